@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 
+	"github.com/apaydev/bluetui/internal/bluetooth"
 	"github.com/apaydev/bluetui/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	// Logging functionality
+	adapterQuery := flag.String("adapter", "", "Adapter to use, given by its ID (hci0), address or name. Defaults to the first one.")
+	flag.Parse()
+
+	// Logging functionality. The adapter logs to the same file, and nowhere
+	// at all unless we are debugging.
+	logger := slog.New(slog.DiscardHandler)
 	if os.Getenv("DEBUG") == "true" {
 		f, err := tea.LogToFile("debug.log", "debug")
 		if err != nil {
@@ -17,9 +26,44 @@ func main() {
 			os.Exit(1)
 		}
 		defer f.Close()
+		logger = slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	adapterInfo, err := bluetooth.SelectAdapter("", bluetooth.NewSystemBusConnection, *adapterQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to find bluetooth adapter: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The observer takes the progress of the operations to the status bar.
+	observer := tui.NewObserver()
+	adapter, err := bluetooth.NewAdapter("", adapterInfo.Path, bluetooth.NewSystemBusConnection,
+		bluetooth.WithLogger(logger),
+		bluetooth.WithObserver(observer),
+		bluetooth.WithRetryPolicy(bluetooth.DefaultRetryPolicy()),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get bluetooth adapter: %v\n", err)
+		os.Exit(1)
 	}
+	defer adapter.Close()
+
+	// Files go through obexd, on the session bus. Without it, the rest of
+	// the app still works. It's told to use the same adapter as we do.
+	obex, err := bluetooth.NewObex("", bluetooth.NewSessionBusConnection,
+		bluetooth.WithLogger(logger),
+		bluetooth.WithSource(adapterInfo.Address),
+	)
+	if err != nil {
+		logger.Warn("OBEX not available", "error", err)
+	} else {
+		defer obex.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	p := tea.NewProgram(tui.NewModel(), tea.WithAltScreen())
+	p := tea.NewProgram(tui.NewModel(ctx, adapter, obex, observer), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// adapterCmds are the commands that act on the adapter itself, so they don't
+// need to discover devices first.
+var adapterCmds = []string{"info", "power", "discoverable", "discoverable-timeout", "pairable", "pairable-timeout", "alias"}
+
+// runAdapterCmd runs one of the adapterCmds with the given arguments.
+func runAdapterCmd(ctx context.Context, adapter bluetooth.Adapter, cmd string, args []string) error {
+	if cmd == "info" {
+		info, err := adapter.Info(ctx)
+		if err != nil {
+			return err
+		}
+		printAdapterInfo(info)
+		return nil
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("please, provide a value for %s", cmd)
+	}
+	value := args[0]
+
+	switch cmd {
+	case "power":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		return adapter.SetPowered(ctx, on)
+	case "discoverable":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		return adapter.SetDiscoverable(ctx, on)
+	case "pairable":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		return adapter.SetPairable(ctx, on)
+	case "discoverable-timeout":
+		timeout, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		return adapter.SetDiscoverableTimeout(ctx, timeout)
+	case "pairable-timeout":
+		timeout, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		return adapter.SetPairableTimeout(ctx, timeout)
+	case "alias":
+		return adapter.SetAlias(ctx, value)
+	}
+
+	return fmt.Errorf("unknown adapter command: %s", cmd)
+}
+
+// parseSwitch parses an on/off value.
+func parseSwitch(value string) (bool, error) {
+	switch value {
+	case "on", "yes", "true":
+		return true, nil
+	case "off", "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q, expected on or off", value)
+}
+
+// parseSeconds parses a timeout given in seconds, where 0 means forever.
+func parseSeconds(value string) (time.Duration, error) {
+	secs, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q, expected a number of seconds", value)
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+func printAdapterInfo(info bluetooth.AdapterInfo) {
+	fmt.Printf("Adapter %s (%s)\n", info.ID(), info.Address)
+	fmt.Printf("  Name:                 %s\n", info.Name)
+	fmt.Printf("  Alias:                %s\n", info.Alias)
+	fmt.Printf("  Powered:              %t\n", info.Powered)
+	fmt.Printf("  Discoverable:         %t\n", info.Discoverable)
+	fmt.Printf("  Discoverable timeout: %s\n", formatTimeout(info.DiscoverableTimeout))
+	fmt.Printf("  Pairable:             %t\n", info.Pairable)
+	fmt.Printf("  Pairable timeout:     %s\n", formatTimeout(info.PairableTimeout))
+	fmt.Printf("  Discovering:          %t\n", info.Discovering)
+}
+
+func formatTimeout(timeout time.Duration) string {
+	if timeout == 0 {
+		return "forever"
+	}
+	return timeout.String()
+}
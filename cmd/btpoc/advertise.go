@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// advertiseFlags holds the flags used to build the advertisement of the
+// advertise command.
+type advertiseFlags struct {
+	adType       *string
+	name         *string
+	uuids        *string
+	manufacturer *string
+	serviceData  *string
+	txPower      *int
+	appearance   *uint
+	discoverable *bool
+	timeout      *time.Duration
+	includes     *string
+}
+
+// registerAdvertiseFlags defines the advertisement flags. It must be called
+// before flag.Parse.
+func registerAdvertiseFlags() advertiseFlags {
+	return advertiseFlags{
+		adType:       flag.String("ad-type", "peripheral", "Advertisement type: broadcast or peripheral."),
+		name:         flag.String("ad-name", "", "Local name to advertise."),
+		uuids:        flag.String("ad-uuids", "", "Comma-separated list of service UUIDs to advertise."),
+		manufacturer: flag.String("ad-manufacturer", "", "Comma-separated manufacturer data, as company=hex (e.g. 004c=0215...)."),
+		serviceData:  flag.String("ad-service-data", "", "Comma-separated service data, as uuid=hex (e.g. feaa=10...)."),
+		txPower:      flag.Int("ad-tx-power", 0, "Transmission power to ask for and advertise, in dBm."),
+		appearance:   flag.Uint("ad-appearance", 0, "Appearance to advertise (e.g. 0x0200 for a tag)."),
+		discoverable: flag.Bool("ad-discoverable", false, "Set the general discoverable flag. Peripheral advertisements only."),
+		timeout:      flag.Duration("ad-timeout", 0, "How long the advertisement lasts, in whole seconds. Defaults to the command timeout."),
+		includes:     flag.String("ad-includes", "", "Comma-separated data for BlueZ to include: tx-power, appearance, local-name or rsi."),
+	}
+}
+
+// advertisement builds the advertisement out of the parsed flags.
+func (f advertiseFlags) advertisement() (bluetooth.Advertisement, error) {
+	ad := bluetooth.Advertisement{
+		Type:         bluetooth.AdvertisementType(*f.adType),
+		LocalName:    *f.name,
+		Discoverable: *f.discoverable,
+		Timeout:      *f.timeout,
+	}
+	if *f.uuids != "" {
+		ad.ServiceUUIDs = strings.Split(*f.uuids, ",")
+	}
+	if *f.includes != "" {
+		ad.Includes = strings.Split(*f.includes, ",")
+	}
+
+	var err error
+	if *f.manufacturer != "" {
+		if ad.ManufacturerData, err = bluetooth.ParseManufacturerData(*f.manufacturer); err != nil {
+			return bluetooth.Advertisement{}, err
+		}
+	}
+	if *f.serviceData != "" {
+		if ad.ServiceData, err = bluetooth.ParseServiceData(*f.serviceData); err != nil {
+			return bluetooth.Advertisement{}, err
+		}
+	}
+
+	// Zero is a valid power and appearance, so we only set them if the flags
+	// were given.
+	flag.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "ad-tx-power":
+			power := int16(*f.txPower)
+			ad.TxPower = &power
+		case "ad-appearance":
+			appearance := uint16(*f.appearance)
+			ad.Appearance = &appearance
+		}
+	})
+
+	return ad, nil
+}
+
+// advertise broadcasts the advertisement until it times out or the context
+// is done, whatever comes first.
+func advertise(ctx context.Context, adapter bluetooth.Adapter, ad bluetooth.Advertisement) error {
+	active, err := adapter.Advertise(ctx, ad)
+	if err != nil {
+		return err
+	}
+
+	if info, err := adapter.AdvertisingInfo(ctx); err == nil {
+		fmt.Printf("Advertising as %s (%d of %d slots in use).\n", active.Path, info.ActiveInstances, info.ActiveInstances+info.SupportedInstances)
+	}
+
+	select {
+	case <-active.Released():
+		fmt.Println("Advertisement released.")
+		return nil
+	case <-ctx.Done():
+		return active.Stop(context.WithoutCancel(ctx))
+	}
+}
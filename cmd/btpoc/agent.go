@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// stdinAgent answers the pairing requests of BlueZ by prompting the user on
+// the terminal.
+type stdinAgent struct {
+	// lines gets the lines typed on the terminal, while a prompt waits for
+	// one.
+	lines chan string
+	// mu guards cancel, which Cancel closes to abandon the pending prompt.
+	mu     sync.Mutex
+	cancel chan struct{}
+}
+
+func newStdinAgent() *stdinAgent {
+	a := &stdinAgent{lines: make(chan string)}
+	go a.readLines(os.Stdin)
+	return a
+}
+
+// readLines reads the terminal in the background, so that a prompt can be
+// abandoned while the user is typing. Lines typed while no prompt waits are
+// dropped, rather than answering the next request.
+func (a *stdinAgent) readLines(r io.Reader) {
+	defer close(a.lines)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		select {
+		case a.lines <- scanner.Text():
+		default:
+		}
+	}
+}
+
+// prompt shows the question and returns the line typed by the user. It gives
+// up with ErrCanceled if BlueZ cancels the request first.
+func (a *stdinAgent) prompt(format string, args ...any) (string, error) {
+	cancel := make(chan struct{})
+	a.mu.Lock()
+	a.cancel = cancel
+	a.mu.Unlock()
+
+	fmt.Printf(format, args...)
+	select {
+	case line, ok := <-a.lines:
+		if !ok {
+			return "", bluetooth.ErrCanceled
+		}
+		return strings.TrimSpace(line), nil
+	case <-cancel:
+		return "", bluetooth.ErrCanceled
+	}
+}
+
+// confirm asks a yes/no question, rejecting the request on anything but yes.
+func (a *stdinAgent) confirm(format string, args ...any) error {
+	answer, err := a.prompt(format+" [y/N]: ", args...)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		return bluetooth.ErrRejected
+	}
+	return nil
+}
+
+func (a *stdinAgent) RequestPinCode(device string) (string, error) {
+	return a.prompt("Enter PIN code for %s: ", device)
+}
+
+func (a *stdinAgent) DisplayPinCode(device, pinCode string) error {
+	fmt.Printf("Type PIN code %s on %s\n", pinCode, device)
+	return nil
+}
+
+func (a *stdinAgent) RequestPasskey(device string) (uint32, error) {
+	answer, err := a.prompt("Enter passkey for %s: ", device)
+	if err != nil {
+		return 0, err
+	}
+	passkey, err := strconv.ParseUint(answer, 10, 32)
+	if err != nil {
+		return 0, bluetooth.ErrRejected
+	}
+	return uint32(passkey), nil
+}
+
+func (a *stdinAgent) DisplayPasskey(device string, passkey uint32, entered uint16) {
+	fmt.Printf("Type passkey %06d on %s (%d digits entered)\n", passkey, device, entered)
+}
+
+func (a *stdinAgent) RequestConfirmation(device string, passkey uint32) error {
+	return a.confirm("Does %s show passkey %06d?", device, passkey)
+}
+
+func (a *stdinAgent) RequestAuthorization(device string) error {
+	return a.confirm("Authorize pairing with %s?", device)
+}
+
+func (a *stdinAgent) AuthorizeService(device, uuid string) error {
+	return a.confirm("Authorize service %s for %s?", uuid, device)
+}
+
+func (a *stdinAgent) Cancel() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		close(a.cancel)
+		a.cancel = nil
+	}
+	fmt.Println("\nRequest canceled by the device.")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// filterFlags holds the flags used to build the discovery filter.
+type filterFlags struct {
+	transport     *string
+	rssi          *int
+	pathloss      *uint
+	uuids         *string
+	duplicateData *bool
+	pattern       *string
+	discoverable  *bool
+}
+
+// registerFilterFlags defines the discovery filter flags. It must be called
+// before flag.Parse.
+func registerFilterFlags() filterFlags {
+	return filterFlags{
+		transport:     flag.String("transport", "", "Discovery transport: auto, bredr or le."),
+		rssi:          flag.Int("rssi", 0, "Only report devices with a stronger signal than this, in dBm (e.g. -70)."),
+		pathloss:      flag.Uint("pathloss", 0, "Only report devices with a lower pathloss than this, in dB."),
+		uuids:         flag.String("uuids", "", "Comma-separated list of service UUIDs that devices must advertise."),
+		duplicateData: flag.Bool("duplicate-data", true, "Report every advertisement, instead of only the ones that change."),
+		pattern:       flag.String("pattern", "", "Only report devices whose name or address starts with this."),
+		discoverable:  flag.Bool("discoverable-only", false, "Only report discoverable devices."),
+	}
+}
+
+// filter builds the discovery filter out of the parsed flags. The thresholds
+// are checked before they are narrowed down to the types of the filter, which
+// would otherwise turn an out of range value into a different one.
+func (f filterFlags) filter() (bluetooth.DiscoveryFilter, error) {
+	if *f.rssi < -127 || *f.rssi > 20 {
+		return bluetooth.DiscoveryFilter{}, fmt.Errorf("invalid rssi %d, it goes from -127 to 20 dBm", *f.rssi)
+	}
+	if *f.pathloss > 137 {
+		return bluetooth.DiscoveryFilter{}, fmt.Errorf("invalid pathloss %d, it goes up to 137 dB", *f.pathloss)
+	}
+
+	filter := bluetooth.DiscoveryFilter{
+		RSSI:         int16(*f.rssi),
+		Pathloss:     uint16(*f.pathloss),
+		Transport:    bluetooth.Transport(*f.transport),
+		Discoverable: *f.discoverable,
+		Pattern:      *f.pattern,
+	}
+
+	if *f.uuids != "" {
+		filter.UUIDs = strings.Split(*f.uuids, ",")
+	}
+
+	// We only want to override the BlueZ default if the flag was given.
+	flag.Visit(func(fl *flag.Flag) {
+		if fl.Name == "duplicate-data" {
+			filter.DuplicateData = f.duplicateData
+		}
+	})
+
+	return filter, nil
+}
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,10 +18,18 @@ import (
 )
 
 func main() {
-	validCmds := []string{"discover", "pair", "connect", "disconnect"}
+	validCmds := append([]string{"adapters", "profiles", "discover", "pair", "connect", "disconnect", "connect-profile", "disconnect-profile", "remove", "player", "audio", "gatt", "notify", "write", "serve", "advertise", "send", "pbap"}, adapterCmds...)
 	usageStr := fmt.Sprintf("Command to execute. Options: %s", strings.Join(validCmds, ", "))
 	// I need to read the function to be executed through flags.
 	cmd := flag.String("cmd", "discover", usageStr)
+	adapterQuery := flag.String("adapter", "", "Adapter to use, given by its ID (hci0), address or name. Defaults to the first one.")
+	filterFlags := registerFilterFlags()
+	adFlags := registerAdvertiseFlags()
+	pbapFlags := registerPbapFlags()
+	timeout := flag.Duration("timeout", 30*time.Second, "How long to wait for the command to finish. Ctrl+C cancels it at any time.")
+	retries := flag.Int("retries", 2, "How many times connect and pair are retried when they fail for a transient reason.")
+	verbose := flag.Bool("v", false, "Log what the adapter does to stderr.")
+	capability := flag.String("capability", string(bluetooth.CapabilityKeyboardDisplay), "Capability of the pairing agent: DisplayOnly, DisplayYesNo, KeyboardOnly, NoInputNoOutput or KeyboardDisplay.")
 	flag.Parse()
 	args := flag.Args()
 
@@ -26,9 +38,66 @@ func main() {
 		os.Exit(1)
 	}
 
+	filter, err := filterFlags.filter()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid discovery filter: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Listing the adapters doesn't require us to pick one of them.
+	if *cmd == "adapters" {
+		adapters, err := bluetooth.ListAdapters("", bluetooth.NewSystemBusConnection)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list bluetooth adapters: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, a := range adapters {
+			power := "off"
+			if a.Powered {
+				power = "on"
+			}
+			fmt.Printf("%s\t%s\t%s\tpower %s\n", a.ID(), a.Address, a.Name, power)
+		}
+		os.Exit(0)
+	}
+
+	// The profiles that connect-profile and disconnect-profile know by name.
+	if *cmd == "profiles" {
+		for _, p := range bluetooth.Profiles() {
+			fmt.Printf("%s\t%s\t%s\n", p.Key, p.UUID, p.Name)
+		}
+		os.Exit(0)
+	}
+
+	adapterInfo, err := bluetooth.SelectAdapter("", bluetooth.NewSystemBusConnection, *adapterQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to find bluetooth adapter: %v\n", err)
+		os.Exit(1)
+	}
+
 	// I want to work with my bluetooth adapter. So, I need to get the
 	// object for it, which will give me interfaces, devices and methods.
-	adapter, err := bluetooth.NewAdapter("", "", bluetooth.NewSystemBusConnection)
+	// The adapter doesn't print anything, so the output stays clean for
+	// scripts. Its logs go to stderr when asked for.
+	policy := bluetooth.DefaultRetryPolicy()
+	policy.MaxAttempts = *retries + 1
+	opts := []bluetooth.Option{
+		bluetooth.WithRetryPolicy(policy),
+		// Retries are reported on stderr, so it's clear why we are waiting.
+		bluetooth.WithObserver(bluetooth.ObserverFunc(func(ev bluetooth.OperationEvent) {
+			if ev.Status == bluetooth.OperationRetrying {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", ev.Message, ev.Err)
+			}
+		})),
+	}
+	if *verbose {
+		opts = append(opts, bluetooth.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	}
+	// Files go through obexd rather than the adapter. It picks an adapter
+	// of its own unless told which one to send from.
+	obexOpts := append(slices.Clone(opts), bluetooth.WithSource(adapterInfo.Address))
+	adapter, err := bluetooth.NewAdapter("", adapterInfo.Path, bluetooth.NewSystemBusConnection, opts...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to get bluetooth adapter: %v\n", err)
 		os.Exit(1)
@@ -41,100 +110,371 @@ func main() {
 		}
 	}()
 
-	// Discover
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Pairing and connecting wait for the device, which can take a while.
+	// Interrupting cancels them, so BlueZ doesn't go on without us.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
 	defer cancel()
 
-	err = adapter.Discover(ctx)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to discover devices: %v\n", err)
-		os.Exit(1)
+	// Our own GATT server doesn't need any device either. It's served until
+	// the timeout, so it should be given a long one.
+	if *cmd == "serve" {
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, "please, provide the JSON file that defines the services to serve.")
+			os.Exit(1)
+		}
+		if err := serve(ctx, adapter, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to serve GATT services: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
-	devices, err := adapter.Devices()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to get devices: %v\n", err)
-		os.Exit(1)
+	// Advertising doesn't need any device either, and it lasts until the
+	// timeout of the advertisement or the one of the command.
+	if *cmd == "advertise" {
+		ad, err := adFlags.advertisement()
+		if err == nil {
+			err = advertise(ctx, adapter, ad)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to advertise: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
-	if devices == nil {
-		fmt.Println("No devices found.")
+	// Adapter commands don't need any device, so there's no need to discover.
+	if slices.Contains(adapterCmds, *cmd) {
+		if err := runAdapterCmd(ctx, adapter, *cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run %s: %v\n", *cmd, err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
 	if *cmd == "discover" {
+		devices, err := discover(ctx, adapter, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to discover devices: %v\n", err)
+			os.Exit(1)
+		}
+
 		// Show all devices
 		fmt.Println("\nDiscovered Devices:")
-		i := 0
-		for _, d := range devices {
-			fmt.Printf("[%d] %s (%s)\n", i+1, d.Name(), d.Address())
-			i++
+		for i, d := range devices {
+			rssi := "-"
+			if v, ok := d.RSSI(); ok {
+				rssi = fmt.Sprintf("%d dBm", v)
+			}
+			battery := "-"
+			if v, ok := d.Battery(); ok {
+				battery = fmt.Sprintf("%d%%", v)
+			}
+			fmt.Printf("[%d] %s (%s) rssi %s, battery %s, paired %t, connected %t, icon %s\n",
+				i+1, d.Name(), d.Address(), rssi, battery, d.Paired(), d.Connected(), d.Icon())
 		}
+		os.Exit(0)
 	}
 
-	// Pairing
-	if *cmd == "pair" {
-		if len(args) < 1 {
-			fmt.Fprintf(os.Stderr, "please, provide a device address to pair with.\n")
+	// The rest of the commands work on a device.
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "please, provide the address of the device to %s.\n", *cmd)
+		os.Exit(1)
+	}
+	addr := args[0]
+
+	var (
+		run  func(context.Context, string) error
+		done string
+	)
+	switch *cmd {
+	case "pair":
+		// The agent answers the PIN, passkey and confirmation requests that
+		// the device may need while pairing.
+		err = adapter.RegisterAgent(ctx, bluetooth.AgentCapability(*capability), newStdinAgent())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to register pairing agent: %v\n", err)
+			os.Exit(1)
+		}
+		run, done = adapter.Pair, "Paired successfully."
+	case "connect":
+		run, done = adapter.Connect, "Connected successfully."
+	case "disconnect":
+		run, done = adapter.Disconnect, "Disconnected successfully."
+	case "connect-profile", "disconnect-profile":
+		if len(args) < 2 {
+			fmt.Fprintf(os.Stderr, "please, provide the profile to %s, given by its name (see -cmd profiles) or UUID.\n", strings.TrimSuffix(*cmd, "-profile"))
+			os.Exit(1)
+		}
+		profile := args[1]
+		if *cmd == "connect-profile" {
+			run = func(ctx context.Context, addr string) error { return adapter.ConnectProfile(ctx, addr, profile) }
+			done = bluetooth.ProfileName(profile) + " connected."
+		} else {
+			run = func(ctx context.Context, addr string) error { return adapter.DisconnectProfile(ctx, addr, profile) }
+			done = bluetooth.ProfileName(profile) + " disconnected."
+		}
+	case "remove":
+		run, done = adapter.Remove, "Device removed."
+	case "player":
+		// Without a command, we just show what's playing.
+		if len(args) < 2 {
+			run = func(ctx context.Context, addr string) error {
+				state, err := adapter.Player(ctx, addr)
+				if err != nil {
+					return err
+				}
+				printPlayer(state)
+				return nil
+			}
+			break
+		}
+		command, ok := playerCommands[strings.ToLower(args[1])]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown player command %s. Valid commands are: play, pause, stop, next, previous, forward, rewind.\n", args[1])
+			os.Exit(1)
+		}
+		run = func(ctx context.Context, addr string) error { return adapter.ControlPlayer(ctx, addr, command) }
+		done = "Sent " + strings.ToLower(string(command)) + " to the player."
+	case "audio":
+		// Without a volume, we just show the audio streams.
+		if len(args) < 2 {
+			run = func(ctx context.Context, addr string) error {
+				transports, err := adapter.Transports(ctx, addr)
+				if err != nil {
+					return err
+				}
+				printTransports(transports)
+				return nil
+			}
+			break
+		}
+		volume, err := strconv.ParseUint(args[1], 10, 16)
+		if err != nil || volume > bluetooth.MaxVolume {
+			fmt.Fprintf(os.Stderr, "invalid volume %s, it goes from 0 to %d.\n", args[1], bluetooth.MaxVolume)
 			os.Exit(1)
 		}
+		run = func(ctx context.Context, addr string) error { return adapter.SetVolume(ctx, addr, uint16(volume)) }
+		done = "Volume set."
+	case "notify":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "please, provide the characteristic to follow, given by its UUID or object path.")
+			os.Exit(1)
+		}
+		// Values are printed until the timeout, or Ctrl+C.
+		run = func(ctx context.Context, addr string) error {
+			path, err := findCharacteristic(ctx, adapter, addr, args[1])
+			if err != nil {
+				return err
+			}
+			notifications, err := adapter.Notify(ctx, path)
+			if err != nil {
+				return err
+			}
+			for n := range notifications {
+				fmt.Printf("%s\t% x\n", n.Time.Format("15:04:05.000"), n.Value)
+			}
+			return nil
+		}
+	case "write":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "please, provide the characteristic to write to, given by its UUID or object path.")
+			os.Exit(1)
+		}
+		// Whatever comes through stdin is streamed to the characteristic.
+		run = func(ctx context.Context, addr string) error {
+			path, err := findCharacteristic(ctx, adapter, addr, args[1])
+			if err != nil {
+				return err
+			}
+			w, err := adapter.OpenWriter(ctx, path)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
 
-		addr := args[0]
-		err = adapter.Pair(addr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to pair with device %s: %v\n", addr, err)
+			start := time.Now()
+			n, err := io.Copy(w, os.Stdin)
+			if err != nil {
+				return err
+			}
+			elapsed := time.Since(start)
+			done = fmt.Sprintf("Wrote %d bytes in %s (%.1f kB/s, acquired %t, MTU %d).",
+				n, elapsed.Round(time.Millisecond), float64(n)/1000/elapsed.Seconds(), w.Acquired(), w.MTU())
+			return nil
+		}
+	case "send":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "please, provide the file to send.")
 			os.Exit(1)
 		}
+		run = func(ctx context.Context, addr string) error { return sendFile(ctx, addr, args[1], obexOpts) }
+		done = "File sent."
+	case "pbap":
+		// Without a command, we just list the phonebooks.
+		export := len(args) > 1
+		if export && args[1] != "export" {
+			fmt.Fprintf(os.Stderr, "unknown pbap command %s. The only one is export, followed by the directory to export to.\n", args[1])
+			os.Exit(1)
+		}
+		dir := "."
+		if len(args) > 2 {
+			dir = args[2]
+		}
+		run = func(ctx context.Context, addr string) error { return pbap(ctx, addr, export, dir, pbapFlags, obexOpts) }
+		if export {
+			done = "Phonebooks exported."
+		}
+	case "gatt":
+		run = func(ctx context.Context, addr string) error {
+			services, err := connectedServices(ctx, adapter, addr)
+			if err != nil {
+				return err
+			}
+			printServices(services)
+			return nil
+		}
+	}
 
-		os.Exit(0)
+	// BlueZ remembers the devices it has seen (and the paired ones), so we
+	// only need to scan when it doesn't know about this one yet.
+	err = run(ctx, addr)
+	if errors.Is(err, bluetooth.ErrDeviceNotFound) {
+		fmt.Fprintf(os.Stderr, "device %s not known yet, scanning for it...\n", addr)
+		if _, err = discover(ctx, adapter, filter); err == nil {
+			err = run(ctx, addr)
+		}
+	}
+	if *cmd == "pair" {
+		if uerr := adapter.UnregisterAgent(context.WithoutCancel(ctx)); uerr != nil {
+			fmt.Fprintf(os.Stderr, "failed to unregister pairing agent: %v\n", uerr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to %s device %s: %v\n", *cmd, addr, err)
+		os.Exit(1)
+	}
+	if done != "" {
+		fmt.Println(done)
+	}
+}
+
+// playerCommands are the commands that the player command takes.
+var playerCommands = map[string]bluetooth.PlayerCommand{
+	"play":     bluetooth.PlayerPlay,
+	"pause":    bluetooth.PlayerPause,
+	"stop":     bluetooth.PlayerStop,
+	"next":     bluetooth.PlayerNext,
+	"previous": bluetooth.PlayerPrevious,
+	"forward":  bluetooth.PlayerFastForward,
+	"rewind":   bluetooth.PlayerRewind,
+}
 
+// connectedServices connects to the device and returns its services, since
+// BlueZ only resolves them while connected. Resolving them takes a moment
+// after connecting, so we wait for it.
+func connectedServices(ctx context.Context, adapter bluetooth.Adapter, addr string) ([]bluetooth.GattService, error) {
+	if err := adapter.Connect(ctx, addr); err != nil {
+		return nil, err
 	}
 
-	// Connecting
-	if *cmd == "connect" {
-		args := flag.Args()
-		if len(args) < 1 {
-			fmt.Fprintf(os.Stderr, "please, provide a device address to connect with.\n")
-			os.Exit(1)
+	services, err := adapter.Services(ctx, addr)
+	for errors.Is(err, bluetooth.ErrServicesNotResolved) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
 		}
+		services, err = adapter.Services(ctx, addr)
+	}
+	return services, err
+}
 
-		addr := args[0]
-		err = adapter.Connect(addr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to connect with device %s: %v\n", addr, err)
-			os.Exit(1)
+// findCharacteristic returns the path of the characteristic of the device
+// given by its UUID or path.
+func findCharacteristic(ctx context.Context, adapter bluetooth.Adapter, addr, char string) (string, error) {
+	if strings.HasPrefix(char, "/") {
+		return char, nil
+	}
+
+	services, err := connectedServices(ctx, adapter, addr)
+	if err != nil {
+		return "", err
+	}
+
+	uuid := bluetooth.NormalizeUUID(char)
+	for _, s := range services {
+		for _, c := range s.Characteristics {
+			if c.UUID == uuid {
+				return c.Path, nil
+			}
 		}
-		os.Exit(0)
 	}
+	return "", fmt.Errorf("characteristic %s not found", char)
+}
 
-	// Disconnecting
-	if *cmd == "disconnect" {
-		args := flag.Args()
-		if len(args) < 1 {
-			fmt.Fprintf(os.Stderr, "please, provide a device address to disconnect from.\n")
-			os.Exit(1)
+// printServices prints the GATT database of a device as a tree.
+func printServices(services []bluetooth.GattService) {
+	for _, s := range services {
+		fmt.Printf("%s\t%s\n", s.Name(), s.UUID)
+		for _, c := range s.Characteristics {
+			fmt.Printf("  %s\t%s\t[%s]\t% x\n", c.Name(), c.UUID, strings.Join(c.Flags, ", "), c.Value)
+			for _, d := range c.Descriptors {
+				fmt.Printf("    %s\t%s\n", d.Name(), d.UUID)
+			}
 		}
+	}
+}
 
-		addr := args[0]
-		err = adapter.Disconnect(addr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to disconnect from device %s: %v\n", addr, err)
-			os.Exit(1)
+// printTransports prints the audio streams of a device, one per line.
+func printTransports(transports []bluetooth.MediaTransport) {
+	if len(transports) == 0 {
+		fmt.Println("No audio streams.")
+		return
+	}
+	for _, t := range transports {
+		volume := "-"
+		if t.HasVolume {
+			volume = fmt.Sprintf("%d/%d", t.Volume, bluetooth.MaxVolume)
 		}
-		os.Exit(0)
+		codec := t.Codec.String()
+		if t.CodecErr != nil {
+			codec = fmt.Sprintf("%s (% x: %v)", codec, t.Codec.Raw, t.CodecErr)
+		}
+		fmt.Printf("%s\t%s\t%s\tvolume %s\n", bluetooth.ProfileName(t.UUID), t.State, codec, volume)
+	}
+}
+
+// printPlayer prints the state of a player.
+func printPlayer(state bluetooth.PlayerState) {
+	track := state.Track
+	fmt.Printf("Player:\t%s\n", state.Name)
+	fmt.Printf("Status:\t%s\n", state.Status)
+	fmt.Printf("Title:\t%s\n", track.Title)
+	fmt.Printf("Artist:\t%s\n", track.Artist)
+	fmt.Printf("Album:\t%s\n", track.Album)
+	fmt.Printf("Position:\t%s / %s\n", state.CurrentPosition(time.Now()).Round(time.Second), track.Duration.Round(time.Second))
+}
+
+// discoverTimeout is how long we scan for devices.
+const discoverTimeout = 5 * time.Second
+
+// discover scans for devices matching the filter and returns everything the
+// adapter knows about afterwards.
+func discover(ctx context.Context, adapter bluetooth.Adapter, filter bluetooth.DiscoveryFilter) ([]bluetooth.Device, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoverTimeout)
+	defer cancel()
+
+	if err := adapter.Discover(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	devices, err := adapter.Devices()
+	if err != nil {
+		return nil, err
 	}
 
-	//
-	//
-	// // Try connecting (with retry)
-	// for range 3 {
-	// 	fmt.Println("Attempting to connect...")
-	// 	err = device.Call(deviceInterface+".Connect", 0).Err
-	// 	if err == nil {
-	// 		fmt.Println("Connected to:", selected.Name)
-	// 		break
-	// 	}
-	// 	fmt.Println("Connect failed:", err)
-	// 	time.Sleep(2 * time.Second)
-	// }
+	return devices, nil
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// sendFile pushes the file to the device through obexd, printing the progress
+// to stderr. The device may ask its user to accept the file, which counts
+// against the timeout.
+func sendFile(ctx context.Context, addr, file string, opts []bluetooth.Option) error {
+	obex, err := bluetooth.NewObex("", bluetooth.NewSessionBusConnection, opts...)
+	if err != nil {
+		return err
+	}
+	defer obex.Close()
+
+	fmt.Fprintf(os.Stderr, "waiting for %s to accept %s...\n", addr, file)
+	transfer, err := obex.SendFile(ctx, addr, file)
+	if err != nil {
+		return err
+	}
+
+	for p := range transfer.Progress() {
+		printTransferProgress(p)
+	}
+	fmt.Fprintln(os.Stderr)
+	return transfer.Err()
+}
+
+// printTransferProgress overwrites the progress line on stderr.
+func printTransferProgress(p bluetooth.TransferProgress) {
+	if p.Size == 0 {
+		fmt.Fprintf(os.Stderr, "\r%s: %d bytes", p.Status, p.Transferred)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r%s: %3.0f%% (%d/%d bytes)", p.Status, p.Fraction()*100, p.Transferred, p.Size)
+}
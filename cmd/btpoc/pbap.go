@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// pbapFlags holds the flags of the pbap command.
+type pbapFlags struct {
+	format     *string
+	phonebooks *string
+	location   *string
+}
+
+// registerPbapFlags defines the flags of the pbap command. It must be called
+// before flag.Parse.
+func registerPbapFlags() pbapFlags {
+	var names []string
+	for _, p := range bluetooth.ExportedPhonebooks() {
+		names = append(names, string(p))
+	}
+	return pbapFlags{
+		format:     flag.String("vcard", "3.0", "Version of the vCards exported by pbap: 2.1 or 3.0."),
+		phonebooks: flag.String("phonebooks", strings.Join(names, ","), "Comma-separated list of the phonebooks that pbap lists or exports: pb, ich, och, mch, cch, spd or fav."),
+		location:   flag.String("location", bluetooth.PhonebookInternal, "Where pbap reads the phonebooks from: int (the phone) or sim1."),
+	}
+}
+
+// options parses the flags.
+func (f pbapFlags) options() (bluetooth.VCardFormat, []bluetooth.Phonebook, error) {
+	format, err := bluetooth.ParseVCardFormat(*f.format)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var phonebooks []bluetooth.Phonebook
+	for name := range strings.SplitSeq(*f.phonebooks, ",") {
+		p, err := bluetooth.ParsePhonebook(name)
+		if err != nil {
+			return "", nil, err
+		}
+		phonebooks = append(phonebooks, p)
+	}
+	return format, phonebooks, nil
+}
+
+// pbap lists the phonebooks of the phone, or exports them to vCard files in
+// dir when export is set. The phone usually asks its user to allow access
+// first, which counts against the timeout.
+func pbap(ctx context.Context, addr string, export bool, dir string, f pbapFlags, opts []bluetooth.Option) error {
+	format, phonebooks, err := f.options()
+	if err != nil {
+		return err
+	}
+
+	obex, err := bluetooth.NewObex("", bluetooth.NewSessionBusConnection, opts...)
+	if err != nil {
+		return err
+	}
+	defer obex.Close()
+
+	fmt.Fprintf(os.Stderr, "waiting for %s to allow access to its phonebooks...\n", addr)
+	session, err := obex.PhonebookAccess(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := session.Close(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	if export {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	// Phones don't have every phonebook (favourites are often missing), so
+	// the ones they refuse are skipped.
+	done := 0
+	for _, p := range phonebooks {
+		if err := session.Select(ctx, *f.location, p); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "skipping %s: %v\n", p.Name(), err)
+			continue
+		}
+
+		if !export {
+			entries, err := session.List(ctx)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				fmt.Printf("%s\t%s\t%s\n", p, e.Handle, e.Name)
+			}
+			done++
+			continue
+		}
+
+		file := filepath.Join(dir, p.FileName())
+		transfer, err := session.PullAll(ctx, file, format)
+		if err != nil {
+			return err
+		}
+		for progress := range transfer.Progress() {
+			fmt.Fprintf(os.Stderr, "\r%s: %d bytes", p.Name(), progress.Transferred)
+		}
+		fmt.Fprintln(os.Stderr)
+		if err := transfer.Err(); err != nil {
+			return err
+		}
+		fmt.Println(file)
+		done++
+	}
+
+	if done == 0 {
+		return errors.New("no phonebook could be read")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+)
+
+// stdoutGattHandler serves the values of the GATT server as they are, and
+// prints every request made by the centrals.
+type stdoutGattHandler struct{}
+
+func (stdoutGattHandler) ReadValue(req bluetooth.GattRequest, value []byte) ([]byte, error) {
+	printGattRequest("read", req, value)
+	return value, nil
+}
+
+func (stdoutGattHandler) WriteValue(req bluetooth.GattRequest, value []byte) error {
+	printGattRequest("write", req, value)
+	return nil
+}
+
+func printGattRequest(op string, req bluetooth.GattRequest, value []byte) {
+	fmt.Printf("%s\t%s\t%s\t%s\t%d\t% x\n", time.Now().Format("15:04:05.000"), op, req.Device, bluetooth.GattName(req.UUID), req.Offset, value)
+}
+
+// serve serves the services of the definition file until the context is
+// done, which is the timeout unless interrupted first.
+func serve(ctx context.Context, adapter bluetooth.Adapter, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	def, err := bluetooth.ReadGattServerDefinition(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	app, err := adapter.ServeGatt(ctx, def, stdoutGattHandler{})
+	if err != nil {
+		return err
+	}
+	printServices(app.Services())
+
+	<-ctx.Done()
+	return app.Close(context.WithoutCancel(ctx))
+}
@@ -1,18 +1,69 @@
 package bluetooth
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
 
 // Adapter defines the behavior of a platform-specific Bluetooth adapter.
 //
 // Implementations are expected to handle OS-specific logic behind methods like
 // Discover, Connect, etc.
 type Adapter interface {
-	Discover(context.Context) error
-	Pair(addr string) error
-	Trust(addr string) error
-	Connect(addr string) error
-	Disconnect(addr string) error
+	// Discover scans for devices until the context is done, only reporting
+	// the ones that match the filter. The devices found are then available
+	// through Devices.
+	Discover(context.Context, DiscoveryFilter) error
+	// StartDiscovery starts scanning for devices that match the filter, and
+	// returns without waiting for any result.
+	StartDiscovery(context.Context, DiscoveryFilter) (*DiscoverySession, error)
+	// Watch streams the changes on the devices of the adapter until the
+	// context is done, at which point the channel is closed.
+	Watch(context.Context) (<-chan DeviceEvent, error)
+	// Pair, Connect and the rest of the operations on devices can take a
+	// while, since they wait for the device. They are abandoned as soon as
+	// the context is done.
+	Pair(ctx context.Context, addr string) error
+	Trust(ctx context.Context, addr string) error
+	Connect(ctx context.Context, addr string) error
+	Disconnect(ctx context.Context, addr string) error
+	// ConnectProfile and DisconnectProfile only act on one of the profiles
+	// of the device, given by its UUID or by the key of a well-known one
+	// (see Profiles).
+	ConnectProfile(ctx context.Context, addr, profile string) error
+	DisconnectProfile(ctx context.Context, addr, profile string) error
+	// Remove forgets the device, along with its pairing information.
+	Remove(ctx context.Context, addr string) error
 	Devices() ([]Device, error)
+	// Info returns the current state of the adapter, and the setters below
+	// change it.
+	Info(context.Context) (AdapterInfo, error)
+	SetPowered(context.Context, bool) error
+	SetDiscoverable(context.Context, bool) error
+	SetDiscoverableTimeout(context.Context, time.Duration) error
+	SetPairable(context.Context, bool) error
+	SetPairableTimeout(context.Context, time.Duration) error
+	SetAlias(context.Context, string) error
+	// RegisterAgent makes the handler answer the requests (PIN codes,
+	// passkeys, confirmations) made while pairing devices.
+	RegisterAgent(context.Context, AgentCapability, AgentHandler) error
+	UnregisterAgent(context.Context) error
+	// Media controls the players of the connected devices.
+	Media
+	// Audio inspects the audio streams of the connected devices.
+	Audio
+	// GATT browses the services of the connected LE devices.
+	GATT
+	// GattServer serves our own services to the devices that connect to
+	// us.
+	GattServer
+	// Advertising broadcasts our own LE advertisements.
+	Advertising
 	Close() error
 	// These methods are used to get the adapter's properties.
 	// NOTE: I have not found a way to make them generic for all implementations
@@ -27,46 +78,125 @@ type Adapter interface {
 type adapterBase struct {
 	destination string
 	path        string
-	devices     map[string]Device
+	// mu guards devices, which can be updated from the goroutines spawned by
+	// Watch while the caller reads it.
+	mu      sync.RWMutex
+	devices map[string]Device
+	// logger and observer are set through the options given to NewAdapter.
+	// The package never writes to stdout on its own.
+	logger   *slog.Logger
+	observer Observer
+	// retry is the policy used by Connect and Pair.
+	retry RetryPolicy
+	// source is the address of the adapter OBEX sessions are created
+	// from. Only Obex uses it.
+	source string
 }
 
-// Device describes a discovered Bluetooth Device.
-type Device struct {
-	name    string
-	address string
-	path    string
+// Option configures an adapter created through NewAdapter.
+type Option func(*adapterBase)
+
+// WithLogger makes the adapter log what it does through the given logger.
+// Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *adapterBase) {
+		if logger != nil {
+			b.logger = logger
+		}
+	}
+}
+
+// WithObserver makes the adapter report the progress of the operations run
+// on devices (pairing, connecting, etc) to the given observer.
+func WithObserver(observer Observer) Option {
+	return func(b *adapterBase) {
+		b.observer = observer
+	}
 }
 
-// Name returns the name of the Bluetooth device.
-func (d Device) Name() string {
-	return d.name
+// init sets up the base of a new adapter and applies the options.
+func (b *adapterBase) init(destination, path string, opts ...Option) {
+	b.destination = destination
+	b.path = path
+	b.devices = make(map[string]Device)
+	b.logger = slog.New(slog.DiscardHandler)
+	for _, opt := range opts {
+		opt(b)
+	}
 }
 
-// Address returns the address of the Bluetooth device.
-func (d Device) Address() string {
-	return d.address
+// notify logs the event and hands it to the observer, if any.
+func (b *adapterBase) notify(ev OperationEvent) {
+	level := slog.LevelInfo
+	switch ev.Status {
+	case OperationStarted:
+		level = slog.LevelDebug
+	case OperationRetrying:
+		level = slog.LevelWarn
+	case OperationFailed:
+		level = slog.LevelError
+	}
+
+	attrs := []any{"op", ev.Operation, "address", ev.Address, "status", ev.Status}
+	if ev.Attempt > 0 {
+		attrs = append(attrs, "attempt", ev.Attempt, "max_attempts", ev.MaxAttempts)
+	}
+	if ev.Err != nil {
+		attrs = append(attrs, "err", ev.Err)
+	}
+	msg := ev.Message
+	if msg == "" {
+		msg = string(ev.Operation) + " " + ev.Status.String()
+	}
+	b.logger.Log(context.Background(), level, msg, attrs...)
+
+	if b.observer != nil {
+		b.observer.Notify(ev)
+	}
 }
 
-// Path returns the D-Bus path of the Bluetooth device.
-func (d Device) Path() string {
-	return d.path
+// AdapterInfo describes one of the Bluetooth adapters (radios) available in
+// the system.
+type AdapterInfo struct {
+	// Path is the object path of the adapter, e.g. /org/bluez/hci0.
+	Path    string
+	Address string
+	// Name is the system name of the adapter, usually the hostname.
+	Name string
+	// Alias is the name shown to other devices. It defaults to Name.
+	Alias        string
+	Powered      bool
+	Discoverable bool
+	Pairable     bool
+	Discovering  bool
+	// DiscoverableTimeout and PairableTimeout tell how long the adapter stays
+	// discoverable or pairable once switched on. Zero means forever.
+	DiscoverableTimeout time.Duration
+	PairableTimeout     time.Duration
 }
 
-// METHODS REQUIRED SO THAT THIS CAN BE USED AS A LIST ITEM
+// ID returns the short identifier of the adapter, e.g. hci0.
+func (a AdapterInfo) ID() string {
+	return path.Base(a.Path)
+}
 
-func (d Device) Title() string       { return d.name }
-func (d Device) Description() string { return d.address }
-func (d Device) FilterValue() string { return d.name }
+// FindAdapter looks for the adapter that matches the given query, which may
+// be its ID (hci0), its object path, its address or its name. Addresses are
+// compared case-insensitively.
+func FindAdapter(adapters []AdapterInfo, query string) (AdapterInfo, error) {
+	for _, a := range adapters {
+		if query == a.ID() || query == a.Path || strings.EqualFold(query, a.Address) {
+			return a, nil
+		}
+	}
 
-// NOTE: Func used only to test the integration with the list bubble. This
-// will be either modified or removed.
-func GetDevices() []Device {
-	return []Device{
-		{name: "Device 1", address: "00:00:00:00:00:01", path: "/org/bluez/hci0/dev_00_00_00_00_00_01"},
-		{name: "Device 2", address: "00:00:00:00:00:02", path: "/org/bluez/hci0/dev_00_00_00_00_00_02"},
-		{name: "Device 3", address: "00:00:00:00:00:03", path: "/org/bluez/hci0/dev_00_00_00_00_00_03"},
-		{name: "Device 4", address: "00:00:00:00:00:04", path: "/org/bluez/hci0/dev_00_00_00_00_00_04"},
-		{name: "Device 5", address: "00:00:00:00:00:05", path: "/org/bluez/hci0/dev_00_00_00_00_00_05"},
-		{name: "Device 6", address: "00:00:00:00:00:06", path: "/org/bluez/hci0/dev_00_00_00_00_00_06"},
+	// Names are checked last, since they are the most likely to be
+	// ambiguous.
+	for _, a := range adapters {
+		if query == a.Name {
+			return a, nil
+		}
 	}
+
+	return AdapterInfo{}, fmt.Errorf("no adapter matches %q", query)
 }
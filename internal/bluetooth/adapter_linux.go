@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -21,8 +23,12 @@ const (
 	// and methods available for the BT adapter, and for the Device adapter.
 	adapterInterface = "org.bluez.Adapter1"
 	deviceInterface  = "org.bluez.Device1"
+	batteryInterface = "org.bluez.Battery1"
 	// Standard interface to work with properties of D-Bus objects.
 	propertiesInterface = "org.freedesktop.DBus.Properties"
+	// Standard interface implemented by the root object of BlueZ, which lets
+	// us list every object it exports and be notified when they come and go.
+	objectManagerInterface = "org.freedesktop.DBus.ObjectManager"
 )
 
 // linuxAdapter is a Linux-specific implementation of the Adapter interface.
@@ -33,8 +39,97 @@ type linuxAdapter struct {
 	adapterObj dbusObject
 }
 
-// NewAdapter creates a new Linux-specific Bluetooth adapter.
-func NewAdapter(destination, path string, dbusConnFact DbusConnectionFactory) (Adapter, error) {
+// ListAdapters returns every Bluetooth adapter known by BlueZ, sorted by their
+// object path. The connection created by the factory is closed before
+// returning.
+func ListAdapters(destination string, dbusConnFact DbusConnectionFactory) ([]AdapterInfo, error) {
+	conn, err := dbusConnFact()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	dest := destination
+	if dest == "" {
+		dest = bluezDestination
+	}
+
+	objs, err := managedObjects(context.Background(), conn, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var adapters []AdapterInfo
+	for path, ifaceMap := range objs {
+		props, ok := ifaceMap[adapterInterface]
+		if !ok {
+			continue
+		}
+
+		adapters = append(adapters, adapterInfoFromProperties(path, props))
+	}
+
+	slices.SortFunc(adapters, func(a, b AdapterInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return adapters, nil
+}
+
+// SelectAdapter returns the adapter that matches the query (see FindAdapter),
+// or the first one when the query is empty. It's what the -adapter flag of the
+// commands goes through.
+func SelectAdapter(destination string, dbusConnFact DbusConnectionFactory, query string) (AdapterInfo, error) {
+	adapters, err := ListAdapters(destination, dbusConnFact)
+	if err != nil {
+		return AdapterInfo{}, fmt.Errorf("failed to list adapters: %w", err)
+	}
+
+	if query == "" {
+		if len(adapters) == 0 {
+			return AdapterInfo{}, errors.New("no adapter found")
+		}
+		return adapters[0], nil
+	}
+	return FindAdapter(adapters, query)
+}
+
+// adapterInfoFromProperties builds an AdapterInfo out of the properties of an
+// org.bluez.Adapter1 object.
+func adapterInfoFromProperties(path dbus.ObjectPath, props map[string]dbus.Variant) AdapterInfo {
+	p := properties(props)
+	info := AdapterInfo{Path: string(path)}
+	info.Address, _ = p.stringValue("Address")
+	info.Name, _ = p.stringValue("Name")
+	info.Alias, _ = p.stringValue("Alias")
+	info.Powered, _ = p.boolValue("Powered")
+	info.Discoverable, _ = p.boolValue("Discoverable")
+	info.Pairable, _ = p.boolValue("Pairable")
+	info.Discovering, _ = p.boolValue("Discovering")
+	if v, ok := p.uint32Value("DiscoverableTimeout"); ok {
+		info.DiscoverableTimeout = time.Duration(v) * time.Second
+	}
+	if v, ok := p.uint32Value("PairableTimeout"); ok {
+		info.PairableTimeout = time.Duration(v) * time.Second
+	}
+	return info
+}
+
+// managedObjects returns every object exported by BlueZ, along with their
+// interfaces and properties.
+func managedObjects(ctx context.Context, conn dbusConn, destination string) (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	var objs map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	objManager := conn.Object(destination, "/")
+	err := mapError(objManager.CallWithContext(ctx, objectManagerInterface+".GetManagedObjects", 0).Store(&objs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed objects: %w", err)
+	}
+	return objs, nil
+}
+
+// NewAdapter creates a new Linux-specific Bluetooth adapter. The options can
+// be used to plug in a logger and an observer.
+func NewAdapter(destination, path string, dbusConnFact DbusConnectionFactory, opts ...Option) (Adapter, error) {
 	conn, err := dbusConnFact()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
@@ -52,11 +147,13 @@ func NewAdapter(destination, path string, dbusConnFact DbusConnectionFactory) (A
 
 	// The adapter that we return is going to use the conn returned by our factory,
 	// which means that we can easily mock stuff.
-	return &linuxAdapter{
-		conn:        conn,
-		adapterBase: adapterBase{destination: dest, path: pth},
-		adapterObj:  conn.Object(dest, dbus.ObjectPath(pth)),
-	}, nil
+	adapter := &linuxAdapter{
+		conn:       conn,
+		adapterObj: conn.Object(dest, dbus.ObjectPath(pth)),
+	}
+	adapter.init(dest, pth, opts...)
+
+	return adapter, nil
 }
 
 // Destination returns the destination name of the Bluetooth adapter. By default,
@@ -71,34 +168,80 @@ func (a *linuxAdapter) Path() string {
 	return a.path
 }
 
-// Discover starts the discovery process for Bluetooth devices. This is a blocking
-// function.
-func (b *linuxAdapter) Discover(ctx context.Context) (err error) {
-	// Pretty self explainatory. Begin scanning for devices, and defer
-	// the call to stop that process.
-	err = b.adapterObj.Call(adapterInterface+".StartDiscovery", 0).Err
+// Info returns the current state of the adapter.
+func (b *linuxAdapter) Info(ctx context.Context) (AdapterInfo, error) {
+	var props map[string]dbus.Variant
+	err := mapError(b.adapterObj.CallWithContext(ctx, propertiesInterface+".GetAll", 0, adapterInterface).Store(&props))
 	if err != nil {
-		return fmt.Errorf("failed to start discovery process: %w", err)
+		return AdapterInfo{}, fmt.Errorf("failed to get adapter properties: %w", err)
 	}
 
-	// Since stopping the discovery process can yield an error, we should
-	// handle it accordingly.
-	defer func() {
-		if cerr := b.adapterObj.Call(adapterInterface+".StopDiscovery", 0).Err; cerr != nil {
-			errors.Join(err, errors.Join(err, fmt.Errorf("failed to stop discovery process: %w", cerr)))
-		}
-	}()
+	return adapterInfoFromProperties(dbus.ObjectPath(b.path), props), nil
+}
+
+// SetPowered switches the adapter on or off.
+func (b *linuxAdapter) SetPowered(ctx context.Context, powered bool) error {
+	return b.setProperty(ctx, "Powered", powered)
+}
 
-	// Wait for context to be done (timeout or cancellation)
-	<-ctx.Done()
+// SetDiscoverable makes the adapter visible, or not, to other devices.
+func (b *linuxAdapter) SetDiscoverable(ctx context.Context, discoverable bool) error {
+	return b.setProperty(ctx, "Discoverable", discoverable)
+}
 
-	// If user canceled discovery explicitly
-	if ctx.Err() == context.Canceled {
-		return fmt.Errorf("discovery canceled by user")
+// SetDiscoverableTimeout sets how long the adapter stays discoverable once
+// switched on. Zero means forever.
+func (b *linuxAdapter) SetDiscoverableTimeout(ctx context.Context, timeout time.Duration) error {
+	return b.setProperty(ctx, "DiscoverableTimeout", uint32(timeout.Seconds()))
+}
+
+// SetPairable allows, or not, other devices to pair with the adapter.
+func (b *linuxAdapter) SetPairable(ctx context.Context, pairable bool) error {
+	return b.setProperty(ctx, "Pairable", pairable)
+}
+
+// SetPairableTimeout sets how long the adapter stays pairable once switched
+// on. Zero means forever.
+func (b *linuxAdapter) SetPairableTimeout(ctx context.Context, timeout time.Duration) error {
+	return b.setProperty(ctx, "PairableTimeout", uint32(timeout.Seconds()))
+}
+
+// SetAlias sets the name of the adapter shown to other devices. An empty
+// alias resets it to the system name.
+func (b *linuxAdapter) SetAlias(ctx context.Context, alias string) error {
+	return b.setProperty(ctx, "Alias", alias)
+}
+
+// setProperty sets one of the properties of the org.bluez.Adapter1 interface.
+func (b *linuxAdapter) setProperty(ctx context.Context, name string, value any) error {
+	err := call(ctx, b.adapterObj, propertiesInterface+".Set", adapterInterface, name, dbus.MakeVariant(value))
+	if err != nil {
+		return fmt.Errorf("failed to set adapter property %s: %w", name, err)
 	}
+	return nil
+}
 
-	// Get the devices that were discovered.
-	err = b.getDevicesInfo()
+// Discover starts the discovery process for Bluetooth devices. This is a blocking
+// function. Canceling the context is the way to stop it, so the devices found
+// until then are kept.
+func (b *linuxAdapter) Discover(ctx context.Context, filter DiscoveryFilter) error {
+	session, err := b.StartDiscovery(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	// We don't need the devices as they are found, but the session needs
+	// someone reading them.
+	for range session.Devices() {
+	}
+
+	if _, err := session.Stop(); err != nil {
+		return err
+	}
+
+	// Get the devices that were discovered. The context is done by now, but
+	// that only meant that we had to stop scanning.
+	err = b.getDevicesInfo(context.WithoutCancel(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to get info for discovered devices: %w", err)
 	}
@@ -106,127 +249,282 @@ func (b *linuxAdapter) Discover(ctx context.Context) (err error) {
 	return nil
 }
 
+// StartDiscovery starts the discovery process for Bluetooth devices, and
+// returns a session that reports them as they are found. Discovery goes on
+// until the session is stopped or the context is done.
+func (b *linuxAdapter) StartDiscovery(ctx context.Context, filter DiscoveryFilter) (*DiscoverySession, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid discovery filter: %w", err)
+	}
+
+	// The filter is always set, even if it's empty, so that we don't keep
+	// the one from a previous discovery.
+	err := call(ctx, b.adapterObj, adapterInterface+".SetDiscoveryFilter", discoveryFilterProperties(filter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set discovery filter: %w", err)
+	}
+
+	// We need to be watching before we start scanning, so that we don't miss
+	// any device.
+	sessionCtx, cancel := context.WithCancel(ctx)
+	events, err := b.watch(sessionCtx, false)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to watch discovered devices: %w", err)
+	}
+
+	// Pretty self explainatory. Begin scanning for devices. The session
+	// takes care of stopping that process.
+	err = call(ctx, b.adapterObj, adapterInterface+".StartDiscovery")
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start discovery process: %w", err)
+	}
+
+	// The session is stopped once its context is done, so stopping the scan
+	// must not depend on it.
+	stopScan := func() error {
+		if err := call(context.WithoutCancel(ctx), b.adapterObj, adapterInterface+".StopDiscovery"); err != nil {
+			return fmt.Errorf("failed to stop discovery process: %w", err)
+		}
+		return nil
+	}
+
+	return newDiscoverySession(sessionCtx, cancel, events, stopScan), nil
+}
+
+// discoveryFilterProperties translates the filter into the dictionary expected
+// by Adapter1.SetDiscoveryFilter. Fields holding their zero value are left
+// out, so that BlueZ uses its defaults.
+func discoveryFilterProperties(filter DiscoveryFilter) map[string]dbus.Variant {
+	props := make(map[string]dbus.Variant)
+
+	if len(filter.UUIDs) > 0 {
+		props["UUIDs"] = dbus.MakeVariant(filter.UUIDs)
+	}
+	if filter.RSSI != 0 {
+		props["RSSI"] = dbus.MakeVariant(filter.RSSI)
+	}
+	if filter.Pathloss != 0 {
+		props["Pathloss"] = dbus.MakeVariant(filter.Pathloss)
+	}
+	if filter.Transport != "" {
+		props["Transport"] = dbus.MakeVariant(string(filter.Transport))
+	}
+	if filter.DuplicateData != nil {
+		props["DuplicateData"] = dbus.MakeVariant(*filter.DuplicateData)
+	}
+	if filter.Discoverable {
+		props["Discoverable"] = dbus.MakeVariant(true)
+	}
+	if filter.Pattern != "" {
+		props["Pattern"] = dbus.MakeVariant(filter.Pattern)
+	}
+
+	return props
+}
+
 // getDevicesInfo is a helper function that retrieves the information of discovered
 // devices in our BlueZ object.
-func (b *linuxAdapter) getDevicesInfo() error {
-	var objs map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+func (b *linuxAdapter) getDevicesInfo(ctx context.Context) error {
 	// Call the GetManagedObjects method to get all the devices that BlueZ
 	// knows about at this point.
-	objManager := b.conn.Object(b.destination, "/")
-	err := objManager.Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&objs)
+	objs, err := managedObjects(ctx, b.conn, b.destination)
 	if err != nil {
-		return fmt.Errorf("failed to get managed objects: %w", err)
+		return err
 	}
 
 	devices := make(map[string]Device)
 
 	for path, ifaceMap := range objs {
-		if dev, ok := ifaceMap[deviceInterface]; ok {
-			var name, addr string
-
-			if val, ok := dev["Address"]; ok {
-				addr = val.Value().(string)
-			} else {
-				continue
-			}
-
-			if val, ok := dev["Name"]; ok {
-				name = val.Value().(string)
-			} else {
-				name = "<unknown>"
-			}
-
-			devices[addr] = Device{name: name, address: addr, path: string(path)}
+		// BlueZ reports the devices of every adapter, so we need to skip
+		// the ones that don't belong to ours.
+		if !b.ownsPath(path) {
+			continue
+		}
+
+		if dev, ok := deviceFromInterfaces(path, ifaceMap); ok {
+			devices[dev.address] = dev
 		}
 	}
 
+	b.mu.Lock()
 	b.devices = devices
+	b.mu.Unlock()
 
 	return nil
 }
 
-// Pair attempts to pair with a Bluetooth device using its address.
-func (b *linuxAdapter) Pair(deviceAddress string) error {
-	if deviceAddress == "" {
-		return errors.New("a device address is required")
+// Pair attempts to pair with a Bluetooth device using its address. Pairing
+// with a device that is already paired is not an error. It's retried
+// according to the retry policy of the adapter.
+func (b *linuxAdapter) Pair(ctx context.Context, deviceAddress string) error {
+	device, err := b.deviceObject(ctx, deviceAddress)
+	if err != nil {
+		return b.failed(OpPair, deviceAddress, fmt.Errorf("pairing with device at addr %s failed: %w", deviceAddress, err))
 	}
 
-	devicePath := b.devices[deviceAddress].Path()
-	device := b.conn.Object(b.destination, dbus.ObjectPath(devicePath))
-
-	// Try pairing
-	err := device.Call(deviceInterface+".Pair", 0).Err
-	if err != nil {
-		if strings.Contains(err.Error(), "Already Exists") {
-			fmt.Println("Already paired. Skipping pairing step.")
-		} else {
-			return fmt.Errorf("pairing with device at addr %s failed: %w", deviceAddress, err)
+	err = b.withRetry(ctx, OpPair, deviceAddress, func() error {
+		return call(ctx, device, deviceInterface+".Pair")
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrAlreadyExists):
+		b.notify(OperationEvent{Operation: OpPair, Address: deviceAddress, Status: OperationSkipped, Message: "already paired"})
+		return nil
+	case ctx.Err() != nil:
+		// The call failed because we stopped waiting for it, but BlueZ
+		// goes on pairing anyway, so it has to be told to give up. A
+		// context done after the pairing went through changes nothing.
+		cerr := call(context.WithoutCancel(ctx), device, deviceInterface+".CancelPairing")
+		if cerr != nil && !errors.Is(cerr, ErrDoesNotExist) {
+			b.logger.Warn("failed to cancel pairing", "address", deviceAddress, "err", cerr)
 		}
-	} else {
-		fmt.Println("Paired successfully.")
+		return b.failed(OpPair, deviceAddress, fmt.Errorf("pairing with device at addr %s canceled: %w", deviceAddress, ctx.Err()))
+	default:
+		return b.failed(OpPair, deviceAddress, fmt.Errorf("pairing with device at addr %s failed: %w", deviceAddress, err))
 	}
 
+	b.notify(OperationEvent{Operation: OpPair, Address: deviceAddress, Status: OperationSucceeded, Message: "paired"})
 	return nil
 }
 
 // Trust attempts to trust a Bluetooth device using its address.
-func (b *linuxAdapter) Trust(deviceAddress string) error {
-	if deviceAddress == "" {
-		return errors.New("a device address is required")
+func (b *linuxAdapter) Trust(ctx context.Context, deviceAddress string) error {
+	device, err := b.deviceObject(ctx, deviceAddress)
+	if err != nil {
+		return b.failed(OpTrust, deviceAddress, fmt.Errorf("trusting device at addr %s failed: %w", deviceAddress, err))
+	}
+
+	b.notify(OperationEvent{Operation: OpTrust, Address: deviceAddress, Status: OperationStarted})
+	err = call(ctx, device, propertiesInterface+".Set", deviceInterface, "Trusted", dbus.MakeVariant(true))
+	if err != nil {
+		return b.failed(OpTrust, deviceAddress, fmt.Errorf("trusting device at addr %s failed: %w", deviceAddress, err))
 	}
 
-	devicePath := b.devices[deviceAddress].Path()
-	device := b.conn.Object(b.destination, dbus.ObjectPath(devicePath))
+	b.notify(OperationEvent{Operation: OpTrust, Address: deviceAddress, Status: OperationSucceeded, Message: "marked as trusted"})
+	return nil
+}
 
-	// Trust the device
-	err := device.Call(propertiesInterface+".Set", 0, deviceInterface, "Trusted", dbus.MakeVariant(true)).Err
+// Connect attempts to connect to a Bluetooth device using its address. The
+// device doesn't need to be discovered first if BlueZ already knows it. It's
+// retried according to the retry policy of the adapter.
+func (b *linuxAdapter) Connect(ctx context.Context, deviceAddress string) error {
+	device, err := b.deviceObject(ctx, deviceAddress)
 	if err != nil {
-		return fmt.Errorf("trusting device at addr %s failed: %w", deviceAddress, err)
+		return b.failed(OpConnect, deviceAddress, fmt.Errorf("connecting to device at addr %s failed: %w", deviceAddress, err))
 	}
 
-	fmt.Println("Device marked as trusted.")
+	err = b.withRetry(ctx, OpConnect, deviceAddress, func() error {
+		return call(ctx, device, deviceInterface+".Connect")
+	})
+	if err != nil {
+		return b.failed(OpConnect, deviceAddress, fmt.Errorf("connecting to device at addr %s failed: %w", deviceAddress, err))
+	}
+
+	b.notify(OperationEvent{Operation: OpConnect, Address: deviceAddress, Status: OperationSucceeded, Message: "connected"})
 	return nil
 }
 
-// Connect attempts to connect to a Bluetooth device using its address.
-func (b *linuxAdapter) Connect(deviceAddress string) error {
-	if deviceAddress == "" {
-		return errors.New("a device address is required")
+// Disconnect attempts to disconnect from a Bluetooth device using its address.
+func (b *linuxAdapter) Disconnect(ctx context.Context, deviceAddress string) error {
+	device, err := b.deviceObject(ctx, deviceAddress)
+	if err != nil {
+		return b.failed(OpDisconnect, deviceAddress, fmt.Errorf("disconnecting from device at addr %s failed: %w", deviceAddress, err))
 	}
 
-	devicePath := b.devices[deviceAddress].Path()
-	device := b.conn.Object(b.destination, dbus.ObjectPath(devicePath))
+	b.notify(OperationEvent{Operation: OpDisconnect, Address: deviceAddress, Status: OperationStarted})
+	err = call(ctx, device, deviceInterface+".Disconnect")
+	if err != nil {
+		return b.failed(OpDisconnect, deviceAddress, fmt.Errorf("disconnecting from device at addr %s failed: %w", deviceAddress, err))
+	}
+
+	b.notify(OperationEvent{Operation: OpDisconnect, Address: deviceAddress, Status: OperationSucceeded, Message: "disconnected"})
+	return nil
+}
 
-	// Try connecting
-	err := device.Call(deviceInterface+".Connect", 0).Err
+// ConnectProfile connects a single profile of the device, e.g. A2DP without
+// HFP. It's retried like Connect.
+func (b *linuxAdapter) ConnectProfile(ctx context.Context, deviceAddress, profile string) error {
+	uuid, err := profileUUID(profile)
 	if err != nil {
-		return fmt.Errorf("connecting to device at addr %s failed: %w", deviceAddress, err)
+		return b.failed(OpConnectProfile, deviceAddress, fmt.Errorf("connecting profile of device at addr %s failed: %w", deviceAddress, err))
+	}
+	device, err := b.deviceObject(ctx, deviceAddress)
+	if err != nil {
+		return b.failed(OpConnectProfile, deviceAddress, fmt.Errorf("connecting %s of device at addr %s failed: %w", ProfileName(uuid), deviceAddress, err))
+	}
+
+	err = b.withRetry(ctx, OpConnectProfile, deviceAddress, func() error {
+		return call(ctx, device, deviceInterface+".ConnectProfile", uuid)
+	})
+	if err != nil {
+		return b.failed(OpConnectProfile, deviceAddress, fmt.Errorf("connecting %s of device at addr %s failed: %w", ProfileName(uuid), deviceAddress, err))
 	}
 
-	fmt.Println("Connected successfully.")
+	b.notify(OperationEvent{Operation: OpConnectProfile, Address: deviceAddress, Status: OperationSucceeded, Message: ProfileName(uuid) + " connected"})
 	return nil
 }
 
-// Disconnect attempts to disconnect from a Bluetooth device using its address.
-func (b *linuxAdapter) Disconnect(deviceAddress string) error {
-	if deviceAddress == "" {
-		return errors.New("a device address is required")
+// DisconnectProfile disconnects a single profile of the device, leaving the
+// rest of them connected.
+func (b *linuxAdapter) DisconnectProfile(ctx context.Context, deviceAddress, profile string) error {
+	uuid, err := profileUUID(profile)
+	if err != nil {
+		return b.failed(OpDisconnectProfile, deviceAddress, fmt.Errorf("disconnecting profile of device at addr %s failed: %w", deviceAddress, err))
+	}
+	device, err := b.deviceObject(ctx, deviceAddress)
+	if err != nil {
+		return b.failed(OpDisconnectProfile, deviceAddress, fmt.Errorf("disconnecting %s of device at addr %s failed: %w", ProfileName(uuid), deviceAddress, err))
 	}
 
-	devicePath := b.devices[deviceAddress].Path()
-	device := b.conn.Object(b.destination, dbus.ObjectPath(devicePath))
+	b.notify(OperationEvent{Operation: OpDisconnectProfile, Address: deviceAddress, Status: OperationStarted})
+	err = call(ctx, device, deviceInterface+".DisconnectProfile", uuid)
+	if err != nil {
+		return b.failed(OpDisconnectProfile, deviceAddress, fmt.Errorf("disconnecting %s of device at addr %s failed: %w", ProfileName(uuid), deviceAddress, err))
+	}
 
-	err := device.Call(deviceInterface+".Disconnect", 0).Err
+	b.notify(OperationEvent{Operation: OpDisconnectProfile, Address: deviceAddress, Status: OperationSucceeded, Message: ProfileName(uuid) + " disconnected"})
+	return nil
+}
+
+// Remove removes a Bluetooth device from the adapter using its address. This
+// also removes its pairing information, so it has to be paired again before
+// it can be used.
+func (b *linuxAdapter) Remove(ctx context.Context, deviceAddress string) error {
+	dev, err := b.resolveDevice(ctx, deviceAddress)
 	if err != nil {
-		return fmt.Errorf("disconnecting from device at addr %s failed: %w", deviceAddress, err)
+		return b.failed(OpRemove, deviceAddress, fmt.Errorf("removing device at addr %s failed: %w", deviceAddress, err))
 	}
 
-	fmt.Println("Disconnected successfully.")
+	b.notify(OperationEvent{Operation: OpRemove, Address: deviceAddress, Status: OperationStarted})
+	err = call(ctx, b.adapterObj, adapterInterface+".RemoveDevice", dbus.ObjectPath(dev.Path()))
+	if err != nil {
+		return b.failed(OpRemove, deviceAddress, fmt.Errorf("removing device at addr %s failed: %w", deviceAddress, err))
+	}
+
+	// BlueZ will also report the removal through InterfacesRemoved, but we
+	// don't want to depend on a watch running to keep the cache right.
+	b.mu.Lock()
+	delete(b.devices, dev.Address())
+	b.mu.Unlock()
+
+	b.notify(OperationEvent{Operation: OpRemove, Address: deviceAddress, Status: OperationSucceeded, Message: "removed"})
 	return nil
 }
 
+// failed reports that the operation failed, and returns the error so it can
+// be given back to the caller in one go.
+func (b *linuxAdapter) failed(op Operation, addr string, err error) error {
+	b.notify(OperationEvent{Operation: op, Address: addr, Status: OperationFailed, Err: err})
+	return err
+}
+
 // Devices returns a list of discovered Bluetooth devices.
 func (b *linuxAdapter) Devices() ([]Device, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	if len(b.devices) == 0 {
 		return nil, errors.New("no devices found")
 	}
@@ -239,6 +537,50 @@ func (b *linuxAdapter) Devices() ([]Device, error) {
 	return devices, nil
 }
 
+// cachedDevice returns the cached device with the given address, if we know
+// about it.
+func (b *linuxAdapter) cachedDevice(addr string) (Device, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	dev, ok := b.devices[addr]
+	return dev, ok
+}
+
+// resolveDevice returns the device with the given address. The cache only
+// holds what we have seen so far, so on a miss we ask BlueZ for the devices
+// it knows (e.g. the ones paired on a previous run) before giving up with
+// ErrDeviceNotFound.
+func (b *linuxAdapter) resolveDevice(ctx context.Context, addr string) (Device, error) {
+	if addr == "" {
+		return Device{}, errors.New("a device address is required")
+	}
+	// BlueZ always reports addresses in upper case.
+	addr = strings.ToUpper(addr)
+
+	if dev, ok := b.cachedDevice(addr); ok {
+		return dev, nil
+	}
+
+	if err := b.getDevicesInfo(ctx); err != nil {
+		return Device{}, fmt.Errorf("failed to look up device: %w", err)
+	}
+
+	if dev, ok := b.cachedDevice(addr); ok {
+		return dev, nil
+	}
+
+	return Device{}, ErrDeviceNotFound
+}
+
+// deviceObject returns the D-Bus object of the device with the given address.
+func (b *linuxAdapter) deviceObject(ctx context.Context, addr string) (dbusObject, error) {
+	dev, err := b.resolveDevice(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return b.conn.Object(b.destination, dbus.ObjectPath(dev.Path())), nil
+}
+
 // Close closes the connection to the D-Bus used by the adapter.
 func (b *linuxAdapter) Close() error {
 	if b.conn != nil {
@@ -1,8 +1,14 @@
 package bluetooth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
 )
 
 func TestNewAdapterWithMock(t *testing.T) {
@@ -71,3 +77,295 @@ func TestNewAdapterWithMock(t *testing.T) {
 		})
 	}
 }
+
+func TestListAdapters(t *testing.T) {
+	conn := &mockDbusConn{
+		objects: map[string]*mockBusObject{
+			"/": {
+				Replies: map[string][]any{
+					objectManagerInterface + ".GetManagedObjects": {
+						map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+							"/org/bluez/hci1": {
+								adapterInterface: {
+									"Address": dbus.MakeVariant("AA:BB:CC:DD:EE:01"),
+									"Name":    dbus.MakeVariant("dongle"),
+									"Powered": dbus.MakeVariant(false),
+								},
+							},
+							"/org/bluez/hci0": {
+								adapterInterface: {
+									"Address": dbus.MakeVariant("AA:BB:CC:DD:EE:00"),
+									"Name":    dbus.MakeVariant("laptop"),
+									"Powered": dbus.MakeVariant(true),
+								},
+							},
+							"/org/bluez/hci0/dev_00_00_00_00_00_01": {
+								deviceInterface: {
+									"Address": dbus.MakeVariant("00:00:00:00:00:01"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	adapters, err := ListAdapters("", func() (dbusConn, error) { return conn, nil })
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(adapters) != 2 || adapters[0].ID() != "hci0" || adapters[1].ID() != "hci1" {
+		t.Fatalf("expected hci0 and hci1 sorted by path, got: %v", adapters)
+	}
+
+	if !adapters[0].Powered || adapters[1].Powered {
+		t.Errorf("expected only hci0 to be powered, got: %v", adapters)
+	}
+
+	if !conn.closed {
+		t.Errorf("expected connection to be closed")
+	}
+
+	queries := map[string]string{
+		"hci1":              "/org/bluez/hci1",
+		"/org/bluez/hci0":   "/org/bluez/hci0",
+		"aa:bb:cc:dd:ee:01": "/org/bluez/hci1",
+		"laptop":            "/org/bluez/hci0",
+	}
+	for query, expected := range queries {
+		info, err := FindAdapter(adapters, query)
+		if err != nil {
+			t.Errorf("expected %q to match an adapter, got: %v", query, err)
+			continue
+		}
+		if info.Path != expected {
+			t.Errorf("expected %q to match %s, got: %s", query, expected, info.Path)
+		}
+	}
+
+	if _, err := FindAdapter(adapters, "hci2"); err == nil {
+		t.Errorf("expected hci2 not to match any adapter")
+	}
+	fact := func() (dbusConn, error) { return conn, nil }
+	for query, expected := range map[string]string{"": "/org/bluez/hci0", "dongle": "/org/bluez/hci1"} {
+		info, err := SelectAdapter("", fact, query)
+		if err != nil {
+			t.Errorf("expected %q to select an adapter, got: %v", query, err)
+			continue
+		}
+		if info.Path != expected {
+			t.Errorf("expected %q to select %s, got: %s", query, expected, info.Path)
+		}
+	}
+}
+
+func TestAdapterInfo(t *testing.T) {
+	adapterObj := &mockBusObject{
+		Replies: map[string][]any{
+			propertiesInterface + ".GetAll": {
+				map[string]dbus.Variant{
+					"Address":             dbus.MakeVariant("AA:BB:CC:DD:EE:00"),
+					"Alias":               dbus.MakeVariant("bluetui"),
+					"Powered":             dbus.MakeVariant(true),
+					"Discoverable":        dbus.MakeVariant(true),
+					"DiscoverableTimeout": dbus.MakeVariant(uint32(180)),
+					"PairableTimeout":     dbus.MakeVariant(uint32(0)),
+				},
+			},
+		},
+	}
+	conn := &mockDbusConn{objects: map[string]*mockBusObject{"/org/bluez/hci0": adapterObj}}
+
+	adapter, err := NewAdapter("", "", func() (dbusConn, error) { return conn, nil })
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	info, err := adapter.Info(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if info.ID() != "hci0" || info.Alias != "bluetui" || !info.Powered || !info.Discoverable || info.Pairable {
+		t.Errorf("unexpected adapter info: %+v", info)
+	}
+	if info.DiscoverableTimeout != 3*time.Minute || info.PairableTimeout != 0 {
+		t.Errorf("unexpected timeouts: %s, %s", info.DiscoverableTimeout, info.PairableTimeout)
+	}
+
+	if err := adapter.SetPowered(t.Context(), false); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if last := adapterObj.CallHistory[len(adapterObj.CallHistory)-1]; last != propertiesInterface+".Set" {
+		t.Errorf("expected property to be set, got call to %s", last)
+	}
+}
+
+func TestConnectUnknownDevice(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	testCases := []struct {
+		name     string
+		addr     string
+		expected error
+	}{
+		// The cache is empty, so the device has to be looked up in BlueZ.
+		{name: "Known by BlueZ", addr: "00:00:00:00:00:01"},
+		{name: "Unknown device", addr: "00:00:00:00:00:09", expected: ErrDeviceNotFound},
+		{name: "Device of another adapter", addr: "00:00:00:00:00:04", expected: ErrDeviceNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			adapter, conn := newTestAdapter(t)
+			conn.objects[devicePath] = &mockBusObject{}
+
+			err := adapter.Connect(t.Context(), tc.addr)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected error %v, got: %v", tc.expected, err)
+			}
+
+			if tc.expected == nil && !slices.Contains(conn.objects[devicePath].CallHistory, deviceInterface+".Connect") {
+				t.Errorf("expected Connect to be called on %s, got: %v", devicePath, conn.objects[devicePath].CallHistory)
+			}
+		})
+	}
+}
+
+func TestPairCanceled(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	adapter, conn := newTestAdapter(t)
+	conn.objects[devicePath] = &mockBusObject{}
+	if err := adapter.getDevicesInfo(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := adapter.Pair(ctx, "00:00:00:00:00:01")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected pairing to be canceled, got: %v", err)
+	}
+
+	expected := []string{deviceInterface + ".Pair", deviceInterface + ".CancelPairing"}
+	if history := conn.objects[devicePath].CallHistory; !slices.Equal(history, expected) {
+		t.Errorf("expected calls %v, got: %v", expected, history)
+	}
+}
+
+// A context done right after the pairing went through must not undo it.
+func TestPairCanceledAfterSuccess(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	adapter, conn := newTestAdapter(t)
+	conn.objects[devicePath] = &mockBusObject{}
+	if err := adapter.getDevicesInfo(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	conn.objects[devicePath].OnCall = func(method string, args ...any) *dbus.Call {
+		if method == deviceInterface+".Pair" {
+			cancel()
+		}
+		return nil
+	}
+
+	if err := adapter.Pair(ctx, "00:00:00:00:00:01"); err != nil {
+		t.Fatalf("expected pairing to succeed, got: %v", err)
+	}
+	expected := []string{deviceInterface + ".Pair"}
+	if history := conn.objects[devicePath].CallHistory; !slices.Equal(history, expected) {
+		t.Errorf("expected calls %v, got: %v", expected, history)
+	}
+}
+
+func TestConnectRetry(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	testCases := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{name: "Transient error", err: dbus.Error{Name: "org.bluez.Error.Failed", Body: []any{"br-connection-page-timeout"}}, attempts: 3},
+		{name: "Permanent error", err: dbus.Error{Name: "org.bluez.Error.NotSupported"}, attempts: 1},
+		{name: "Success", attempts: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			adapter, conn := newTestAdapter(t)
+			conn.objects[devicePath] = &mockBusObject{Errors: map[string]error{deviceInterface + ".Connect": tc.err}}
+			adapter.retry = RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+			var retries int
+			adapter.observer = ObserverFunc(func(ev OperationEvent) {
+				if ev.Status == OperationRetrying {
+					retries++
+				}
+			})
+
+			err := adapter.Connect(t.Context(), "00:00:00:00:00:01")
+			if (err != nil) != (tc.err != nil) {
+				t.Fatalf("expected error %v, got: %v", tc.err, err)
+			}
+
+			if calls := len(conn.objects[devicePath].CallHistory); calls != tc.attempts {
+				t.Errorf("expected %d attempts, got: %d", tc.attempts, calls)
+			}
+			if retries != tc.attempts-1 {
+				t.Errorf("expected %d retry events, got: %d", tc.attempts-1, retries)
+			}
+		})
+	}
+}
+
+// recordingObject records the arguments of the calls made on a mock object.
+type recordingObject struct {
+	*mockBusObject
+	args [][]any
+}
+
+func (o *recordingObject) CallWithContext(ctx context.Context, method string, flags dbus.Flags, args ...any) *dbus.Call {
+	o.args = append(o.args, args)
+	return o.mockBusObject.CallWithContext(ctx, method, flags, args...)
+}
+
+func TestRemove(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+	if err := adapter.getDevicesInfo(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	obj := &recordingObject{mockBusObject: &mockBusObject{}}
+	adapter.adapterObj = obj
+
+	if err := adapter.Remove(t.Context(), "00:00:00:00:00:01"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := []string{adapterInterface + ".RemoveDevice"}
+	if !slices.Equal(obj.CallHistory, expected) {
+		t.Errorf("expected calls %v, got: %v", expected, obj.CallHistory)
+	}
+	if len(obj.args) != 1 || obj.args[0][0] != dbus.ObjectPath("/org/bluez/hci0/dev_00_00_00_00_00_01") {
+		t.Errorf("expected RemoveDevice to be called with the path of the device, got: %v", obj.args)
+	}
+	if _, ok := adapter.cachedDevice("00:00:00:00:00:01"); ok {
+		t.Error("expected the device to be evicted from the cache")
+	}
+
+	// The device on hci1 isn't one of ours.
+	for _, addr := range []string{"00:00:00:00:00:09", "00:00:00:00:00:04"} {
+		if err := adapter.Remove(t.Context(), addr); !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("expected ErrDeviceNotFound for %s, got: %v", addr, err)
+		}
+	}
+	if len(obj.args) != 1 {
+		t.Errorf("expected unknown devices not to be removed, got: %v", obj.args)
+	}
+}
@@ -0,0 +1,229 @@
+package bluetooth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Advertising broadcasts our own LE advertisements, e.g. to pass for a beacon
+// or to let centrals find the services of our GATT server.
+type Advertising interface {
+	// Advertise registers the advertisement with BlueZ, which broadcasts it
+	// until it's stopped or its timeout expires. The context only bounds
+	// the registration.
+	Advertise(ctx context.Context, ad Advertisement) (*ActiveAdvertisement, error)
+	// AdvertisingInfo tells how many advertisements the adapter can
+	// broadcast at once, and how many it already does.
+	AdvertisingInfo(ctx context.Context) (AdvertisingInfo, error)
+}
+
+// AdvertisementType is the kind of advertisement to broadcast.
+type AdvertisementType string
+
+const (
+	// AdvertisementBroadcast can't be connected to, like a beacon.
+	AdvertisementBroadcast AdvertisementType = "broadcast"
+	// AdvertisementPeripheral invites centrals to connect.
+	AdvertisementPeripheral AdvertisementType = "peripheral"
+)
+
+// Advertisement is the data of an LE advertisement. Everything but the type
+// is optional, but it all has to fit in the 31 bytes of a legacy
+// advertisement (or in an extended one, if the adapter supports them), or
+// BlueZ refuses to register it.
+type Advertisement struct {
+	// Type is the kind of advertisement. Empty means
+	// AdvertisementPeripheral.
+	Type      AdvertisementType
+	LocalName string
+	// ServiceUUIDs and the keys of ServiceData may be in any of the forms
+	// NormalizeUUID understands.
+	ServiceUUIDs []string
+	// ManufacturerData is keyed by company ID, e.g. 0x004c for the
+	// iBeacons of Apple.
+	ManufacturerData map[uint16][]byte
+	ServiceData      map[string][]byte
+	// TxPower, if set, is the transmission power to ask for, in dBm. It
+	// implies the tx-power include, so the power is advertised too.
+	TxPower *int16
+	// Appearance, if set, is the appearance to advertise, as assigned by
+	// the Bluetooth SIG (e.g. 0x0200 for a generic tag).
+	Appearance *uint16
+	// Discoverable sets the general discoverable flag, which makes the
+	// advertisement show up in the scans of phones. Only peripheral
+	// advertisements can be discoverable.
+	Discoverable bool
+	// Timeout is how long the advertisement lasts, in whole seconds. Zero
+	// means until it's stopped.
+	Timeout time.Duration
+	// Includes are the data BlueZ fills in on its own: tx-power,
+	// appearance, local-name (the alias of the adapter) or rsi. The adapter
+	// tells which ones it supports in AdvertisingInfo.SupportedIncludes.
+	Includes []string
+}
+
+// advertisementIncludes are the includes BlueZ knows about.
+var advertisementIncludes = []string{"tx-power", "appearance", "local-name", "rsi"}
+
+// Validate checks that the advertisement can be registered. It can't tell
+// whether the data fits, which depends on the adapter.
+func (a Advertisement) Validate() error {
+	switch a.Type {
+	case "", AdvertisementPeripheral:
+	case AdvertisementBroadcast:
+		if a.Discoverable {
+			return errors.New("broadcast advertisements can't be discoverable")
+		}
+	default:
+		return fmt.Errorf("invalid advertisement type %q, expected broadcast or peripheral", a.Type)
+	}
+
+	for _, uuid := range a.ServiceUUIDs {
+		if !validUUID(uuid) {
+			return fmt.Errorf("invalid service UUID %q", uuid)
+		}
+	}
+	for uuid := range a.ServiceData {
+		if !validUUID(uuid) {
+			return fmt.Errorf("invalid service data UUID %q", uuid)
+		}
+	}
+
+	for _, include := range a.Includes {
+		if !slices.Contains(advertisementIncludes, include) {
+			return fmt.Errorf("invalid include %q, expected one of %s", include, strings.Join(advertisementIncludes, ", "))
+		}
+	}
+
+	if a.Timeout < 0 || a.Timeout%time.Second != 0 || a.Timeout > 0xffff*time.Second {
+		return fmt.Errorf("invalid timeout %s, expected whole seconds up to 65535", a.Timeout)
+	}
+	return nil
+}
+
+// ParseManufacturerData parses manufacturer data written as comma-separated
+// company=hex entries, with the company ID in hex too, e.g. "004c=0215aabb".
+func ParseManufacturerData(s string) (map[uint16][]byte, error) {
+	entries, err := parseDataEntries(s)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[uint16][]byte, len(entries))
+	for id, value := range entries {
+		company, err := strconv.ParseUint(strings.TrimPrefix(id, "0x"), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid company ID %q", id)
+		}
+		data[uint16(company)] = value
+	}
+	return data, nil
+}
+
+// ParseServiceData parses service data written as comma-separated uuid=hex
+// entries, e.g. "feaa=10f8".
+func ParseServiceData(s string) (map[string][]byte, error) {
+	return parseDataEntries(s)
+}
+
+func parseDataEntries(s string) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, hexValue, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid data %q, expected key=hex", entry)
+		}
+		value, err := hex.DecodeString(hexValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data %q: %w", entry, err)
+		}
+		data[strings.TrimSpace(key)] = value
+	}
+	return data, nil
+}
+
+// AdvertisingInfo describes the advertising capabilities of the adapter.
+type AdvertisingInfo struct {
+	// ActiveInstances is how many advertisements are broadcast, ours and
+	// the ones of other apps.
+	ActiveInstances int
+	// SupportedInstances is how many more can be registered.
+	SupportedInstances int
+	// SupportedIncludes are the data BlueZ can add to advertisements, e.g.
+	// "tx-power", "appearance" or "local-name".
+	SupportedIncludes []string
+}
+
+// ActiveAdvertisement is an advertisement registered by Advertise. It's
+// broadcast until Stop is called, or BlueZ releases it because its timeout
+// expired or the adapter went away.
+type ActiveAdvertisement struct {
+	// Path is the object path the advertisement is exported at, which
+	// identifies it.
+	Path          string
+	Advertisement Advertisement
+	// Started is when the advertisement was registered.
+	Started time.Time
+
+	// unregister tells BlueZ to stop broadcasting the advertisement.
+	unregister func(ctx context.Context) error
+	// release stops serving the advertisement. It's called once, when it
+	// stops or is released.
+	release  func()
+	once     sync.Once
+	released chan struct{}
+}
+
+func newActiveAdvertisement(path string, ad Advertisement, unregister func(ctx context.Context) error, release func()) *ActiveAdvertisement {
+	return &ActiveAdvertisement{
+		Path:          path,
+		Advertisement: ad,
+		Started:       time.Now(),
+		unregister:    unregister,
+		release:       release,
+		released:      make(chan struct{}),
+	}
+}
+
+// Released is closed once the advertisement is no longer broadcast, be it
+// because it was stopped or because BlueZ released it.
+func (a *ActiveAdvertisement) Released() <-chan struct{} {
+	return a.released
+}
+
+// Stop unregisters the advertisement. Stopping an advertisement that was
+// already released does nothing.
+func (a *ActiveAdvertisement) Stop(ctx context.Context) error {
+	select {
+	case <-a.released:
+		return nil
+	default:
+	}
+
+	err := a.unregister(ctx)
+	a.setReleased()
+	if err != nil {
+		return fmt.Errorf("failed to stop advertisement %s: %w", a.Path, err)
+	}
+	return nil
+}
+
+// setReleased stops serving the advertisement and tells whoever waits on
+// Released.
+func (a *ActiveAdvertisement) setReleased() {
+	a.once.Do(func() {
+		a.release()
+		close(a.released)
+	})
+}
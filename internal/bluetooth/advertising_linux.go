@@ -0,0 +1,156 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	leAdvertisingManagerInterface = "org.bluez.LEAdvertisingManager1"
+	leAdvertisementInterface      = "org.bluez.LEAdvertisement1"
+	// advertisementPath is the prefix of the object paths where we export
+	// our advertisements, which are numbered since many can be active.
+	advertisementPath = "/com/github/apaydev/bluetui/advertisement"
+)
+
+// advertisementID numbers the advertisements we export, so that their paths
+// don't collide.
+var advertisementID atomic.Uint32
+
+// advertisementProperties returns the org.bluez.LEAdvertisement1 properties
+// of the advertisement. BlueZ only reads them once, when registering it.
+func advertisementProperties(ad Advertisement) map[string]dbus.Variant {
+	adType := ad.Type
+	if adType == "" {
+		adType = AdvertisementPeripheral
+	}
+	props := map[string]dbus.Variant{"Type": dbus.MakeVariant(string(adType))}
+
+	if ad.LocalName != "" {
+		props["LocalName"] = dbus.MakeVariant(ad.LocalName)
+	}
+	if len(ad.ServiceUUIDs) > 0 {
+		uuids := make([]string, len(ad.ServiceUUIDs))
+		for i, uuid := range ad.ServiceUUIDs {
+			uuids[i] = NormalizeUUID(uuid)
+		}
+		props["ServiceUUIDs"] = dbus.MakeVariant(uuids)
+	}
+	if len(ad.ManufacturerData) > 0 {
+		data := make(map[uint16]dbus.Variant, len(ad.ManufacturerData))
+		for id, value := range ad.ManufacturerData {
+			data[id] = dbus.MakeVariant(value)
+		}
+		props["ManufacturerData"] = dbus.MakeVariant(data)
+	}
+	if len(ad.ServiceData) > 0 {
+		data := make(map[string]dbus.Variant, len(ad.ServiceData))
+		for uuid, value := range ad.ServiceData {
+			data[NormalizeUUID(uuid)] = dbus.MakeVariant(value)
+		}
+		props["ServiceData"] = dbus.MakeVariant(data)
+	}
+	includes := slices.Clone(ad.Includes)
+	if ad.TxPower != nil {
+		props["TxPower"] = dbus.MakeVariant(*ad.TxPower)
+		if !slices.Contains(includes, "tx-power") {
+			includes = append(includes, "tx-power")
+		}
+	}
+	if len(includes) > 0 {
+		props["Includes"] = dbus.MakeVariant(includes)
+	}
+	if ad.Appearance != nil {
+		props["Appearance"] = dbus.MakeVariant(*ad.Appearance)
+	}
+	// BlueZ only sets the discoverable flag of peripheral advertisements,
+	// and refuses the property on broadcast ones.
+	if adType == AdvertisementPeripheral {
+		props["Discoverable"] = dbus.MakeVariant(ad.Discoverable)
+	}
+	if ad.Timeout > 0 {
+		props["Timeout"] = dbus.MakeVariant(uint16(ad.Timeout.Seconds()))
+	}
+	return props
+}
+
+// advertisement is the org.bluez.LEAdvertisement1 object of an active
+// advertisement.
+type advertisement struct {
+	active *ActiveAdvertisement
+}
+
+// Release is called by BlueZ when it stops broadcasting the advertisement
+// on its own, e.g. because its timeout expired.
+func (a *advertisement) Release() *dbus.Error {
+	// We are called from the goroutine that serves our objects, so the
+	// advertisement is unexported from another one.
+	go a.active.setReleased()
+	return nil
+}
+
+// Advertise exports the advertisement and registers it with the advertising
+// manager of the adapter.
+func (b *linuxAdapter) Advertise(ctx context.Context, ad Advertisement) (*ActiveAdvertisement, error) {
+	if err := ad.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid advertisement: %w", err)
+	}
+
+	path := dbus.ObjectPath(fmt.Sprintf("%s%d", advertisementPath, advertisementID.Add(1)))
+	unexport := func() {
+		for _, iface := range []string{leAdvertisementInterface, propertiesInterface} {
+			if err := b.conn.Export(nil, path, iface); err != nil {
+				b.logger.Warn("failed to stop exporting advertisement", "path", path, "error", err)
+			}
+		}
+	}
+	active := newActiveAdvertisement(string(path), ad,
+		func(ctx context.Context) error {
+			return call(ctx, b.adapterObj, leAdvertisingManagerInterface+".UnregisterAdvertisement", path)
+		},
+		unexport,
+	)
+
+	props := advertisementProperties(ad)
+	err := b.conn.Export(&exportedProperties{leAdvertisementInterface, func() map[string]dbus.Variant { return props }}, path, propertiesInterface)
+	if err == nil {
+		err = b.conn.Export(&advertisement{active}, path, leAdvertisementInterface)
+	}
+	if err != nil {
+		unexport()
+		return nil, fmt.Errorf("failed to export advertisement: %w", err)
+	}
+
+	err = call(ctx, b.adapterObj, leAdvertisingManagerInterface+".RegisterAdvertisement", path, map[string]dbus.Variant{})
+	if err != nil {
+		unexport()
+		return nil, fmt.Errorf("failed to register advertisement: %w", err)
+	}
+
+	return active, nil
+}
+
+// AdvertisingInfo reads the properties of the advertising manager of the
+// adapter.
+func (b *linuxAdapter) AdvertisingInfo(ctx context.Context) (AdvertisingInfo, error) {
+	var props map[string]dbus.Variant
+	err := mapError(b.adapterObj.CallWithContext(ctx, propertiesInterface+".GetAll", 0, leAdvertisingManagerInterface).Store(&props))
+	if err != nil {
+		return AdvertisingInfo{}, fmt.Errorf("failed to get advertising properties: %w", err)
+	}
+
+	p := properties(props)
+	var info AdvertisingInfo
+	if v, ok := p.byteValue("ActiveInstances"); ok {
+		info.ActiveInstances = int(v)
+	}
+	if v, ok := p.byteValue("SupportedInstances"); ok {
+		info.SupportedInstances = int(v)
+	}
+	info.SupportedIncludes, _ = p.stringsValue("SupportedIncludes")
+	return info, nil
+}
@@ -0,0 +1,130 @@
+package bluetooth
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestAdvertisementProperties(t *testing.T) {
+	txPower := int16(-8)
+	props := advertisementProperties(Advertisement{
+		Type:             AdvertisementBroadcast,
+		LocalName:        "beacon",
+		ServiceUUIDs:     []string{"feaa"},
+		ManufacturerData: map[uint16][]byte{0x004c: {0x02, 0x15}},
+		ServiceData:      map[string][]byte{"feaa": {0x10, 0x00}},
+		TxPower:          &txPower,
+		Timeout:          30 * time.Second,
+	})
+
+	expected := map[string]dbus.Variant{
+		"Type":             dbus.MakeVariant("broadcast"),
+		"LocalName":        dbus.MakeVariant("beacon"),
+		"ServiceUUIDs":     dbus.MakeVariant([]string{"0000feaa-0000-1000-8000-00805f9b34fb"}),
+		"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{0x004c: dbus.MakeVariant([]byte{0x02, 0x15})}),
+		"ServiceData":      dbus.MakeVariant(map[string]dbus.Variant{"0000feaa-0000-1000-8000-00805f9b34fb": dbus.MakeVariant([]byte{0x10, 0x00})}),
+		"TxPower":          dbus.MakeVariant(int16(-8)),
+		"Includes":         dbus.MakeVariant([]string{"tx-power"}),
+		"Timeout":          dbus.MakeVariant(uint16(30)),
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("expected properties %v, got: %v", expected, props)
+	}
+
+	// The tx-power include of TxPower comes on top of the other ones.
+	props = advertisementProperties(Advertisement{TxPower: &txPower, Includes: []string{"local-name", "appearance"}})
+	if includes := props["Includes"].Value(); !reflect.DeepEqual(includes, []string{"local-name", "appearance", "tx-power"}) {
+		t.Errorf("expected the local-name, appearance and tx-power includes, got: %v", includes)
+	}
+
+	// Peripheral advertisements always tell whether they are discoverable.
+	props = advertisementProperties(Advertisement{Discoverable: true})
+	if props["Type"].Value() != "peripheral" || props["Discoverable"].Value() != true {
+		t.Errorf("expected a discoverable peripheral advertisement, got: %v", props)
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+	manager := adapter.adapterObj.(*mockBusObject)
+
+	active, err := adapter.Advertise(t.Context(), Advertisement{LocalName: "bluetui"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(manager.CallHistory, []string{leAdvertisingManagerInterface + ".RegisterAdvertisement"}) {
+		t.Errorf("expected the advertisement to be registered, got: %v", manager.CallHistory)
+	}
+	props, dbusErr := conn.exported[active.Path+" "+propertiesInterface].(*exportedProperties).GetAll(leAdvertisementInterface)
+	if dbusErr != nil || props["LocalName"].Value() != "bluetui" {
+		t.Errorf("expected the properties of the advertisement, got: %v (%v)", props, dbusErr)
+	}
+
+	// BlueZ releases the advertisement once its timeout expires.
+	if err := conn.exported[active.Path+" "+leAdvertisementInterface].(*advertisement).Release(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	select {
+	case <-active.Released():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the advertisement to be released")
+	}
+	if len(conn.exported) != 0 {
+		t.Errorf("expected the advertisement to be unexported, got: %v", conn.exported)
+	}
+
+	// There's nothing to unregister once released.
+	if err := active.Stop(t.Context()); err != nil || len(manager.CallHistory) != 1 {
+		t.Errorf("expected stop to do nothing, got: %v (%v)", manager.CallHistory, err)
+	}
+}
+
+func TestAdvertiseStop(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+	manager := adapter.adapterObj.(*mockBusObject)
+
+	first, err := adapter.Advertise(t.Context(), Advertisement{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	second, err := adapter.Advertise(t.Context(), Advertisement{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if first.Path == second.Path {
+		t.Fatalf("expected advertisements at different paths, got: %s", first.Path)
+	}
+
+	if err := first.Stop(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if manager.CallHistory[2] != leAdvertisingManagerInterface+".UnregisterAdvertisement" {
+		t.Errorf("expected the advertisement to be unregistered, got: %v", manager.CallHistory)
+	}
+	if _, ok := conn.exported[second.Path+" "+leAdvertisementInterface]; !ok || len(conn.exported) != 2 {
+		t.Errorf("expected only the second advertisement to be left, got: %v", conn.exported)
+	}
+}
+
+func TestAdvertisingInfo(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+	adapter.adapterObj.(*mockBusObject).Replies = map[string][]any{
+		propertiesInterface + ".GetAll": {map[string]dbus.Variant{
+			"ActiveInstances":    dbus.MakeVariant(byte(1)),
+			"SupportedInstances": dbus.MakeVariant(byte(4)),
+			"SupportedIncludes":  dbus.MakeVariant([]string{"tx-power", "appearance", "local-name"}),
+		}},
+	}
+
+	info, err := adapter.AdvertisingInfo(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if info.ActiveInstances != 1 || info.SupportedInstances != 4 || len(info.SupportedIncludes) != 3 {
+		t.Errorf("unexpected advertising info: %+v", info)
+	}
+}
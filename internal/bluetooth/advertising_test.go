@@ -0,0 +1,79 @@
+package bluetooth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdvertisementValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ad       Advertisement
+		expected string
+	}{
+		{name: "empty", ad: Advertisement{}},
+		{
+			name: "beacon",
+			ad: Advertisement{
+				Type:             AdvertisementBroadcast,
+				ManufacturerData: map[uint16][]byte{0x004c: {0x02, 0x15}},
+				Timeout:          time.Minute,
+			},
+		},
+		{name: "invalid type", ad: Advertisement{Type: "beacon"}, expected: "invalid advertisement type"},
+		{
+			name:     "discoverable broadcast",
+			ad:       Advertisement{Type: AdvertisementBroadcast, Discoverable: true},
+			expected: "can't be discoverable",
+		},
+		{name: "invalid UUID", ad: Advertisement{ServiceUUIDs: []string{"180"}}, expected: "invalid service UUID"},
+		{
+			name:     "invalid service data UUID",
+			ad:       Advertisement{ServiceData: map[string][]byte{"feaa-": {0x10}}},
+			expected: "invalid service data UUID",
+		},
+		{name: "includes", ad: Advertisement{Includes: []string{"local-name", "appearance"}}},
+		{name: "invalid include", ad: Advertisement{Includes: []string{"name"}}, expected: "invalid include"},
+		{name: "fractional timeout", ad: Advertisement{Timeout: 1500 * time.Millisecond}, expected: "invalid timeout"},
+		{name: "long timeout", ad: Advertisement{Timeout: 24 * time.Hour}, expected: "invalid timeout"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.ad.Validate()
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error containing %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseAdvertisementData(t *testing.T) {
+	manufacturer, err := ParseManufacturerData("004c=0215, 0x0059=aabb")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := map[uint16][]byte{0x004c: {0x02, 0x15}, 0x0059: {0xaa, 0xbb}}
+	if !reflect.DeepEqual(manufacturer, expected) {
+		t.Errorf("expected %v, got: %v", expected, manufacturer)
+	}
+
+	service, err := ParseServiceData("feaa=10f8")
+	if err != nil || !reflect.DeepEqual(service, map[string][]byte{"feaa": {0x10, 0xf8}}) {
+		t.Errorf("expected the eddystone service data, got: %v (%v)", service, err)
+	}
+
+	for _, s := range []string{"004c", "004c=02x", "apple=0215"} {
+		if _, err := ParseManufacturerData(s); err == nil {
+			t.Errorf("expected %q to be refused", s)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package bluetooth
+
+// AgentCapability describes the input and output capabilities of an agent,
+// which BlueZ uses to pick the pairing method.
+type AgentCapability string
+
+const (
+	CapabilityDisplayOnly     AgentCapability = "DisplayOnly"
+	CapabilityDisplayYesNo    AgentCapability = "DisplayYesNo"
+	CapabilityKeyboardOnly    AgentCapability = "KeyboardOnly"
+	CapabilityNoInputNoOutput AgentCapability = "NoInputNoOutput"
+	CapabilityKeyboardDisplay AgentCapability = "KeyboardDisplay"
+)
+
+// AgentHandler receives the requests made by BlueZ while pairing with a
+// device. Devices are identified by their address.
+//
+// Methods are called from the goroutine that serves D-Bus calls, and they may
+// block until the user answers. BlueZ calls Cancel if it stops waiting for an
+// answer (e.g. the pairing timed out).
+type AgentHandler interface {
+	// RequestPinCode asks for the PIN code of a legacy device.
+	RequestPinCode(device string) (string, error)
+	// DisplayPinCode asks to show the PIN code that must be typed on the
+	// device.
+	DisplayPinCode(device, pinCode string) error
+	// RequestPasskey asks for the 6-digit passkey shown by the device.
+	RequestPasskey(device string) (uint32, error)
+	// DisplayPasskey asks to show the passkey that must be typed on the
+	// device. It's called again for each key pressed on the device, with the
+	// number of digits entered so far.
+	DisplayPasskey(device string, passkey uint32, entered uint16)
+	// RequestConfirmation asks to confirm that the passkey matches the one
+	// shown by the device (numeric comparison). Returning nil confirms it.
+	RequestConfirmation(device string, passkey uint32) error
+	// RequestAuthorization asks to authorize a pairing that didn't go
+	// through any of the other methods. Returning nil authorizes it.
+	RequestAuthorization(device string) error
+	// AuthorizeService asks to authorize a connection to the service with
+	// the given UUID. Returning nil authorizes it.
+	AuthorizeService(device, uuid string) error
+	// Cancel tells that the ongoing request was canceled by BlueZ.
+	Cancel()
+}
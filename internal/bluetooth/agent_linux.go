@@ -0,0 +1,133 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// BlueZ exposes the agent manager on its root object, which is shared by
+	// every adapter.
+	agentManagerPath      = "/org/bluez"
+	agentManagerInterface = "org.bluez.AgentManager1"
+	agentInterface        = "org.bluez.Agent1"
+	// agentPath is the object path where we export our own agent.
+	agentPath = "/com/github/apaydev/bluetui/agent"
+)
+
+// agent is the org.bluez.Agent1 object that we export on the bus. It
+// forwards every request to an AgentHandler.
+type agent struct {
+	handler AgentHandler
+}
+
+// Release is called by BlueZ when it unregisters the agent. There's nothing
+// to clean up on our side.
+func (a *agent) Release() *dbus.Error {
+	return nil
+}
+
+func (a *agent) RequestPinCode(device dbus.ObjectPath) (string, *dbus.Error) {
+	pin, err := a.handler.RequestPinCode(addressFromPath(device))
+	if err != nil {
+		return "", agentError(err)
+	}
+	return pin, nil
+}
+
+func (a *agent) DisplayPinCode(device dbus.ObjectPath, pinCode string) *dbus.Error {
+	return agentError(a.handler.DisplayPinCode(addressFromPath(device), pinCode))
+}
+
+func (a *agent) RequestPasskey(device dbus.ObjectPath) (uint32, *dbus.Error) {
+	passkey, err := a.handler.RequestPasskey(addressFromPath(device))
+	if err != nil {
+		return 0, agentError(err)
+	}
+	return passkey, nil
+}
+
+func (a *agent) DisplayPasskey(device dbus.ObjectPath, passkey uint32, entered uint16) *dbus.Error {
+	a.handler.DisplayPasskey(addressFromPath(device), passkey, entered)
+	return nil
+}
+
+func (a *agent) RequestConfirmation(device dbus.ObjectPath, passkey uint32) *dbus.Error {
+	return agentError(a.handler.RequestConfirmation(addressFromPath(device), passkey))
+}
+
+func (a *agent) RequestAuthorization(device dbus.ObjectPath) *dbus.Error {
+	return agentError(a.handler.RequestAuthorization(addressFromPath(device)))
+}
+
+func (a *agent) AuthorizeService(device dbus.ObjectPath, uuid string) *dbus.Error {
+	return agentError(a.handler.AuthorizeService(addressFromPath(device), uuid))
+}
+
+func (a *agent) Cancel() *dbus.Error {
+	a.handler.Cancel()
+	return nil
+}
+
+// agentError translates the error returned by an AgentHandler into the one
+// that BlueZ expects from an agent.
+func agentError(err error) *dbus.Error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrCanceled):
+		return dbus.NewError("org.bluez.Error.Canceled", []any{err.Error()})
+	default:
+		return dbus.NewError("org.bluez.Error.Rejected", []any{err.Error()})
+	}
+}
+
+// addressFromPath extracts the address of a device out of its object path,
+// e.g. /org/bluez/hci0/dev_00_11_22_33_44_55 gives 00:11:22:33:44:55.
+func addressFromPath(devicePath dbus.ObjectPath) string {
+	base := path.Base(string(devicePath))
+	return strings.ReplaceAll(strings.TrimPrefix(base, "dev_"), "_", ":")
+}
+
+// RegisterAgent exports an agent that forwards pairing requests to the given
+// handler, and registers it with BlueZ with the given capability. BlueZ uses
+// it for every pairing started through this adapter.
+func (b *linuxAdapter) RegisterAgent(ctx context.Context, capability AgentCapability, handler AgentHandler) error {
+	if handler == nil {
+		return errors.New("an agent handler is required")
+	}
+
+	err := b.conn.Export(&agent{handler: handler}, agentPath, agentInterface)
+	if err != nil {
+		return fmt.Errorf("failed to export agent: %w", err)
+	}
+
+	manager := b.conn.Object(b.destination, agentManagerPath)
+	err = call(ctx, manager, agentManagerInterface+".RegisterAgent", dbus.ObjectPath(agentPath), string(capability))
+	if err != nil {
+		_ = b.conn.Export(nil, agentPath, agentInterface)
+		return fmt.Errorf("failed to register agent: %w", err)
+	}
+
+	return nil
+}
+
+// UnregisterAgent unregisters the agent from BlueZ and stops serving it.
+func (b *linuxAdapter) UnregisterAgent(ctx context.Context) error {
+	manager := b.conn.Object(b.destination, agentManagerPath)
+	err := call(ctx, manager, agentManagerInterface+".UnregisterAgent", dbus.ObjectPath(agentPath))
+	if err != nil {
+		return fmt.Errorf("failed to unregister agent: %w", err)
+	}
+
+	if err := b.conn.Export(nil, agentPath, agentInterface); err != nil {
+		return fmt.Errorf("failed to stop exporting agent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package bluetooth
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeAgentHandler answers every request with fixed values.
+type fakeAgentHandler struct {
+	pin      string
+	passkey  uint32
+	err      error
+	devices  []string
+	canceled bool
+}
+
+func (f *fakeAgentHandler) RequestPinCode(device string) (string, error) {
+	f.devices = append(f.devices, device)
+	return f.pin, f.err
+}
+
+func (f *fakeAgentHandler) DisplayPinCode(device, pinCode string) error {
+	f.devices = append(f.devices, device)
+	return f.err
+}
+
+func (f *fakeAgentHandler) RequestPasskey(device string) (uint32, error) {
+	f.devices = append(f.devices, device)
+	return f.passkey, f.err
+}
+
+func (f *fakeAgentHandler) DisplayPasskey(device string, passkey uint32, entered uint16) {
+	f.devices = append(f.devices, device)
+}
+
+func (f *fakeAgentHandler) RequestConfirmation(device string, passkey uint32) error {
+	f.devices = append(f.devices, device)
+	return f.err
+}
+
+func (f *fakeAgentHandler) RequestAuthorization(device string) error {
+	f.devices = append(f.devices, device)
+	return f.err
+}
+
+func (f *fakeAgentHandler) AuthorizeService(device, uuid string) error {
+	f.devices = append(f.devices, device)
+	return f.err
+}
+
+func (f *fakeAgentHandler) Cancel() {
+	f.canceled = true
+}
+
+func TestAgent(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_11_22_33_44_55"
+
+	handler := &fakeAgentHandler{pin: "0000", passkey: 123456}
+	a := &agent{handler: handler}
+
+	if pin, err := a.RequestPinCode(devicePath); err != nil || pin != "0000" {
+		t.Errorf("expected PIN 0000, got: %q (%v)", pin, err)
+	}
+	if passkey, err := a.RequestPasskey(devicePath); err != nil || passkey != 123456 {
+		t.Errorf("expected passkey 123456, got: %d (%v)", passkey, err)
+	}
+	if err := a.RequestConfirmation(devicePath, 123456); err != nil {
+		t.Errorf("expected confirmation, got: %v", err)
+	}
+	if handler.devices[0] != "00:11:22:33:44:55" {
+		t.Errorf("expected device address to be resolved from its path, got: %s", handler.devices[0])
+	}
+
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{err: ErrRejected, expected: "org.bluez.Error.Rejected"},
+		{err: ErrCanceled, expected: "org.bluez.Error.Canceled"},
+		{err: errors.New("wrong PIN"), expected: "org.bluez.Error.Rejected"},
+	}
+
+	for _, tc := range testCases {
+		handler.err = tc.err
+		if err := a.RequestAuthorization(devicePath); err == nil || err.Name != tc.expected {
+			t.Errorf("expected %v to be reported as %s, got: %v", tc.err, tc.expected, err)
+		}
+	}
+
+	a.Cancel()
+	if !handler.canceled {
+		t.Errorf("expected handler to be canceled")
+	}
+}
+
+func TestRegisterAgent(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+
+	if err := adapter.RegisterAgent(t.Context(), CapabilityKeyboardDisplay, &fakeAgentHandler{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := conn.exported[agentPath+" "+agentInterface]; !ok {
+		t.Fatalf("expected agent to be exported")
+	}
+
+	if err := adapter.UnregisterAgent(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := conn.exported[agentPath+" "+agentInterface]; ok {
+		t.Errorf("expected agent to stop being exported")
+	}
+}
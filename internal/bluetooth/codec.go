@@ -0,0 +1,300 @@
+package bluetooth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A2DP codec IDs, as found in MediaTransport1.Codec. The vendor ones are all
+// CodecVendor, and tell themselves apart with the vendor and codec IDs at the
+// start of their configuration.
+const (
+	CodecSBC    byte = 0x00
+	CodecMPEG12 byte = 0x01
+	CodecAAC    byte = 0x02
+	CodecATRAC  byte = 0x04
+	CodecVendor byte = 0xff
+)
+
+// HFP codec IDs, as found in MediaTransport1.Codec for the transports of the
+// Handsfree and Headset profiles. They are negotiated with AT commands, so
+// these transports carry no configuration to decode.
+const (
+	CodecCVSD   byte = 0x01
+	CodecMSBC   byte = 0x02
+	CodecLC3SWB byte = 0x03
+)
+
+// Vendor codecs we know about, by vendor and codec ID.
+var vendorCodecs = []struct {
+	vendorID uint32
+	codecID  uint16
+	name     string
+}{
+	{vendorID: 0x0000004f, codecID: 0x0001, name: "aptX"},
+	{vendorID: 0x000000d7, codecID: 0x0024, name: "aptX HD"},
+	{vendorID: 0x0000000a, codecID: 0x0002, name: "aptX Low Latency"},
+	{vendorID: 0x0000000a, codecID: 0x0001, name: "FastStream"},
+	{vendorID: 0x0000012d, codecID: 0x00aa, name: "LDAC"},
+	{vendorID: 0x000000e0, codecID: 0x0001, name: "Opus"},
+}
+
+// ErrInvalidCodecConfig is returned when a codec configuration is too short
+// for its codec.
+var ErrInvalidCodecConfig = errors.New("invalid codec configuration")
+
+// CodecConfig is the configuration negotiated for an audio stream. Only the
+// fields that make sense for the codec are set, the rest are left empty.
+type CodecConfig struct {
+	// Codec is the A2DP or HFP codec ID, e.g. CodecSBC or CodecMSBC.
+	Codec byte
+	// Name is the name of the codec, e.g. "SBC" or "LDAC".
+	Name string
+	// VendorID and VendorCodecID identify vendor codecs.
+	VendorID      uint32
+	VendorCodecID uint16
+	// SampleRate is in Hz.
+	SampleRate  int
+	ChannelMode string
+	// BlockLength, Subbands, Allocation and the bitpool range are SBC only.
+	BlockLength int
+	Subbands    int
+	Allocation  string
+	MinBitpool  int
+	MaxBitpool  int
+	// ObjectType, Bitrate (in bits per second) and VBR are AAC only.
+	ObjectType string
+	Bitrate    int
+	VBR        bool
+	// Raw is the configuration as we got it.
+	Raw []byte
+}
+
+// String summarizes the configuration in one line, e.g.
+// "SBC 44.1 kHz joint stereo, bitpool 2-53".
+func (c CodecConfig) String() string {
+	parts := []string{c.Name}
+	if c.SampleRate > 0 {
+		parts = append(parts, formatSampleRate(c.SampleRate))
+	}
+	if c.ChannelMode != "" {
+		parts = append(parts, c.ChannelMode)
+	}
+	s := strings.Join(parts, " ")
+
+	var details []string
+	if c.ObjectType != "" {
+		details = append(details, c.ObjectType)
+	}
+	if c.MaxBitpool > 0 {
+		details = append(details, fmt.Sprintf("bitpool %d-%d", c.MinBitpool, c.MaxBitpool))
+	}
+	if c.Bitrate > 0 {
+		details = append(details, fmt.Sprintf("%d kbps", c.Bitrate/1000))
+	}
+	if c.VBR {
+		details = append(details, "VBR")
+	}
+	if len(details) > 0 {
+		s += ", " + strings.Join(details, ", ")
+	}
+	return s
+}
+
+func formatSampleRate(hz int) string {
+	if hz%1000 == 0 {
+		return fmt.Sprintf("%d kHz", hz/1000)
+	}
+	return fmt.Sprintf("%.1f kHz", float64(hz)/1000)
+}
+
+// DecodeCodecConfig decodes the Codec and Configuration properties of a
+// MediaTransport1 (the A2DP codec capabilities, see the A2DP spec and the
+// a2dp-codecs.h header of BlueZ). Codecs we don't know are still returned,
+// named after their ID, with only Raw set.
+func DecodeCodecConfig(codec byte, config []byte) (CodecConfig, error) {
+	c := CodecConfig{Codec: codec, Raw: config}
+
+	switch codec {
+	case CodecSBC:
+		c.Name = "SBC"
+		return c, c.decodeSBC(config)
+	case CodecMPEG12:
+		c.Name = "MPEG-1,2 Audio"
+	case CodecAAC:
+		c.Name = "AAC"
+		return c, c.decodeAAC(config)
+	case CodecATRAC:
+		c.Name = "ATRAC"
+	case CodecVendor:
+		return c, c.decodeVendor(config)
+	default:
+		c.Name = fmt.Sprintf("codec 0x%02x", codec)
+	}
+	return c, nil
+}
+
+// DecodeTransportCodec decodes the codec of a transport of the given profile
+// UUID. Only A2DP transports go through DecodeCodecConfig: the IDs of the
+// Handsfree and Headset ones mean something else (e.g. 0x01 is CVSD there, not
+// MPEG-1,2 Audio).
+func DecodeTransportCodec(uuid string, codec byte, config []byte) (CodecConfig, error) {
+	switch uuid {
+	case UUIDAudioSource, UUIDAudioSink:
+		return DecodeCodecConfig(codec, config)
+	case UUIDHandsfree, UUIDHandsfreeGateway, UUIDHeadset, UUIDHeadsetGateway:
+		return decodeHFPCodec(codec, config), nil
+	}
+	return CodecConfig{Codec: codec, Name: fmt.Sprintf("codec 0x%02x", codec), Raw: config}, nil
+}
+
+// decodeHFPCodec names the codec of a Handsfree or Headset transport. All of
+// them are mono, and run at a fixed sample rate.
+func decodeHFPCodec(codec byte, config []byte) CodecConfig {
+	c := CodecConfig{Codec: codec, Raw: config}
+
+	switch codec {
+	case CodecCVSD:
+		c.Name, c.SampleRate, c.ChannelMode = "CVSD", 8000, "mono"
+	case CodecMSBC:
+		c.Name, c.SampleRate, c.ChannelMode = "mSBC", 16000, "mono"
+	case CodecLC3SWB:
+		c.Name, c.SampleRate, c.ChannelMode = "LC3-SWB", 32000, "mono"
+	default:
+		c.Name = fmt.Sprintf("codec 0x%02x", codec)
+	}
+	return c
+}
+
+// bitValue returns the value of the first flag set in b, going through them
+// in order. The configurations have a single flag set per field, since they
+// are picked out of the capabilities of both ends.
+func bitValue[T any](b byte, flags []byte, values []T) (T, bool) {
+	for i, flag := range flags {
+		if b&flag != 0 {
+			return values[i], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+var (
+	sbcSampleRateFlags  = []byte{0x80, 0x40, 0x20, 0x10}
+	sbcSampleRates      = []int{16000, 32000, 44100, 48000}
+	sbcChannelModeFlags = []byte{0x08, 0x04, 0x02, 0x01}
+	sbcChannelModes     = []string{"mono", "dual channel", "stereo", "joint stereo"}
+	sbcBlockFlags       = []byte{0x80, 0x40, 0x20, 0x10}
+	sbcBlockLengths     = []int{4, 8, 12, 16}
+	sbcSubbandFlags     = []byte{0x08, 0x04}
+	sbcSubbands         = []int{4, 8}
+	sbcAllocationFlags  = []byte{0x02, 0x01}
+	sbcAllocations      = []string{"SNR", "loudness"}
+)
+
+// decodeSBC decodes the 4 bytes of SBC: the sample rate and channel mode,
+// the block length, subbands and allocation method, and the bitpool range.
+func (c *CodecConfig) decodeSBC(config []byte) error {
+	if len(config) < 4 {
+		return fmt.Errorf("%w: SBC needs 4 bytes, got %d", ErrInvalidCodecConfig, len(config))
+	}
+
+	c.SampleRate, _ = bitValue(config[0]&0xf0, sbcSampleRateFlags, sbcSampleRates)
+	c.ChannelMode, _ = bitValue(config[0]&0x0f, sbcChannelModeFlags, sbcChannelModes)
+	c.BlockLength, _ = bitValue(config[1]&0xf0, sbcBlockFlags, sbcBlockLengths)
+	c.Subbands, _ = bitValue(config[1]&0x0c, sbcSubbandFlags, sbcSubbands)
+	c.Allocation, _ = bitValue(config[1]&0x03, sbcAllocationFlags, sbcAllocations)
+	c.MinBitpool = int(config[2])
+	c.MaxBitpool = int(config[3])
+	return nil
+}
+
+var (
+	aacObjectTypeFlags = []byte{0x80, 0x40, 0x20, 0x10}
+	aacObjectTypes     = []string{"MPEG-2 AAC LC", "MPEG-4 AAC LC", "MPEG-4 AAC LTP", "MPEG-4 AAC scalable"}
+)
+
+// aacSampleRates follow the 12 bits of sample rate flags of AAC, from the
+// highest one down.
+var aacSampleRates = []int{8000, 11025, 12000, 16000, 22050, 24000, 32000, 44100, 48000, 64000, 88200, 96000}
+
+// decodeAAC decodes the 6 bytes of AAC: the object type, 12 bits of sample
+// rate flags, the channels, and a VBR flag followed by 23 bits of bitrate.
+func (c *CodecConfig) decodeAAC(config []byte) error {
+	if len(config) < 6 {
+		return fmt.Errorf("%w: AAC needs 6 bytes, got %d", ErrInvalidCodecConfig, len(config))
+	}
+
+	c.ObjectType, _ = bitValue(config[0], aacObjectTypeFlags, aacObjectTypes)
+
+	rates := uint16(config[1])<<4 | uint16(config[2]>>4)
+	for i, rate := range aacSampleRates {
+		if rates&(0x800>>i) != 0 {
+			c.SampleRate = rate
+			break
+		}
+	}
+
+	switch {
+	case config[2]&0x08 != 0:
+		c.ChannelMode = "mono"
+	case config[2]&0x04 != 0:
+		c.ChannelMode = "stereo"
+	}
+
+	c.VBR = config[3]&0x80 != 0
+	c.Bitrate = int(config[3]&0x7f)<<16 | int(config[4])<<8 | int(config[5])
+	return nil
+}
+
+var (
+	aptxSampleRateFlags  = []byte{0x80, 0x40, 0x20, 0x10}
+	aptxSampleRates      = []int{16000, 32000, 44100, 48000}
+	aptxChannelModeFlags = []byte{0x01, 0x02}
+	aptxChannelModes     = []string{"mono", "stereo"}
+
+	ldacSampleRateFlags  = []byte{0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
+	ldacSampleRates      = []int{44100, 48000, 88200, 96000, 176400, 192000}
+	ldacChannelModeFlags = []byte{0x04, 0x02, 0x01}
+	ldacChannelModes     = []string{"mono", "dual channel", "stereo"}
+)
+
+// decodeVendor decodes vendor codecs, which start with a 4 bytes vendor ID
+// and a 2 bytes codec ID, both little endian, followed by their own fields.
+func (c *CodecConfig) decodeVendor(config []byte) error {
+	if len(config) < 6 {
+		c.Name = "vendor codec"
+		return fmt.Errorf("%w: vendor codecs need at least 6 bytes, got %d", ErrInvalidCodecConfig, len(config))
+	}
+
+	c.VendorID = binary.LittleEndian.Uint32(config[0:4])
+	c.VendorCodecID = binary.LittleEndian.Uint16(config[4:6])
+	c.Name = fmt.Sprintf("vendor codec 0x%08x:0x%04x", c.VendorID, c.VendorCodecID)
+	for _, v := range vendorCodecs {
+		if v.vendorID == c.VendorID && v.codecID == c.VendorCodecID {
+			c.Name = v.name
+			break
+		}
+	}
+
+	fields := config[6:]
+	switch c.Name {
+	case "aptX", "aptX HD", "aptX Low Latency", "FastStream":
+		// FastStream packs its sample rates differently, and there's not
+		// much to tell about it anyway.
+		if c.Name == "FastStream" || len(fields) < 1 {
+			return nil
+		}
+		c.SampleRate, _ = bitValue(fields[0]&0xf0, aptxSampleRateFlags, aptxSampleRates)
+		c.ChannelMode, _ = bitValue(fields[0]&0x0f, aptxChannelModeFlags, aptxChannelModes)
+	case "LDAC":
+		if len(fields) < 2 {
+			return fmt.Errorf("%w: LDAC needs 8 bytes, got %d", ErrInvalidCodecConfig, len(config))
+		}
+		c.SampleRate, _ = bitValue(fields[0], ldacSampleRateFlags, ldacSampleRates)
+		c.ChannelMode, _ = bitValue(fields[1], ldacChannelModeFlags, ldacChannelModes)
+	}
+	return nil
+}
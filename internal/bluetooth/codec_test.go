@@ -0,0 +1,129 @@
+package bluetooth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// The configurations below were captured from MediaTransport1 while
+// streaming to real headphones and speakers.
+func TestDecodeCodecConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		codec    byte
+		config   []byte
+		expected CodecConfig
+		str      string
+	}{
+		{
+			name:   "SBC",
+			codec:  CodecSBC,
+			config: []byte{0x21, 0x15, 0x02, 0x35},
+			expected: CodecConfig{
+				Name: "SBC", SampleRate: 44100, ChannelMode: "joint stereo",
+				BlockLength: 16, Subbands: 8, Allocation: "loudness", MinBitpool: 2, MaxBitpool: 53,
+			},
+			str: "SBC 44.1 kHz joint stereo, bitpool 2-53",
+		},
+		{
+			name:   "AAC",
+			codec:  CodecAAC,
+			config: []byte{0x80, 0x01, 0x04, 0x83, 0xe8, 0x00},
+			expected: CodecConfig{
+				Name: "AAC", SampleRate: 44100, ChannelMode: "stereo",
+				ObjectType: "MPEG-2 AAC LC", Bitrate: 256000, VBR: true,
+			},
+			str: "AAC 44.1 kHz stereo, MPEG-2 AAC LC, 256 kbps, VBR",
+		},
+		{
+			name:   "aptX",
+			codec:  CodecVendor,
+			config: []byte{0x4f, 0x00, 0x00, 0x00, 0x01, 0x00, 0x22},
+			expected: CodecConfig{
+				Name: "aptX", VendorID: 0x4f, VendorCodecID: 0x01, SampleRate: 44100, ChannelMode: "stereo",
+			},
+			str: "aptX 44.1 kHz stereo",
+		},
+		{
+			name:   "aptX HD",
+			codec:  CodecVendor,
+			config: []byte{0xd7, 0x00, 0x00, 0x00, 0x24, 0x00, 0x12, 0x00, 0x00, 0x00, 0x00},
+			expected: CodecConfig{
+				Name: "aptX HD", VendorID: 0xd7, VendorCodecID: 0x24, SampleRate: 48000, ChannelMode: "stereo",
+			},
+			str: "aptX HD 48 kHz stereo",
+		},
+		{
+			name:   "LDAC",
+			codec:  CodecVendor,
+			config: []byte{0x2d, 0x01, 0x00, 0x00, 0xaa, 0x00, 0x10, 0x01},
+			expected: CodecConfig{
+				Name: "LDAC", VendorID: 0x12d, VendorCodecID: 0xaa, SampleRate: 48000, ChannelMode: "stereo",
+			},
+			str: "LDAC 48 kHz stereo",
+		},
+		{
+			name:   "Unknown vendor codec",
+			codec:  CodecVendor,
+			config: []byte{0x34, 0x12, 0x00, 0x00, 0x01, 0x00},
+			expected: CodecConfig{
+				Name: "vendor codec 0x00001234:0x0001", VendorID: 0x1234, VendorCodecID: 0x01,
+			},
+			str: "vendor codec 0x00001234:0x0001",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeCodecConfig(tc.codec, tc.config)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			tc.expected.Codec, tc.expected.Raw = tc.codec, tc.config
+			if got.String() != tc.str {
+				t.Errorf("expected %q, got: %q", tc.str, got.String())
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCodecConfigTooShort(t *testing.T) {
+	if _, err := DecodeCodecConfig(CodecSBC, []byte{0x21, 0x15}); !errors.Is(err, ErrInvalidCodecConfig) {
+		t.Errorf("expected ErrInvalidCodecConfig, got: %v", err)
+	}
+	if _, err := DecodeCodecConfig(CodecVendor, []byte{0x2d, 0x01, 0x00, 0x00, 0xaa, 0x00}); !errors.Is(err, ErrInvalidCodecConfig) {
+		t.Errorf("expected ErrInvalidCodecConfig for LDAC without its fields, got: %v", err)
+	}
+}
+
+// HFP transports use IDs of their own, which must not be decoded as A2DP ones.
+func TestDecodeTransportCodec(t *testing.T) {
+	testCases := []struct {
+		name  string
+		uuid  string
+		codec byte
+		str   string
+	}{
+		{name: "HFP CVSD", uuid: UUIDHandsfree, codec: CodecCVSD, str: "CVSD 8 kHz mono"},
+		{name: "HFP mSBC", uuid: UUIDHandsfreeGateway, codec: CodecMSBC, str: "mSBC 16 kHz mono"},
+		{name: "HSP CVSD", uuid: UUIDHeadset, codec: CodecCVSD, str: "CVSD 8 kHz mono"},
+		{name: "A2DP", uuid: UUIDAudioSink, codec: CodecMPEG12, str: "MPEG-1,2 Audio"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeTransportCodec(tc.uuid, tc.codec, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if got.String() != tc.str {
+				t.Errorf("expected %q, got: %q", tc.str, got.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package bluetooth
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/godbus/dbus/v5"
@@ -12,12 +13,27 @@ import (
 // all of our methods and properties.
 type dbusObject interface {
 	Call(method string, flags dbus.Flags, args ...any) *dbus.Call
+	// CallWithContext is what we use for every call to BlueZ, so that slow
+	// operations (pairing, connecting) can be canceled.
+	CallWithContext(ctx context.Context, method string, flags dbus.Flags, args ...any) *dbus.Call
 }
 
 // dbusConn abstracts the functions that are currently used in the code
 // to interact with D-Bus connections. Also useful for mocking.
 type dbusConn interface {
 	Object(dest string, path dbus.ObjectPath) dbusObject
+	// These are needed to subscribe to the signals emitted by BlueZ, which is
+	// how we find out about devices that appear, change or disappear.
+	AddMatchSignal(options ...dbus.MatchOption) error
+	RemoveMatchSignal(options ...dbus.MatchOption) error
+	Signal(ch chan<- *dbus.Signal)
+	RemoveSignal(ch chan<- *dbus.Signal)
+	// Export is needed to serve our own objects (e.g. the pairing agent) to
+	// BlueZ.
+	Export(v any, path dbus.ObjectPath, iface string) error
+	// Emit sends signals from the objects we export, e.g. the changes of
+	// the values of our GATT characteristics, which is how they notify.
+	Emit(path dbus.ObjectPath, name string, values ...any) error
 	Close() error
 }
 
@@ -49,3 +65,13 @@ func NewSystemBusConnection() (dbusConn, error) {
 	}
 	return &defaultDbusConn{conn}, nil
 }
+
+// NewSessionBusConnection creates a real connection to the session bus of
+// the user, which is where the OBEX daemon of BlueZ lives.
+func NewSessionBusConnection() (dbusConn, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+	return &defaultDbusConn{conn}, nil
+}
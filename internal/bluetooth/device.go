@@ -0,0 +1,192 @@
+package bluetooth
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Device describes a discovered Bluetooth Device. It mirrors the properties
+// exposed by BlueZ through the org.bluez.Device1 interface.
+type Device struct {
+	name        string
+	alias       string
+	address     string
+	addressType string
+	path        string
+	icon        string
+	modalias    string
+	class       uint32
+	appearance  uint16
+
+	// RSSI and TX power are only reported while the device is being
+	// discovered, so we need to know whether we actually have them.
+	rssi       int16
+	hasRSSI    bool
+	txPower    int16
+	hasTxPower bool
+
+	paired           bool
+	bonded           bool
+	trusted          bool
+	blocked          bool
+	connected        bool
+	servicesResolved bool
+	legacyPairing    bool
+
+	uuids            []string
+	manufacturerData map[uint16][]byte
+	serviceData      map[string][]byte
+
+	// The battery is reported through its own interface, which only the
+	// devices that know their charge have.
+	battery       uint8
+	hasBattery    bool
+	batterySource string
+}
+
+// Name returns the name of the Bluetooth device.
+func (d Device) Name() string {
+	return d.name
+}
+
+// Alias returns the name given to the device by the user. If none was set,
+// BlueZ falls back to the device's name, or to its address.
+func (d Device) Alias() string {
+	return d.alias
+}
+
+// Address returns the address of the Bluetooth device.
+func (d Device) Address() string {
+	return d.address
+}
+
+// AddressType returns the type of the device's address, either "public" or
+// "random".
+func (d Device) AddressType() string {
+	return d.addressType
+}
+
+// Path returns the D-Bus path of the Bluetooth device.
+func (d Device) Path() string {
+	return d.path
+}
+
+// Class returns the Bluetooth class of device (BR/EDR only).
+func (d Device) Class() uint32 {
+	return d.class
+}
+
+// Appearance returns the external appearance of the device (LE only).
+func (d Device) Appearance() uint16 {
+	return d.appearance
+}
+
+// Icon returns the freedesktop.org icon name proposed for the device, e.g.
+// "audio-headset".
+func (d Device) Icon() string {
+	return d.icon
+}
+
+// RSSI returns the signal strength of the device in dBm, and whether it's
+// known. It's only available while discovering.
+func (d Device) RSSI() (int16, bool) {
+	return d.rssi, d.hasRSSI
+}
+
+// TxPower returns the advertised transmission power level of the device,
+// and whether it's known. It's only available while discovering.
+func (d Device) TxPower() (int16, bool) {
+	return d.txPower, d.hasTxPower
+}
+
+// Paired tells whether the device is paired.
+func (d Device) Paired() bool {
+	return d.paired
+}
+
+// Bonded tells whether the pairing information of the device is stored.
+func (d Device) Bonded() bool {
+	return d.bonded
+}
+
+// Trusted tells whether the device is trusted.
+func (d Device) Trusted() bool {
+	return d.trusted
+}
+
+// Blocked tells whether incoming connections from the device are rejected.
+func (d Device) Blocked() bool {
+	return d.blocked
+}
+
+// Connected tells whether the device is connected.
+func (d Device) Connected() bool {
+	return d.connected
+}
+
+// ServicesResolved tells whether the services of the device have been
+// resolved.
+func (d Device) ServicesResolved() bool {
+	return d.servicesResolved
+}
+
+// LegacyPairing tells whether the device only supports the pre-2.1 pairing
+// mechanism, which requires a PIN code.
+func (d Device) LegacyPairing() bool {
+	return d.legacyPairing
+}
+
+// UUIDs returns the UUIDs of the services available on the device.
+func (d Device) UUIDs() []string {
+	return slices.Clone(d.uuids)
+}
+
+// Modalias returns the remote device ID information in modalias format.
+func (d Device) Modalias() string {
+	return d.modalias
+}
+
+// ManufacturerData returns the advertised manufacturer specific data, keyed
+// by company identifier.
+func (d Device) ManufacturerData() map[uint16][]byte {
+	return maps.Clone(d.manufacturerData)
+}
+
+// ServiceData returns the advertised service data, keyed by service UUID.
+func (d Device) ServiceData() map[string][]byte {
+	return maps.Clone(d.serviceData)
+}
+
+// Battery returns the charge of the device's battery, in percent. The second
+// value is false if the device doesn't report it.
+func (d Device) Battery() (uint8, bool) {
+	return d.battery, d.hasBattery
+}
+
+// BatterySource tells where the battery level comes from, e.g. "HFP 1.7" for
+// headsets that report it through the hands-free profile. It's empty when
+// BlueZ reads it through the GATT battery service, or doesn't know.
+func (d Device) BatterySource() string {
+	return d.batterySource
+}
+
+// METHODS REQUIRED SO THAT THIS CAN BE USED AS A LIST ITEM
+
+func (d Device) Title() string { return d.name }
+
+func (d Device) Description() string {
+	parts := []string{d.address}
+	if d.paired {
+		parts = append(parts, "paired")
+	}
+	if d.trusted {
+		parts = append(parts, "trusted")
+	}
+	if d.connected {
+		parts = append(parts, "connected")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (d Device) FilterValue() string { return d.name }
@@ -0,0 +1,167 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Transport is the kind of scan run while discovering.
+type Transport string
+
+const (
+	// TransportAuto runs an interleaved scan, or a BR/EDR inquiry for
+	// adapters that don't support LE.
+	TransportAuto  Transport = "auto"
+	TransportBREDR Transport = "bredr"
+	TransportLE    Transport = "le"
+)
+
+// DiscoveryFilter narrows down the devices reported while discovering. The
+// zero value doesn't filter anything.
+type DiscoveryFilter struct {
+	// UUIDs only keeps the devices that advertise at least one of these
+	// services.
+	UUIDs []string
+	// RSSI only keeps the devices whose signal is stronger than this value,
+	// in dBm. Zero means no threshold. It can't be combined with Pathloss.
+	RSSI int16
+	// Pathloss only keeps the devices whose pathloss is lower than this
+	// value, in dB. Zero means no threshold. It can't be combined with RSSI.
+	Pathloss uint16
+	// Transport is the kind of scan to run. Empty means TransportAuto.
+	Transport Transport
+	// DuplicateData tells whether every advertisement of a device should be
+	// reported, or only the ones that change its data. Nil keeps the BlueZ
+	// default, which reports every one of them.
+	DuplicateData *bool
+	// Discoverable only keeps the devices that are discoverable.
+	Discoverable bool
+	// Pattern only keeps the devices whose address or name starts with it.
+	Pattern string
+}
+
+// Validate checks that the filter can be applied.
+func (f DiscoveryFilter) Validate() error {
+	if f.RSSI != 0 && f.Pathloss != 0 {
+		return errors.New("RSSI and pathloss thresholds can't be combined")
+	}
+
+	switch f.Transport {
+	case "", TransportAuto, TransportBREDR, TransportLE:
+	default:
+		return fmt.Errorf("invalid transport %q, expected auto, bredr or le", f.Transport)
+	}
+
+	if f.Transport == TransportBREDR && f.Pathloss != 0 {
+		return errors.New("pathloss can't be used with a BR/EDR scan")
+	}
+
+	return nil
+}
+
+// DiscoverySession is an ongoing discovery, started by
+// Adapter.StartDiscovery. It keeps scanning until Stop is called or the
+// context given to StartDiscovery is done.
+type DiscoverySession struct {
+	devices chan Device
+	done    chan struct{}
+	cancel  context.CancelFunc
+
+	mu    sync.Mutex
+	found map[string]Device
+	// err is the error we got while stopping the scan, if any.
+	err error
+}
+
+// newDiscoverySession starts a session that collects the devices reported by
+// events, which must stop when ctx is done. stopScan is called once events
+// is closed, and cancel must make ctx done.
+func newDiscoverySession(ctx context.Context, cancel context.CancelFunc, events <-chan DeviceEvent, stopScan func() error) *DiscoverySession {
+	s := &DiscoverySession{
+		devices: make(chan Device, 16),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		found:   make(map[string]Device),
+	}
+
+	go s.run(ctx, events, stopScan)
+
+	return s
+}
+
+func (s *DiscoverySession) run(ctx context.Context, events <-chan DeviceEvent, stopScan func() error) {
+	defer close(s.done)
+	defer close(s.devices)
+
+	for ev := range events {
+		s.mu.Lock()
+		_, known := s.found[ev.Device.Address()]
+
+		switch {
+		case ev.Type == DeviceRemoved:
+			delete(s.found, ev.Device.Address())
+			s.mu.Unlock()
+			continue
+		case ev.Type == DeviceAdded, known:
+		default:
+			// Devices that BlueZ already knew about only count as found
+			// once they advertise again, which updates their RSSI.
+			if _, ok := ev.Changed["RSSI"]; !ok {
+				s.mu.Unlock()
+				continue
+			}
+		}
+
+		s.found[ev.Device.Address()] = ev.Device
+		s.mu.Unlock()
+
+		select {
+		case s.devices <- ev.Device:
+		case <-ctx.Done():
+		}
+	}
+
+	err := stopScan()
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Devices streams the devices found by the session. A device is sent again
+// every time it's updated (e.g. its RSSI changes). The channel is closed when
+// the session ends.
+func (s *DiscoverySession) Devices() <-chan Device {
+	return s.devices
+}
+
+// Done is closed when the session ends.
+func (s *DiscoverySession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Found returns the devices found so far.
+func (s *DiscoverySession) Found() []Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices := make([]Device, 0, len(s.found))
+	for _, dev := range s.found {
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+// Stop stops scanning, and returns the devices found by the session. It can
+// be called more than once, and after the session ended on its own.
+func (s *DiscoverySession) Stop() ([]Device, error) {
+	s.cancel()
+	<-s.done
+
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+
+	return s.Found(), err
+}
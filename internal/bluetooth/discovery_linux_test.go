@@ -0,0 +1,117 @@
+package bluetooth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestDiscoveryFilterProperties(t *testing.T) {
+	off := false
+	filter := DiscoveryFilter{
+		UUIDs:         []string{"0000180f-0000-1000-8000-00805f9b34fb"},
+		RSSI:          -70,
+		Transport:     TransportLE,
+		DuplicateData: &off,
+		Pattern:       "Keyboard",
+	}
+
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("expected filter to be valid, got: %v", err)
+	}
+
+	props := discoveryFilterProperties(filter)
+	if len(props) != 5 {
+		t.Errorf("expected 5 filter properties, got: %v", props)
+	}
+	if v, ok := props["RSSI"].Value().(int16); !ok || v != -70 {
+		t.Errorf("expected RSSI to be sent as int16, got: %v", props["RSSI"])
+	}
+	if v, ok := props["DuplicateData"].Value().(bool); !ok || v {
+		t.Errorf("expected DuplicateData to be disabled, got: %v", props["DuplicateData"])
+	}
+	if _, ok := props["Pathloss"]; ok {
+		t.Errorf("expected unset pathloss to be left out")
+	}
+
+	if props := discoveryFilterProperties(DiscoveryFilter{}); len(props) != 0 {
+		t.Errorf("expected empty filter to reset every property, got: %v", props)
+	}
+
+	invalid := []DiscoveryFilter{
+		{RSSI: -70, Pathloss: 20},
+		{Transport: "usb"},
+		{Transport: TransportBREDR, Pathloss: 20},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected filter %+v to be invalid", f)
+		}
+	}
+}
+
+func TestStartDiscovery(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session, err := adapter.StartDiscovery(ctx, DiscoveryFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Device 01 was already known, so it only counts as found once it
+	// advertises again.
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: "/org/bluez/hci0/dev_00_00_00_00_00_01",
+		Body: []any{deviceInterface, map[string]dbus.Variant{"Connected": dbus.MakeVariant(false)}, []string{}},
+	})
+	conn.emit(&dbus.Signal{
+		Name: interfacesAddedSignal,
+		Path: "/",
+		Body: []any{
+			dbus.ObjectPath("/org/bluez/hci0/dev_00_00_00_00_00_02"),
+			map[string]map[string]dbus.Variant{
+				deviceInterface: {"Address": dbus.MakeVariant("00:00:00:00:00:02")},
+			},
+		},
+	})
+
+	select {
+	case dev := <-session.Devices():
+		if dev.Address() != "00:00:00:00:00:02" {
+			t.Fatalf("expected device 02 to be found first, got: %s", dev.Address())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a discovered device")
+	}
+
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: "/org/bluez/hci0/dev_00_00_00_00_00_01",
+		Body: []any{deviceInterface, map[string]dbus.Variant{"RSSI": dbus.MakeVariant(int16(-60))}, []string{}},
+	})
+	<-session.Devices()
+
+	// Canceling must not throw away what was found.
+	cancel()
+	for range session.Devices() {
+	}
+
+	found, err := session.Stop()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("expected 2 devices to be found, got: %v", found)
+	}
+
+	calls := adapter.adapterObj.(*mockBusObject).CallHistory
+	if last := calls[len(calls)-1]; last != adapterInterface+".StopDiscovery" {
+		t.Errorf("expected discovery to be stopped, last call was %s", last)
+	}
+}
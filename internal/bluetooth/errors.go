@@ -0,0 +1,60 @@
+package bluetooth
+
+import "errors"
+
+// Errors reported by BlueZ. They are wrapped in an *Error, which keeps the
+// original message, so they must be checked with errors.Is.
+var (
+	ErrAlreadyExists          = errors.New("already exists")
+	ErrAlreadyConnected       = errors.New("already connected")
+	ErrNotConnected           = errors.New("not connected")
+	ErrAuthenticationFailed   = errors.New("authentication failed")
+	ErrAuthenticationCanceled = errors.New("authentication canceled")
+	ErrAuthenticationRejected = errors.New("authentication rejected")
+	ErrAuthenticationTimeout  = errors.New("authentication timeout")
+	ErrConnectionAttempt      = errors.New("connection attempt failed")
+	ErrInProgress             = errors.New("operation in progress")
+	ErrNotReady               = errors.New("adapter not ready")
+	ErrNotSupported           = errors.New("operation not supported")
+	ErrNotAvailable           = errors.New("operation not available")
+	ErrNotAuthorized          = errors.New("not authorized")
+	ErrNotPermitted           = errors.New("not permitted")
+	ErrInvalidArguments       = errors.New("invalid arguments")
+	ErrDoesNotExist           = errors.New("does not exist")
+	ErrFailed                 = errors.New("operation failed")
+)
+
+// ErrDeviceNotFound is returned when neither we nor BlueZ know about a device
+// with the given address. It has to be discovered first.
+var ErrDeviceNotFound = errors.New("device not found")
+
+// Errors that an AgentHandler can return to refuse a request. Any other error
+// is reported to BlueZ as a rejection. BlueZ also uses them for the requests
+// that we make and are refused or canceled.
+var (
+	ErrRejected = errors.New("request rejected")
+	ErrCanceled = errors.New("request canceled")
+)
+
+// Error is an error reported by BlueZ. It matches one of the sentinel errors
+// of this package through errors.Is.
+type Error struct {
+	// Name is the name of the D-Bus error, e.g. org.bluez.Error.Failed.
+	Name string
+	// Message is the description given by BlueZ, which is often more
+	// specific than the name (e.g. "br-connection-page-timeout").
+	Message string
+	kind    error
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.kind.Error()
+	}
+	return e.kind.Error() + ": " + e.Message
+}
+
+// Unwrap returns the sentinel error matching the D-Bus error name.
+func (e *Error) Unwrap() error {
+	return e.kind
+}
@@ -0,0 +1,80 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// bluezErrors maps the names of the D-Bus errors reported by BlueZ to our
+// sentinel errors.
+var bluezErrors = map[string]error{
+	"org.bluez.Error.AlreadyExists":           ErrAlreadyExists,
+	"org.bluez.Error.AlreadyConnected":        ErrAlreadyConnected,
+	"org.bluez.Error.NotConnected":            ErrNotConnected,
+	"org.bluez.Error.AuthenticationFailed":    ErrAuthenticationFailed,
+	"org.bluez.Error.AuthenticationCanceled":  ErrAuthenticationCanceled,
+	"org.bluez.Error.AuthenticationRejected":  ErrAuthenticationRejected,
+	"org.bluez.Error.AuthenticationTimeout":   ErrAuthenticationTimeout,
+	"org.bluez.Error.ConnectionAttemptFailed": ErrConnectionAttempt,
+	"org.bluez.Error.InProgress":              ErrInProgress,
+	"org.bluez.Error.NotReady":                ErrNotReady,
+	"org.bluez.Error.NotSupported":            ErrNotSupported,
+	"org.bluez.Error.NotAvailable":            ErrNotAvailable,
+	"org.bluez.Error.NotAuthorized":           ErrNotAuthorized,
+	"org.bluez.Error.NotPermitted":            ErrNotPermitted,
+	"org.bluez.Error.InvalidArguments":        ErrInvalidArguments,
+	"org.bluez.Error.DoesNotExist":            ErrDoesNotExist,
+	"org.bluez.Error.Failed":                  ErrFailed,
+	"org.bluez.Error.Rejected":                ErrRejected,
+	"org.bluez.Error.Canceled":                ErrCanceled,
+	"org.bluez.Error.InvalidOffset":           ErrInvalidOffset,
+	"org.bluez.Error.InvalidValueLength":      ErrInvalidValueLength,
+	// obexd has a namespace of its own, with a few of the same errors.
+	"org.bluez.obex.Error.InvalidArguments": ErrInvalidArguments,
+	"org.bluez.obex.Error.InProgress":       ErrInProgress,
+	"org.bluez.obex.Error.NotSupported":     ErrNotSupported,
+	"org.bluez.obex.Error.NotAuthorized":    ErrNotAuthorized,
+	"org.bluez.obex.Error.Forbidden":        ErrNotPermitted,
+	"org.bluez.obex.Error.Failed":           ErrFailed,
+	// D-Bus itself reports this one when the object we call is gone.
+	"org.freedesktop.DBus.Error.UnknownObject": ErrDoesNotExist,
+}
+
+// mapError translates the D-Bus errors reported by BlueZ into an *Error.
+// Errors that we don't know about are returned untouched.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dbusErr dbus.Error
+	var dbusErrPtr *dbus.Error
+	switch {
+	case errors.As(err, &dbusErr):
+	case errors.As(err, &dbusErrPtr):
+		dbusErr = *dbusErrPtr
+	default:
+		return err
+	}
+
+	kind, ok := bluezErrors[dbusErr.Name]
+	if !ok {
+		return err
+	}
+
+	var message string
+	if len(dbusErr.Body) > 0 {
+		message, _ = dbusErr.Body[0].(string)
+	}
+
+	return &Error{Name: dbusErr.Name, Message: message, kind: kind}
+}
+
+// call calls a method on a D-Bus object, translating the errors reported by
+// BlueZ. The call is abandoned as soon as the context is done, in which case
+// the error of the context is returned.
+func call(ctx context.Context, obj dbusObject, method string, args ...any) error {
+	return mapError(obj.CallWithContext(ctx, method, 0, args...).Err)
+}
@@ -0,0 +1,140 @@
+package bluetooth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMapError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+		message  string
+	}{
+		{
+			name:     "Value error",
+			err:      dbus.Error{Name: "org.bluez.Error.AuthenticationFailed", Body: []any{"Authentication Failed"}},
+			expected: ErrAuthenticationFailed,
+			message:  "Authentication Failed",
+		},
+		{
+			name:     "Pointer error",
+			err:      dbus.NewError("org.bluez.Error.InProgress", []any{"In Progress"}),
+			expected: ErrInProgress,
+			message:  "In Progress",
+		},
+		{
+			name:     "Wrapped error",
+			err:      fmt.Errorf("calling Connect: %w", dbus.Error{Name: "org.bluez.Error.Failed", Body: []any{"br-connection-page-timeout"}}),
+			expected: ErrFailed,
+			message:  "br-connection-page-timeout",
+		},
+		{
+			name:     "Error without message",
+			err:      dbus.Error{Name: "org.bluez.Error.DoesNotExist"},
+			expected: ErrDoesNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := mapError(tc.err)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected error to match %v, got: %v", tc.expected, err)
+			}
+
+			var bluezErr *Error
+			if !errors.As(err, &bluezErr) {
+				t.Fatalf("expected an *Error, got: %T", err)
+			}
+			if bluezErr.Message != tc.message {
+				t.Errorf("expected message %q, got: %q", tc.message, bluezErr.Message)
+			}
+		})
+	}
+
+	var unknown dbus.Error
+	if err := mapError(dbus.Error{Name: "org.example.Error.Unknown"}); !errors.As(err, &unknown) {
+		t.Errorf("expected unknown errors to be left untouched, got: %T", err)
+	}
+	if err := mapError(nil); err != nil {
+		t.Errorf("expected nil, got: %v", err)
+	}
+}
+
+func TestPairAlreadyPaired(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	adapter, conn := newTestAdapter(t)
+	conn.objects[devicePath] = &mockBusObject{
+		Errors: map[string]error{
+			deviceInterface + ".Pair": dbus.Error{Name: "org.bluez.Error.AlreadyExists", Body: []any{"Already Exists"}},
+		},
+	}
+	if err := adapter.getDevicesInfo(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if err := adapter.Pair(t.Context(), "00:00:00:00:00:01"); err != nil {
+		t.Errorf("expected pairing an already paired device to succeed, got: %v", err)
+	}
+
+	conn.objects[devicePath].Errors[deviceInterface+".Pair"] = dbus.Error{Name: "org.bluez.Error.AuthenticationCanceled"}
+	if err := adapter.Pair(t.Context(), "00:00:00:00:00:01"); !errors.Is(err, ErrAuthenticationCanceled) {
+		t.Errorf("expected pairing to be canceled, got: %v", err)
+	}
+}
+
+func TestPairEvents(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	adapter, conn := newTestAdapter(t)
+	conn.objects[devicePath] = &mockBusObject{Errors: map[string]error{}}
+	if err := adapter.getDevicesInfo(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var events []OperationEvent
+	adapter.observer = ObserverFunc(func(ev OperationEvent) { events = append(events, ev) })
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected OperationStatus
+	}{
+		{name: "Paired", expected: OperationSucceeded},
+		{name: "Already paired", err: dbus.Error{Name: "org.bluez.Error.AlreadyExists"}, expected: OperationSkipped},
+		{name: "Failed", err: dbus.Error{Name: "org.bluez.Error.AuthenticationFailed"}, expected: OperationFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events = nil
+			conn.objects[devicePath].Errors[deviceInterface+".Pair"] = tc.err
+
+			_ = adapter.Pair(t.Context(), "00:00:00:00:00:01")
+
+			if len(events) != 2 {
+				t.Fatalf("expected 2 events, got: %v", events)
+			}
+			if events[0].Status != OperationStarted {
+				t.Errorf("expected the first event to be %s, got: %s", OperationStarted, events[0].Status)
+			}
+			// Without a retry policy, there's only ever one attempt.
+			if events[0].Attempt != 0 || events[0].MaxAttempts != 0 {
+				t.Errorf("expected no attempt numbers, got: %+v", events[0])
+			}
+			last := events[1]
+			if last.Operation != OpPair || last.Address != "00:00:00:00:00:01" || last.Status != tc.expected {
+				t.Errorf("expected a %s pair event for 00:00:00:00:00:01, got: %+v", tc.expected, last)
+			}
+			if (last.Err != nil) != (tc.expected == OperationFailed) {
+				t.Errorf("expected an error only on failure, got: %v", last.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package bluetooth
+
+// DeviceEventType tells what happened to a device reported by a DeviceEvent.
+type DeviceEventType int
+
+const (
+	// DeviceAdded is sent when a device shows up for the first time, either
+	// because it was already known when the watch started or because it was
+	// just discovered.
+	DeviceAdded DeviceEventType = iota
+	// DeviceUpdated is sent when one or more properties of a known device
+	// change (name, RSSI, connection state, etc).
+	DeviceUpdated
+	// DeviceRemoved is sent when the device is gone from the adapter.
+	DeviceRemoved
+)
+
+// String returns a human readable representation of the event type.
+func (t DeviceEventType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceUpdated:
+		return "updated"
+	case DeviceRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// DeviceEvent describes a change on one of the devices of the adapter.
+type DeviceEvent struct {
+	Type DeviceEventType
+	// Device is the state of the device after the change was applied. For
+	// removals, this is the last state we knew about.
+	Device Device
+	// Changed holds the raw values of the properties that were added or
+	// modified, keyed by their BlueZ name (e.g. "RSSI", "Connected").
+	// Properties of interfaces other than Device1 are prefixed by the
+	// interface, e.g. "Battery1.Percentage". Properties that were
+	// invalidated are present with a nil value, and "Battery1.*" means that
+	// the whole interface is gone.
+	Changed map[string]any
+}
+
+// Operation names an operation run on a device, as reported by an
+// OperationEvent.
+type Operation string
+
+const (
+	OpPair       Operation = "pair"
+	OpTrust      Operation = "trust"
+	OpConnect    Operation = "connect"
+	OpDisconnect Operation = "disconnect"
+	OpRemove     Operation = "remove"
+	// OpConnectProfile and OpDisconnectProfile name the profile in the
+	// message of their events.
+	OpConnectProfile    Operation = "connect-profile"
+	OpDisconnectProfile Operation = "disconnect-profile"
+)
+
+// OperationStatus tells how far an operation went.
+type OperationStatus int
+
+const (
+	// OperationStarted is sent right before we ask BlueZ to run the
+	// operation.
+	OperationStarted OperationStatus = iota
+	// OperationSucceeded is sent once BlueZ reports that it's done.
+	OperationSucceeded
+	// OperationRetrying is sent when an attempt failed and another one is
+	// going to be made after a while. Err holds the error of the attempt.
+	OperationRetrying
+	// OperationSkipped is sent when there was nothing to do, e.g. pairing
+	// with a device that is already paired. It's not an error.
+	OperationSkipped
+	// OperationFailed is sent along with the error returned to the caller.
+	OperationFailed
+)
+
+// String returns a human readable representation of the status.
+func (s OperationStatus) String() string {
+	switch s {
+	case OperationStarted:
+		return "started"
+	case OperationSucceeded:
+		return "succeeded"
+	case OperationRetrying:
+		return "retrying"
+	case OperationSkipped:
+		return "skipped"
+	case OperationFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// OperationEvent reports the progress of an operation on a device. These
+// replace the messages that we used to print, so apps can show them however
+// they want (or not at all).
+type OperationEvent struct {
+	Operation Operation
+	// Address is the address of the device the operation runs on.
+	Address string
+	Status  OperationStatus
+	// Message is a short human readable description of the event, e.g.
+	// "already paired".
+	Message string
+	// Err is only set when the operation, or one of its attempts, failed.
+	Err error
+	// Attempt is the number of the attempt that the event refers to, out of
+	// MaxAttempts. Both are zero for operations that are never retried,
+	// including Connect and Pair when the retry policy allows one attempt.
+	Attempt     int
+	MaxAttempts int
+}
+
+// Observer gets notified about the operations run by an adapter.
+//
+// Notify is called from the goroutine that runs the operation, right before
+// the operation moves on, so it must not block.
+type Observer interface {
+	Notify(OperationEvent)
+}
+
+// ObserverFunc lets plain functions be used as an Observer.
+type ObserverFunc func(OperationEvent)
+
+// Notify calls f(ev).
+func (f ObserverFunc) Notify(ev OperationEvent) {
+	f(ev)
+}
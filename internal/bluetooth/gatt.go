@@ -0,0 +1,231 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrServicesNotResolved is returned when the GATT services of a device are
+// asked for before BlueZ is done discovering them, which only happens once
+// the device is connected.
+var ErrServicesNotResolved = errors.New("services not resolved yet")
+
+// GATT browses the GATT database of connected LE devices, and reads and
+// writes its attributes. Services are found by device address, while
+// characteristics and descriptors, whose UUIDs may repeat within a device,
+// are identified by their object path.
+type GATT interface {
+	// Services returns the services of the device along with their
+	// characteristics and descriptors, sorted by path, which follows the
+	// order of their handles.
+	Services(ctx context.Context, addr string) ([]GattService, error)
+	// ReadCharacteristic reads the value of a characteristic from the
+	// device.
+	ReadCharacteristic(ctx context.Context, path string) ([]byte, error)
+	// WriteCharacteristic writes the value of a characteristic. Without a
+	// response, the device doesn't acknowledge the write ("write command"),
+	// which only works if the characteristic has the write-without-response
+	// flag.
+	WriteCharacteristic(ctx context.Context, path string, value []byte, withResponse bool) error
+	// ReadDescriptor reads the value of a descriptor from the device.
+	ReadDescriptor(ctx context.Context, path string) ([]byte, error)
+	// Notify subscribes to the notifications (or indications) of a
+	// characteristic and streams every new value until the context is done,
+	// when it unsubscribes and closes the channel.
+	Notify(ctx context.Context, path string) (<-chan GattNotification, error)
+	// OpenWriter returns a writer that streams data to a characteristic
+	// with writes without response. It must be closed when done, to
+	// release the characteristic.
+	OpenWriter(ctx context.Context, path string) (*GattWriter, error)
+}
+
+// GattNotification is a new value of a characteristic, pushed by the device.
+type GattNotification struct {
+	// Path is the object path of the characteristic.
+	Path  string
+	Value []byte
+	// Time is when the value got to us.
+	Time time.Time
+}
+
+// GattService is a service of the GATT database of a device.
+type GattService struct {
+	Path string
+	UUID string
+	// Primary is false for services that are only included by others.
+	Primary         bool
+	Characteristics []GattCharacteristic
+}
+
+// Name returns the assigned-number name of the service, or its UUID.
+func (s GattService) Name() string {
+	return GattName(s.UUID)
+}
+
+// GattCharacteristic is a characteristic of a GATT service.
+type GattCharacteristic struct {
+	Path string
+	UUID string
+	// Flags are the properties and permissions of the characteristic, as
+	// named by BlueZ, e.g. "read", "write-without-response" or "notify".
+	Flags []string
+	// Value is the last value BlueZ has cached, from reads or
+	// notifications. It's empty until one of those happens.
+	Value     []byte
+	Notifying bool
+	// NotifyAcquirable and WriteAcquirable tell whether notifications and
+	// writes can go through a socket (see Notify and OpenWriter), which is
+	// much faster than D-Bus.
+	NotifyAcquirable bool
+	WriteAcquirable  bool
+	// MTU is the ATT MTU of the link, if BlueZ reports it.
+	MTU         int
+	Descriptors []GattDescriptor
+}
+
+// Name returns the assigned-number name of the characteristic, or its UUID.
+func (c GattCharacteristic) Name() string {
+	return GattName(c.UUID)
+}
+
+// HasFlag tells whether the characteristic has the given flag.
+func (c GattCharacteristic) HasFlag(flag string) bool {
+	return slices.Contains(c.Flags, flag)
+}
+
+// CanRead tells whether the characteristic can be read.
+func (c GattCharacteristic) CanRead() bool {
+	return c.HasFlag("read") || c.HasFlag("encrypt-read") || c.HasFlag("encrypt-authenticated-read") || c.HasFlag("secure-read")
+}
+
+// CanWrite tells whether the characteristic can be written, with or without
+// response.
+func (c GattCharacteristic) CanWrite() bool {
+	return c.HasFlag("write") || c.HasFlag("write-without-response") || c.HasFlag("encrypt-write") ||
+		c.HasFlag("encrypt-authenticated-write") || c.HasFlag("secure-write") || c.HasFlag("authenticated-signed-writes")
+}
+
+// GattDescriptor is a descriptor of a GATT characteristic.
+type GattDescriptor struct {
+	Path  string
+	UUID  string
+	Flags []string
+	Value []byte
+}
+
+// Name returns the assigned-number name of the descriptor, or its UUID.
+func (d GattDescriptor) Name() string {
+	return GattName(d.UUID)
+}
+
+// gattNames are the names of the 16-bit UUIDs assigned by the Bluetooth SIG
+// that we are likely to come across: the most common services,
+// characteristics and descriptors.
+var gattNames = map[uint16]string{
+	// Services.
+	0x1800: "Generic Access",
+	0x1801: "Generic Attribute",
+	0x1802: "Immediate Alert",
+	0x1803: "Link Loss",
+	0x1804: "Tx Power",
+	0x1805: "Current Time",
+	0x1809: "Health Thermometer",
+	0x180a: "Device Information",
+	0x180d: "Heart Rate",
+	0x180f: "Battery",
+	0x1810: "Blood Pressure",
+	0x1812: "Human Interface Device",
+	0x1816: "Cycling Speed and Cadence",
+	0x1818: "Cycling Power",
+	0x1819: "Location and Navigation",
+	0x181a: "Environmental Sensing",
+	0x181c: "User Data",
+	0x181d: "Weight Scale",
+	0x1826: "Fitness Machine",
+	0x1827: "Mesh Provisioning",
+	0x1828: "Mesh Proxy",
+	0xfe59: "Nordic DFU",
+
+	// Characteristics.
+	0x2a00: "Device Name",
+	0x2a01: "Appearance",
+	0x2a02: "Peripheral Privacy Flag",
+	0x2a04: "Peripheral Preferred Connection Parameters",
+	0x2a05: "Service Changed",
+	0x2a06: "Alert Level",
+	0x2a07: "Tx Power Level",
+	0x2a19: "Battery Level",
+	0x2a1c: "Temperature Measurement",
+	0x2a23: "System ID",
+	0x2a24: "Model Number String",
+	0x2a25: "Serial Number String",
+	0x2a26: "Firmware Revision String",
+	0x2a27: "Hardware Revision String",
+	0x2a28: "Software Revision String",
+	0x2a29: "Manufacturer Name String",
+	0x2a2a: "IEEE 11073-20601 Regulatory Certification Data List",
+	0x2a2b: "Current Time",
+	0x2a37: "Heart Rate Measurement",
+	0x2a38: "Body Sensor Location",
+	0x2a39: "Heart Rate Control Point",
+	0x2a4a: "HID Information",
+	0x2a4b: "Report Map",
+	0x2a4c: "HID Control Point",
+	0x2a4d: "Report",
+	0x2a4e: "Protocol Mode",
+	0x2a50: "PnP ID",
+	0x2a5b: "CSC Measurement",
+	0x2a5c: "CSC Feature",
+	0x2a63: "Cycling Power Measurement",
+	0x2a6e: "Temperature",
+	0x2a6f: "Humidity",
+	0x2a6d: "Pressure",
+	0x2a9d: "Weight Measurement",
+	0x2aa6: "Central Address Resolution",
+	0x2ac9: "Resolvable Private Address Only",
+	0x2b29: "Client Supported Features",
+	0x2b2a: "Database Hash",
+	0x2b3a: "Server Supported Features",
+
+	// Descriptors.
+	0x2900: "Characteristic Extended Properties",
+	0x2901: "Characteristic User Description",
+	0x2902: "Client Characteristic Configuration",
+	0x2903: "Server Characteristic Configuration",
+	0x2904: "Characteristic Presentation Format",
+	0x2905: "Characteristic Aggregate Format",
+	0x2906: "Valid Range",
+	0x2908: "Report Reference",
+}
+
+// ShortUUID returns the 16-bit form of a UUID built on the Bluetooth base
+// UUID, e.g. 0x180f for "0000180f-0000-1000-8000-00805f9b34fb". It returns
+// false for any other UUID, like the 128-bit ones of custom services.
+func ShortUUID(uuid string) (uint16, bool) {
+	uuid = NormalizeUUID(uuid)
+	if len(uuid) != 36 || !strings.HasPrefix(uuid, "0000") || !strings.HasSuffix(uuid, bluetoothBaseUUIDTail) {
+		return 0, false
+	}
+
+	short, err := strconv.ParseUint(uuid[4:8], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(short), true
+}
+
+// GattName returns the assigned-number name of a GATT service,
+// characteristic or descriptor UUID, or the UUID itself when it's not one we
+// know about.
+func GattName(uuid string) string {
+	if short, ok := ShortUUID(uuid); ok {
+		if name, ok := gattNames[short]; ok {
+			return name
+		}
+	}
+	return uuid
+}
@@ -0,0 +1,83 @@
+package bluetooth
+
+import (
+	"context"
+	"io"
+)
+
+// defaultATTMTU is the ATT MTU every LE link starts with, used when we
+// don't know the negotiated one.
+const defaultATTMTU = 23
+
+// attWriteHeader is the size of the header of a write, which the value
+// shares the MTU with.
+const attWriteHeader = 3
+
+// GattWriter streams data to a characteristic with writes without response,
+// split to fit the MTU. It writes to the socket BlueZ hands over through
+// AcquireWrite when the characteristic allows it, which is much faster than
+// a D-Bus call per write, and falls back to WriteValue otherwise.
+type GattWriter struct {
+	// conn is the acquired socket, nil when falling back to D-Bus.
+	conn io.WriteCloser
+	// write sends one value through D-Bus, for the fallback.
+	write func(value []byte) error
+	mtu   int
+}
+
+// Acquired tells whether the writer goes through the acquired socket.
+func (w *GattWriter) Acquired() bool {
+	return w.conn != nil
+}
+
+// MTU returns the ATT MTU of the link.
+func (w *GattWriter) MTU() int {
+	return w.mtu
+}
+
+// MaxWriteSize returns the largest value a single write can carry.
+func (w *GattWriter) MaxWriteSize() int {
+	return max(w.mtu-attWriteHeader, 1)
+}
+
+// Write sends p in as many writes as needed to fit the MTU. Each one is a
+// separate value for the device, so protocols that care about framing should
+// keep their messages within MaxWriteSize.
+func (w *GattWriter) Write(p []byte) (int, error) {
+	size := w.MaxWriteSize()
+
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(len(p), size)]
+
+		var err error
+		if w.conn != nil {
+			_, err = w.conn.Write(chunk)
+		} else {
+			err = w.write(chunk)
+		}
+		if err != nil {
+			return written, err
+		}
+
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Close releases the characteristic, so others can acquire it.
+func (w *GattWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// newFallbackWriter returns a writer that goes through WriteValue.
+func newFallbackWriter(ctx context.Context, mtu int, write func(ctx context.Context, value []byte) error) *GattWriter {
+	if mtu <= 0 {
+		mtu = defaultATTMTU
+	}
+	return &GattWriter{mtu: mtu, write: func(value []byte) error { return write(ctx, value) }}
+}
@@ -0,0 +1,106 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// acquire calls AcquireNotify or AcquireWrite on the characteristic, which
+// hand over a socket to read the notifications from, or write the values to,
+// along with the ATT MTU of the link. Closing the socket releases the
+// characteristic.
+func (b *linuxAdapter) acquire(ctx context.Context, path, method string) (*os.File, int, error) {
+	var fd dbus.UnixFD
+	var mtu uint16
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(path))
+	err := mapError(obj.CallWithContext(ctx, gattCharacteristicInterface+"."+method, 0, map[string]dbus.Variant{}).Store(&fd, &mtu))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Non-blocking sockets go through the poller of the runtime, which is
+	// what makes closing the file unblock a pending read.
+	if err := syscall.SetNonblock(int(fd), true); err != nil {
+		_ = syscall.Close(int(fd))
+		return nil, 0, fmt.Errorf("failed to set up acquired socket: %w", err)
+	}
+	return os.NewFile(uintptr(fd), path), int(mtu), nil
+}
+
+// notifyFromConn streams the notifications read from an acquired socket. The
+// socket keeps the boundaries of the packets, so each read is a value. It's
+// closed once the context is done, or when BlueZ hangs up (e.g. because the
+// device disconnected), which ends the stream.
+func notifyFromConn(ctx context.Context, path string, conn io.ReadCloser, mtu int, logger *slog.Logger) <-chan GattNotification {
+	notifications := make(chan GattNotification, 32)
+
+	go func() {
+		defer close(notifications)
+		// Closing the socket is what stops the read below when the context
+		// is done. If the read stops first, we close it ourselves.
+		stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+		defer func() {
+			if stop() {
+				_ = conn.Close()
+			}
+		}()
+
+		buf := make([]byte, max(mtu, defaultATTMTU))
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+					logger.Warn("failed to read notification", "path", path, "error", err)
+				}
+				return
+			}
+
+			select {
+			case notifications <- GattNotification{Path: path, Value: bytes.Clone(buf[:n]), Time: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notifications
+}
+
+// OpenWriter returns a writer for the characteristic. It writes to the socket
+// of AcquireWrite if the characteristic allows it, and falls back to
+// WriteValue otherwise, in which case the context bounds every write.
+func (b *linuxAdapter) OpenWriter(ctx context.Context, path string) (*GattWriter, error) {
+	conn, mtu, err := b.acquire(ctx, path, "AcquireWrite")
+	if err == nil {
+		return &GattWriter{conn: conn, mtu: mtu}, nil
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to open writer for characteristic %s: %w", path, ctx.Err())
+	}
+
+	b.logger.Debug("falling back to WriteValue", "path", path, "error", err)
+	return newFallbackWriter(ctx, b.characteristicMTU(ctx, path), func(ctx context.Context, value []byte) error {
+		return b.WriteCharacteristic(ctx, path, value, false)
+	}), nil
+}
+
+// characteristicMTU returns the MTU property of the characteristic, or 0 if
+// BlueZ doesn't have it (it's only there since 5.62).
+func (b *linuxAdapter) characteristicMTU(ctx context.Context, path string) int {
+	var v dbus.Variant
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(path))
+	if err := obj.CallWithContext(ctx, propertiesInterface+".Get", 0, gattCharacteristicInterface, "MTU").Store(&v); err != nil {
+		return 0
+	}
+	mtu, _ := v.Value().(uint16)
+	return int(mtu)
+}
@@ -0,0 +1,292 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const testCharPath = "/org/bluez/hci0/dev_00_00_00_00_00_01/service000c/char000d"
+
+// newSocketCharacteristic fakes a characteristic that can be acquired
+// through method: it hands over one end of a socketpair, like BlueZ does,
+// and the other end is returned to play the device.
+func newSocketCharacteristic(tb testing.TB, method string, mtu uint16) (*linuxAdapter, *mockDbusConn, *os.File) {
+	tb.Helper()
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		tb.Fatalf("failed to create socketpair: %v", err)
+	}
+	device := os.NewFile(uintptr(fds[1]), "device")
+	tb.Cleanup(func() { _ = device.Close() })
+
+	adapter, conn := newTestAdapter(tb)
+	conn.objects[testCharPath] = &mockBusObject{Replies: map[string][]any{
+		gattCharacteristicInterface + "." + method: {dbus.UnixFD(fds[0]), mtu},
+	}}
+	return adapter, conn, device
+}
+
+func TestNotifyAcquired(t *testing.T) {
+	adapter, _, device := newSocketCharacteristic(t, "AcquireNotify", 23)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	notifications, err := adapter.Notify(ctx, testCharPath)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	values := [][]byte{{0x01}, {0x02, 0x03}, bytes.Repeat([]byte{0xff}, 20)}
+	for _, v := range values {
+		if _, err := device.Write(v); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	for _, want := range values {
+		select {
+		case n := <-notifications:
+			if !bytes.Equal(n.Value, want) {
+				t.Errorf("expected value % x, got: % x", want, n.Value)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a notification")
+		}
+	}
+
+	// Canceling closes our end, which the device sees as a hang up.
+	cancel()
+	for range notifications {
+	}
+	if n, err := device.Read(make([]byte, 1)); n != 0 || err == nil {
+		t.Errorf("expected the socket to be closed, got: %d bytes, %v", n, err)
+	}
+}
+
+func TestGattWriterAcquired(t *testing.T) {
+	adapter, _, device := newSocketCharacteristic(t, "AcquireWrite", 23)
+
+	w, err := adapter.OpenWriter(t.Context(), testCharPath)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer w.Close()
+	if !w.Acquired() || w.MaxWriteSize() != 20 {
+		t.Fatalf("expected an acquired writer of 20 bytes, got: acquired %t, %d bytes", w.Acquired(), w.MaxWriteSize())
+	}
+
+	data := bytes.Repeat([]byte{0xaa}, 50)
+	if n, err := w.Write(data); n != len(data) || err != nil {
+		t.Fatalf("expected 50 bytes written, got: %d, %v", n, err)
+	}
+
+	// Each write is a packet of its own, no bigger than the MTU allows.
+	var sizes []int
+	buf := make([]byte, 64)
+	for range 3 {
+		n, err := device.Read(buf)
+		if err != nil {
+			t.Fatalf("failed to read write: %v", err)
+		}
+		sizes = append(sizes, n)
+	}
+	if !slices.Equal(sizes, []int{20, 20, 10}) {
+		t.Errorf("expected writes of 20, 20 and 10 bytes, got: %v", sizes)
+	}
+}
+
+func TestGattWriterFallback(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+	char := &mockBusObject{
+		Errors: map[string]error{
+			gattCharacteristicInterface + ".AcquireWrite": dbus.Error{Name: "org.bluez.Error.NotSupported"},
+		},
+		Replies: map[string][]any{
+			propertiesInterface + ".Get": {dbus.MakeVariant(uint16(27))},
+		},
+	}
+	conn.objects[testCharPath] = char
+
+	w, err := adapter.OpenWriter(t.Context(), testCharPath)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if w.Acquired() || w.MaxWriteSize() != 24 {
+		t.Fatalf("expected a D-Bus writer of 24 bytes, got: acquired %t, %d bytes", w.Acquired(), w.MaxWriteSize())
+	}
+
+	if _, err := w.Write(make([]byte, 30)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	writes := 0
+	for _, method := range char.CallHistory {
+		if method == gattCharacteristicInterface+".WriteValue" {
+			writes++
+		}
+	}
+	if writes != 2 {
+		t.Errorf("expected 2 WriteValue calls, got: %v", char.CallHistory)
+	}
+}
+
+// BenchmarkNotify compares notifications read from an acquired socket with
+// the ones that come as PropertiesChanged signals. The signals skip the bus
+// here, so the real gap is bigger.
+func BenchmarkNotify(b *testing.B) {
+	value := bytes.Repeat([]byte{0x42}, 20)
+
+	b.Run("acquired", func(b *testing.B) {
+		adapter, _, device := newSocketCharacteristic(b, "AcquireNotify", 23)
+		notifications, err := adapter.Notify(b.Context(), testCharPath)
+		if err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+
+		go func() {
+			for range b.N {
+				if _, err := device.Write(value); err != nil {
+					return
+				}
+			}
+		}()
+
+		b.ResetTimer()
+		for range b.N {
+			<-notifications
+		}
+	})
+
+	b.Run("signals", func(b *testing.B) {
+		adapter, conn := newTestAdapter(b)
+		conn.objects[testCharPath] = &mockBusObject{Errors: map[string]error{
+			gattCharacteristicInterface + ".AcquireNotify": dbus.Error{Name: "org.bluez.Error.NotSupported"},
+		}}
+		notifications, err := adapter.Notify(b.Context(), testCharPath)
+		if err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+
+		sig := &dbus.Signal{
+			Name: propertiesChangedSignal,
+			Path: testCharPath,
+			Body: []any{gattCharacteristicInterface, map[string]dbus.Variant{"Value": dbus.MakeVariant(value)}, []string{}},
+		}
+		go func() {
+			for range b.N {
+				conn.emit(sig)
+			}
+		}()
+
+		b.ResetTimer()
+		for range b.N {
+			<-notifications
+		}
+	})
+}
+
+// withRoundTrip makes every WriteValue call on obj wait for an answer
+// through a socketpair, which is the least a call to BlueZ costs, so the
+// D-Bus path can be compared with the acquired one. A real bus adds the
+// encoding and the hop through the daemon on top.
+func withRoundTrip(tb testing.TB, obj *mockBusObject) *mockBusObject {
+	tb.Helper()
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		tb.Fatalf("failed to create socketpair: %v", err)
+	}
+	conn, bluez := os.NewFile(uintptr(fds[0]), "conn"), os.NewFile(uintptr(fds[1]), "bluez")
+	tb.Cleanup(func() {
+		_ = conn.Close()
+		_ = bluez.Close()
+	})
+
+	// BlueZ acknowledges every call.
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			if _, err := bluez.Read(buf); err != nil {
+				return
+			}
+			if _, err := bluez.Write([]byte{0}); err != nil {
+				return
+			}
+		}
+	}()
+
+	obj.OnCall = func(method string, args ...any) *dbus.Call {
+		if method != gattCharacteristicInterface+".WriteValue" {
+			return nil
+		}
+		if _, err := conn.Write(args[0].([]byte)); err != nil {
+			return &dbus.Call{Err: err}
+		}
+		if _, err := conn.Read(make([]byte, 1)); err != nil {
+			return &dbus.Call{Err: err}
+		}
+		return &dbus.Call{}
+	}
+	return obj
+}
+
+// BenchmarkGattWriter compares writes to an acquired socket, which don't
+// wait for anything, with WriteValue calls, which wait for BlueZ to answer.
+func BenchmarkGattWriter(b *testing.B) {
+	data := bytes.Repeat([]byte{0x42}, 244)
+
+	b.Run("acquired", func(b *testing.B) {
+		adapter, _, device := newSocketCharacteristic(b, "AcquireWrite", 247)
+		w, err := adapter.OpenWriter(b.Context(), testCharPath)
+		if err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+		defer w.Close()
+
+		go func() {
+			buf := make([]byte, 512)
+			for {
+				if _, err := device.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+
+		b.SetBytes(int64(len(data)))
+		b.ResetTimer()
+		for range b.N {
+			if _, err := w.Write(data); err != nil {
+				b.Fatalf("failed to write: %v", err)
+			}
+		}
+	})
+
+	b.Run("dbus", func(b *testing.B) {
+		adapter, conn := newTestAdapter(b)
+		conn.objects[testCharPath] = withRoundTrip(b, &mockBusObject{
+			Errors: map[string]error{
+				gattCharacteristicInterface + ".AcquireWrite": dbus.Error{Name: "org.bluez.Error.NotSupported"},
+			},
+			Replies: map[string][]any{
+				propertiesInterface + ".Get": {dbus.MakeVariant(uint16(247))},
+			},
+		})
+		w, err := adapter.OpenWriter(b.Context(), testCharPath)
+		if err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+
+		b.SetBytes(int64(len(data)))
+		b.ResetTimer()
+		for range b.N {
+			if _, err := w.Write(data); err != nil {
+				b.Fatalf("failed to write: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	gattServiceInterface        = "org.bluez.GattService1"
+	gattCharacteristicInterface = "org.bluez.GattCharacteristic1"
+	gattDescriptorInterface     = "org.bluez.GattDescriptor1"
+)
+
+// gattServicesFromObjects builds the GATT database of the device at
+// devicePath out of the objects managed by BlueZ. Services live right below
+// the device, characteristics below their service and descriptors below
+// their characteristic, and each of them points to its parent through a
+// property, which is what we follow.
+func gattServicesFromObjects(devicePath string, objs map[dbus.ObjectPath]map[string]map[string]dbus.Variant) []GattService {
+	paths := make([]dbus.ObjectPath, 0, len(objs))
+	for path := range objs {
+		if strings.HasPrefix(string(path), devicePath+"/") {
+			paths = append(paths, path)
+		}
+	}
+	// Paths carry the handles in hex with a fixed width (e.g. service000a/
+	// char000b), so sorting them keeps the order of the database.
+	slices.Sort(paths)
+
+	var services []GattService
+	serviceIndex := make(map[dbus.ObjectPath]int)
+	for _, path := range paths {
+		props, ok := objs[path][gattServiceInterface]
+		if !ok {
+			continue
+		}
+		p := properties(props)
+
+		s := GattService{Path: string(path)}
+		s.UUID, _ = p.stringValue("UUID")
+		s.Primary, _ = p.boolValue("Primary")
+		serviceIndex[path] = len(services)
+		services = append(services, s)
+	}
+
+	// Characteristics are indexed by their service and their position in
+	// it, so descriptors can find them.
+	type charIndex struct{ service, char int }
+	charIndexes := make(map[dbus.ObjectPath]charIndex)
+	for _, path := range paths {
+		props, ok := objs[path][gattCharacteristicInterface]
+		if !ok {
+			continue
+		}
+		p := properties(props)
+
+		service, _ := p.objectPathValue("Service")
+		si, ok := serviceIndex[service]
+		if !ok {
+			continue
+		}
+
+		c := GattCharacteristic{Path: string(path)}
+		c.UUID, _ = p.stringValue("UUID")
+		c.Flags, _ = p.stringsValue("Flags")
+		c.Value, _ = p.bytesValue("Value")
+		c.Notifying, _ = p.boolValue("Notifying")
+		// BlueZ only has NotifyAcquired and WriteAcquired on the
+		// characteristics that can be acquired.
+		_, c.NotifyAcquirable = props["NotifyAcquired"]
+		_, c.WriteAcquirable = props["WriteAcquired"]
+		if v, ok := p.uint16Value("MTU"); ok {
+			c.MTU = int(v)
+		}
+		charIndexes[path] = charIndex{service: si, char: len(services[si].Characteristics)}
+		services[si].Characteristics = append(services[si].Characteristics, c)
+	}
+
+	for _, path := range paths {
+		props, ok := objs[path][gattDescriptorInterface]
+		if !ok {
+			continue
+		}
+		p := properties(props)
+
+		char, _ := p.objectPathValue("Characteristic")
+		ci, ok := charIndexes[char]
+		if !ok {
+			continue
+		}
+
+		d := GattDescriptor{Path: string(path)}
+		d.UUID, _ = p.stringValue("UUID")
+		d.Flags, _ = p.stringsValue("Flags")
+		d.Value, _ = p.bytesValue("Value")
+		c := &services[ci.service].Characteristics[ci.char]
+		c.Descriptors = append(c.Descriptors, d)
+	}
+
+	return services
+}
+
+// Services returns the GATT database of the device. It needs the device to be
+// connected and its services resolved.
+func (b *linuxAdapter) Services(ctx context.Context, addr string) ([]GattService, error) {
+	dev, err := b.resolveDevice(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services of device at addr %s: %w", addr, err)
+	}
+	// The cache may not have caught up with the device getting connected
+	// yet, so we ask again before giving up.
+	if !dev.ServicesResolved() {
+		if err := b.getDevicesInfo(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get services of device at addr %s: %w", addr, err)
+		}
+		dev, _ = b.cachedDevice(dev.Address())
+	}
+	if !dev.ServicesResolved() {
+		return nil, fmt.Errorf("failed to get services of device at addr %s: %w", addr, ErrServicesNotResolved)
+	}
+
+	objs, err := managedObjects(ctx, b.conn, b.destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services of device at addr %s: %w", addr, err)
+	}
+
+	return gattServicesFromObjects(dev.Path(), objs), nil
+}
+
+// ReadCharacteristic reads the value of the characteristic at path.
+func (b *linuxAdapter) ReadCharacteristic(ctx context.Context, path string) ([]byte, error) {
+	value, err := b.readValue(ctx, gattCharacteristicInterface, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read characteristic %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// WriteCharacteristic writes the value of the characteristic at path.
+func (b *linuxAdapter) WriteCharacteristic(ctx context.Context, path string, value []byte, withResponse bool) error {
+	writeType := "request"
+	if !withResponse {
+		writeType = "command"
+	}
+	options := map[string]dbus.Variant{"type": dbus.MakeVariant(writeType)}
+
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(path))
+	if err := call(ctx, obj, gattCharacteristicInterface+".WriteValue", value, options); err != nil {
+		return fmt.Errorf("failed to write characteristic %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadDescriptor reads the value of the descriptor at path.
+func (b *linuxAdapter) ReadDescriptor(ctx context.Context, path string) ([]byte, error) {
+	value, err := b.readValue(ctx, gattDescriptorInterface, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// readValue calls ReadValue on a characteristic or descriptor, which reads
+// it from the device (as opposed to the Value property, which is a cache).
+func (b *linuxAdapter) readValue(ctx context.Context, iface, path string) ([]byte, error) {
+	var value []byte
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(path))
+	err := mapError(obj.CallWithContext(ctx, iface+".ReadValue", 0, map[string]dbus.Variant{}).Store(&value))
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Notify reads the notifications of the characteristic from the socket
+// BlueZ hands over through AcquireNotify. Characteristics that don't allow it
+// (e.g. the ones that indicate, or that someone else already follows) fall
+// back to StartNotify and the PropertiesChanged signals, which are much
+// slower.
+func (b *linuxAdapter) Notify(ctx context.Context, path string) (<-chan GattNotification, error) {
+	conn, mtu, err := b.acquire(ctx, path, "AcquireNotify")
+	if err == nil {
+		return notifyFromConn(ctx, path, conn, mtu, b.logger), nil
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to subscribe to characteristic %s: %w", path, ctx.Err())
+	}
+
+	b.logger.Debug("falling back to StartNotify", "path", path, "error", err)
+	return b.notifySignals(ctx, path)
+}
+
+// notifySignals calls StartNotify on the characteristic and follows the
+// changes of its Value property, which BlueZ updates with each
+// notification. StopNotify is called once the context is done.
+func (b *linuxAdapter) notifySignals(ctx context.Context, path string) (<-chan GattNotification, error) {
+	// We subscribe first so that the first notification, which may come
+	// right after StartNotify, can't slip through.
+	signals, unsubscribe, err := b.subscribe([][]dbus.MatchOption{{
+		dbus.WithMatchSender(b.destination),
+		dbus.WithMatchInterface(propertiesInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchObjectPath(dbus.ObjectPath(path)),
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to characteristic %s: %w", path, err)
+	}
+
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(path))
+	if err := call(ctx, obj, gattCharacteristicInterface+".StartNotify"); err != nil {
+		unsubscribe()
+		return nil, fmt.Errorf("failed to subscribe to characteristic %s: %w", path, err)
+	}
+
+	notifications := make(chan GattNotification, 32)
+	go func() {
+		defer close(notifications)
+		defer unsubscribe()
+		defer func() {
+			// The context is done by now, but BlueZ must still be told.
+			if err := call(context.WithoutCancel(ctx), obj, gattCharacteristicInterface+".StopNotify"); err != nil {
+				b.logger.Warn("failed to unsubscribe from characteristic", "path", path, "error", err)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				n, ok := notificationFromSignal(path, sig, time.Now())
+				if !ok {
+					continue
+				}
+				select {
+				case notifications <- n:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return notifications, nil
+}
+
+// notificationFromSignal turns a PropertiesChanged signal of the
+// characteristic at path that carries a new Value into a notification.
+func notificationFromSignal(path string, sig *dbus.Signal, now time.Time) (GattNotification, bool) {
+	if sig.Name != propertiesChangedSignal || string(sig.Path) != path {
+		return GattNotification{}, false
+	}
+
+	var iface string
+	var changed map[string]dbus.Variant
+	var invalidated []string
+	if err := dbus.Store(sig.Body, &iface, &changed, &invalidated); err != nil || iface != gattCharacteristicInterface {
+		return GattNotification{}, false
+	}
+
+	value, ok := properties(changed).bytesValue("Value")
+	if !ok {
+		return GattNotification{}, false
+	}
+	return GattNotification{Path: path, Value: value, Time: now}, true
+}
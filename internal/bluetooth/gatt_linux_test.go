@@ -0,0 +1,119 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestGattServicesFromObjects(t *testing.T) {
+	const dev = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	objs := map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+		dev: {deviceInterface: {"Address": dbus.MakeVariant("00:00:00:00:00:01")}},
+		dev + "/service000c/char000d/desc000f": {gattDescriptorInterface: {
+			"UUID":           dbus.MakeVariant("00002902-0000-1000-8000-00805f9b34fb"),
+			"Characteristic": dbus.MakeVariant(dbus.ObjectPath(dev + "/service000c/char000d")),
+		}},
+		dev + "/service000c/char000d": {gattCharacteristicInterface: {
+			"UUID":    dbus.MakeVariant("00002a19-0000-1000-8000-00805f9b34fb"),
+			"Service": dbus.MakeVariant(dbus.ObjectPath(dev + "/service000c")),
+			"Flags":   dbus.MakeVariant([]string{"read", "notify"}),
+			"Value":   dbus.MakeVariant([]byte{0x5a}),
+		}},
+		dev + "/service000c": {gattServiceInterface: {
+			"UUID":    dbus.MakeVariant("0000180f-0000-1000-8000-00805f9b34fb"),
+			"Primary": dbus.MakeVariant(true),
+		}},
+		dev + "/service0001": {gattServiceInterface: {
+			"UUID":    dbus.MakeVariant("6e400001-b5a3-f393-e0a9-e50e24dcca9e"),
+			"Primary": dbus.MakeVariant(true),
+		}},
+		// Objects of other devices are left out.
+		"/org/bluez/hci0/dev_00_00_00_00_00_02/service0001": {gattServiceInterface: {
+			"UUID": dbus.MakeVariant("00001800-0000-1000-8000-00805f9b34fb"),
+		}},
+	}
+
+	services := gattServicesFromObjects(dev, objs)
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got: %+v", services)
+	}
+	if services[0].Name() != "6e400001-b5a3-f393-e0a9-e50e24dcca9e" || len(services[0].Characteristics) != 0 {
+		t.Errorf("expected the custom service first, got: %+v", services[0])
+	}
+
+	battery := services[1]
+	if battery.Name() != "Battery" || len(battery.Characteristics) != 1 {
+		t.Fatalf("expected the battery service with its level, got: %+v", battery)
+	}
+	level := battery.Characteristics[0]
+	if level.Name() != "Battery Level" || !level.CanRead() || level.CanWrite() || level.Value[0] != 0x5a {
+		t.Errorf("unexpected battery level characteristic: %+v", level)
+	}
+	if len(level.Descriptors) != 1 || level.Descriptors[0].Name() != "Client Characteristic Configuration" {
+		t.Errorf("expected the CCCD below the battery level, got: %+v", level.Descriptors)
+	}
+}
+
+// TestNotifyFallback covers the characteristics that can't be acquired,
+// whose notifications come through PropertiesChanged.
+func TestNotifyFallback(t *testing.T) {
+	const charPath = "/org/bluez/hci0/dev_00_00_00_00_00_01/service000c/char000d"
+
+	adapter, conn := newTestAdapter(t)
+	char := &mockBusObject{Errors: map[string]error{
+		gattCharacteristicInterface + ".AcquireNotify": dbus.Error{Name: "org.bluez.Error.NotSupported"},
+	}}
+	conn.objects[charPath] = char
+
+	ctx, cancel := context.WithCancel(t.Context())
+	notifications, err := adapter.Notify(ctx, charPath)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Changes of other characteristics, and of other properties, are left
+	// out.
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: "/org/bluez/hci0/dev_00_00_00_00_00_01/service000c/char0010",
+		Body: []any{gattCharacteristicInterface, map[string]dbus.Variant{"Value": dbus.MakeVariant([]byte{0x01})}, []string{}},
+	})
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: charPath,
+		Body: []any{gattCharacteristicInterface, map[string]dbus.Variant{"Notifying": dbus.MakeVariant(true)}, []string{}},
+	})
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: charPath,
+		Body: []any{gattCharacteristicInterface, map[string]dbus.Variant{"Value": dbus.MakeVariant([]byte{0x5a})}, []string{}},
+	})
+
+	select {
+	case n := <-notifications:
+		if n.Path != charPath || !bytes.Equal(n.Value, []byte{0x5a}) || n.Time.IsZero() {
+			t.Errorf("unexpected notification: %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a notification")
+	}
+
+	cancel()
+	for range notifications {
+	}
+
+	expected := []string{
+		gattCharacteristicInterface + ".AcquireNotify",
+		gattCharacteristicInterface + ".StartNotify",
+		gattCharacteristicInterface + ".StopNotify",
+	}
+	if !slices.Equal(char.CallHistory, expected) {
+		t.Errorf("expected calls %v, got: %v", expected, char.CallHistory)
+	}
+}
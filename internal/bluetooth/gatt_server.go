@@ -0,0 +1,236 @@
+package bluetooth
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Errors that a GattServerHandler can return to refuse a read or a write,
+// which BlueZ passes on to the central as ATT errors. Any other error is
+// reported as a failure. ErrNotPermitted, ErrNotAuthorized and
+// ErrNotSupported can be returned as well.
+var (
+	ErrInvalidOffset      = errors.New("invalid offset")
+	ErrInvalidValueLength = errors.New("invalid value length")
+)
+
+// GattServer hosts GATT services on the adapter, which then plays the
+// peripheral role: the centrals that connect to it (e.g. phones) find the
+// services next to the ones BlueZ serves itself.
+type GattServer interface {
+	// ServeGatt exports the services of the definition and registers them
+	// with BlueZ. The reads and writes of the centrals go through the
+	// handler, which may be nil to serve the values as they are. Services
+	// are served until the application is closed.
+	ServeGatt(ctx context.Context, def GattServerDefinition, handler GattServerHandler) (GattApplication, error)
+}
+
+// GattApplication is a set of services served by the adapter.
+type GattApplication interface {
+	// Services returns the services served, with the object paths they
+	// were exported at and the current values of their attributes.
+	Services() []GattService
+	// SetValue changes the value of the characteristic at path, and
+	// notifies the centrals subscribed to it.
+	SetValue(path string, value []byte) error
+	// Close unregisters the services from BlueZ and stops serving them.
+	Close(ctx context.Context) error
+}
+
+// GattRequest describes a read or a write made by a central.
+type GattRequest struct {
+	// Path and UUID identify the characteristic or descriptor.
+	Path string
+	UUID string
+	// Device is the address of the central, if BlueZ tells.
+	Device string
+	// Offset is where the read or write starts, for long values that take
+	// more than one request.
+	Offset int
+	// MTU is the ATT MTU of the link, if BlueZ tells.
+	MTU int
+	// Type is the kind of write: "command" (without response), "request"
+	// or "reliable". It's empty for reads, and for writes when BlueZ
+	// doesn't tell.
+	Type string
+}
+
+// GattServerHandler handles the reads and writes of the attributes of a
+// GattApplication. Methods are called from the goroutine that serves D-Bus
+// calls, so they should answer quickly: BlueZ gives up on the request
+// otherwise.
+type GattServerHandler interface {
+	// ReadValue is called when a central reads an attribute, with the
+	// value the server holds for it. The value returned is the one sent,
+	// and the server takes care of the offset of long reads.
+	ReadValue(req GattRequest, value []byte) ([]byte, error)
+	// WriteValue is called when a central writes an attribute, with the
+	// whole value once the write is applied at its offset. Returning nil
+	// accepts the write, and the server holds the value from then on.
+	WriteValue(req GattRequest, value []byte) error
+}
+
+// GattServerDefinition describes the services of a GattApplication. It's
+// usually read from a JSON file through ReadGattServerDefinition, e.g.:
+//
+//	{
+//	  "services": [{
+//	    "uuid": "180f",
+//	    "characteristics": [{
+//	      "uuid": "2a19",
+//	      "flags": ["read", "notify"],
+//	      "hex": "64"
+//	    }]
+//	  }]
+//	}
+type GattServerDefinition struct {
+	Services []GattServiceDefinition `json:"services"`
+}
+
+// GattServiceDefinition describes a service. Its UUID may be in any of the
+// forms NormalizeUUID understands.
+type GattServiceDefinition struct {
+	UUID string `json:"uuid"`
+	// Primary defaults to true. Secondary services are only found through
+	// the services that include them.
+	Primary         *bool                          `json:"primary,omitempty"`
+	Characteristics []GattCharacteristicDefinition `json:"characteristics"`
+}
+
+// IsPrimary tells whether the service is a primary one.
+func (s GattServiceDefinition) IsPrimary() bool {
+	return s.Primary == nil || *s.Primary
+}
+
+// GattCharacteristicDefinition describes a characteristic. Flags are the
+// properties and permissions of the characteristic, as named by BlueZ (see
+// gattCharacteristicFlags), and the initial value is given either as text in
+// Value or as hex bytes in Hex.
+type GattCharacteristicDefinition struct {
+	UUID        string                     `json:"uuid"`
+	Flags       []string                   `json:"flags"`
+	Value       string                     `json:"value,omitempty"`
+	Hex         string                     `json:"hex,omitempty"`
+	Descriptors []GattDescriptorDefinition `json:"descriptors,omitempty"`
+}
+
+// InitialValue returns the value the characteristic starts with.
+func (c GattCharacteristicDefinition) InitialValue() ([]byte, error) {
+	return initialValue(c.Value, c.Hex)
+}
+
+// GattDescriptorDefinition describes a descriptor, like a characteristic
+// minus the descriptors. BlueZ serves the Client Characteristic
+// Configuration descriptors (0x2902) itself, so they can't be defined.
+type GattDescriptorDefinition struct {
+	UUID  string   `json:"uuid"`
+	Flags []string `json:"flags"`
+	Value string   `json:"value,omitempty"`
+	Hex   string   `json:"hex,omitempty"`
+}
+
+// InitialValue returns the value the descriptor starts with.
+func (d GattDescriptorDefinition) InitialValue() ([]byte, error) {
+	return initialValue(d.Value, d.Hex)
+}
+
+func initialValue(text, hexValue string) ([]byte, error) {
+	switch {
+	case text != "" && hexValue != "":
+		return nil, errors.New("value and hex can't be both set")
+	case hexValue != "":
+		value, err := hex.DecodeString(hexValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q: %w", hexValue, err)
+		}
+		return value, nil
+	default:
+		return []byte(text), nil
+	}
+}
+
+// gattCharacteristicFlags and gattDescriptorFlags are the flags BlueZ
+// accepts for the attributes we serve.
+var (
+	gattCharacteristicFlags = []string{
+		"broadcast", "read", "write-without-response", "write", "notify", "indicate",
+		"authenticated-signed-writes", "extended-properties", "reliable-write", "writable-auxiliaries",
+		"encrypt-read", "encrypt-write", "encrypt-notify", "encrypt-indicate",
+		"encrypt-authenticated-read", "encrypt-authenticated-write", "encrypt-authenticated-notify", "encrypt-authenticated-indicate",
+		"secure-read", "secure-write", "secure-notify", "secure-indicate", "authorize",
+	}
+	gattDescriptorFlags = []string{
+		"read", "write", "encrypt-read", "encrypt-write", "encrypt-authenticated-read",
+		"encrypt-authenticated-write", "secure-read", "secure-write", "authorize",
+	}
+)
+
+// ReadGattServerDefinition reads a definition in JSON and validates it.
+// Unknown fields are refused, so that typos don't go unnoticed.
+func ReadGattServerDefinition(r io.Reader) (GattServerDefinition, error) {
+	var def GattServerDefinition
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return GattServerDefinition{}, fmt.Errorf("failed to parse GATT server definition: %w", err)
+	}
+	if err := def.Validate(); err != nil {
+		return GattServerDefinition{}, err
+	}
+	return def, nil
+}
+
+// Validate checks the UUIDs, flags and values of the definition, so that
+// mistakes are reported before BlueZ refuses the whole application.
+func (d GattServerDefinition) Validate() error {
+	if len(d.Services) == 0 {
+		return errors.New("invalid GATT server definition: no services")
+	}
+
+	for i, s := range d.Services {
+		if !validUUID(s.UUID) {
+			return fmt.Errorf("invalid GATT server definition: service %d: invalid UUID %q", i, s.UUID)
+		}
+		if len(s.Characteristics) == 0 {
+			return fmt.Errorf("invalid GATT server definition: service %s: no characteristics", s.UUID)
+		}
+
+		for _, c := range s.Characteristics {
+			if err := validateAttribute(c.UUID, c.Flags, gattCharacteristicFlags, c.Value, c.Hex); err != nil {
+				return fmt.Errorf("invalid GATT server definition: service %s: characteristic %s: %w", s.UUID, c.UUID, err)
+			}
+
+			for _, desc := range c.Descriptors {
+				err := validateAttribute(desc.UUID, desc.Flags, gattDescriptorFlags, desc.Value, desc.Hex)
+				if err == nil && NormalizeUUID(desc.UUID) == NormalizeUUID("2902") {
+					err = errors.New("client characteristic configuration is served by BlueZ")
+				}
+				if err != nil {
+					return fmt.Errorf("invalid GATT server definition: characteristic %s: descriptor %s: %w", c.UUID, desc.UUID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateAttribute(uuid string, flags, validFlags []string, text, hexValue string) error {
+	if !validUUID(uuid) {
+		return fmt.Errorf("invalid UUID %q", uuid)
+	}
+	if len(flags) == 0 {
+		return errors.New("no flags")
+	}
+	for _, f := range flags {
+		if !slices.Contains(validFlags, f) {
+			return fmt.Errorf("unknown flag %q", f)
+		}
+	}
+	_, err := initialValue(text, hexValue)
+	return err
+}
@@ -0,0 +1,459 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	gattManagerInterface = "org.bluez.GattManager1"
+	// gattServerPath is where we export our GATT applications, one per
+	// adapter, e.g. /com/github/apaydev/bluetui/gatt/hci0.
+	gattServerPath = "/com/github/apaydev/bluetui/gatt"
+)
+
+// gattApplication is the GattApplication we export on the bus. BlueZ finds
+// its services, characteristics and descriptors through GetManagedObjects on
+// root, and then calls their methods when the centrals read or write them.
+type gattApplication struct {
+	adapter *linuxAdapter
+	root    dbus.ObjectPath
+	handler GattServerHandler
+	// attributes are sorted like the definition, services first and each
+	// followed by its characteristics and their descriptors.
+	attributes []*gattAttribute
+	// exported are the objects to unexport on close.
+	exported []gattObject
+
+	// mu guards the values and the notifying state of the attributes, and
+	// closed.
+	mu     sync.Mutex
+	closed bool
+}
+
+// gattAttribute is a service, characteristic or descriptor we serve. Which
+// one it is is given by iface.
+type gattAttribute struct {
+	app   *gattApplication
+	path  dbus.ObjectPath
+	iface string
+	uuid  string
+	// parent is the service of a characteristic, or the characteristic of
+	// a descriptor.
+	parent    dbus.ObjectPath
+	primary   bool
+	flags     []string
+	value     []byte
+	notifying bool
+}
+
+// newGattApplication builds the attributes of the definition, which must be
+// valid, laid out like BlueZ lays out the ones of remote devices.
+func newGattApplication(b *linuxAdapter, def GattServerDefinition, handler GattServerHandler) (*gattApplication, error) {
+	app := &gattApplication{
+		adapter: b,
+		root:    dbus.ObjectPath(gattServerPath + "/" + path.Base(b.path)),
+		handler: handler,
+	}
+
+	for i, s := range def.Services {
+		service := &gattAttribute{
+			app:     app,
+			path:    dbus.ObjectPath(fmt.Sprintf("%s/service%d", app.root, i)),
+			iface:   gattServiceInterface,
+			uuid:    NormalizeUUID(s.UUID),
+			primary: s.IsPrimary(),
+		}
+		app.attributes = append(app.attributes, service)
+
+		for j, c := range s.Characteristics {
+			value, err := c.InitialValue()
+			if err != nil {
+				return nil, fmt.Errorf("characteristic %s: %w", c.UUID, err)
+			}
+			char := &gattAttribute{
+				app:    app,
+				path:   dbus.ObjectPath(fmt.Sprintf("%s/char%d", service.path, j)),
+				iface:  gattCharacteristicInterface,
+				uuid:   NormalizeUUID(c.UUID),
+				parent: service.path,
+				flags:  slices.Clone(c.Flags),
+				value:  value,
+			}
+			app.attributes = append(app.attributes, char)
+
+			for k, d := range c.Descriptors {
+				value, err := d.InitialValue()
+				if err != nil {
+					return nil, fmt.Errorf("descriptor %s: %w", d.UUID, err)
+				}
+				app.attributes = append(app.attributes, &gattAttribute{
+					app:    app,
+					path:   dbus.ObjectPath(fmt.Sprintf("%s/desc%d", char.path, k)),
+					iface:  gattDescriptorInterface,
+					uuid:   NormalizeUUID(d.UUID),
+					parent: char.path,
+					flags:  slices.Clone(d.Flags),
+					value:  value,
+				})
+			}
+		}
+	}
+
+	return app, nil
+}
+
+// ServeGatt exports the services of the definition under our own path and
+// registers them with the GATT manager of the adapter. BlueZ only accepts one
+// application per path, so serving again before closing the previous
+// application fails with ErrAlreadyExists.
+func (b *linuxAdapter) ServeGatt(ctx context.Context, def GattServerDefinition, handler GattServerHandler) (GattApplication, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	app, err := newGattApplication(b, def, handler)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GATT server definition: %w", err)
+	}
+	if err := app.export(); err != nil {
+		app.unexport()
+		return nil, fmt.Errorf("failed to export GATT application: %w", err)
+	}
+
+	// BlueZ reads the objects back through GetManagedObjects before
+	// answering, which the connection serves from its own goroutine.
+	manager := b.conn.Object(b.destination, dbus.ObjectPath(b.path))
+	err = call(ctx, manager, gattManagerInterface+".RegisterApplication", app.root, map[string]dbus.Variant{})
+	if err != nil {
+		app.unexport()
+		return nil, fmt.Errorf("failed to register GATT application: %w", err)
+	}
+
+	return app, nil
+}
+
+// gattObject is one of the objects of the application, as exported.
+type gattObject struct {
+	v     any
+	path  dbus.ObjectPath
+	iface string
+}
+
+// export serves the object manager of the application and every attribute.
+// Services only have properties, while characteristics and descriptors also
+// have the methods to read and write them.
+func (app *gattApplication) export() error {
+	objects := []gattObject{{&gattObjectManager{app}, app.root, objectManagerInterface}}
+	for _, a := range app.attributes {
+		objects = append(objects, gattObject{&exportedProperties{a.iface, a.lockedProperties}, a.path, propertiesInterface})
+		switch a.iface {
+		case gattCharacteristicInterface:
+			objects = append(objects, gattObject{&gattCharacteristicObject{a}, a.path, a.iface})
+		case gattDescriptorInterface:
+			objects = append(objects, gattObject{&gattDescriptorObject{a}, a.path, a.iface})
+		}
+	}
+
+	for _, o := range objects {
+		if err := app.adapter.conn.Export(o.v, o.path, o.iface); err != nil {
+			return fmt.Errorf("failed to export %s: %w", o.path, err)
+		}
+		app.exported = append(app.exported, o)
+	}
+	return nil
+}
+
+// unexport stops serving every object exported so far.
+func (app *gattApplication) unexport() {
+	for _, o := range app.exported {
+		if err := app.adapter.conn.Export(nil, o.path, o.iface); err != nil {
+			app.adapter.logger.Warn("failed to stop exporting GATT object", "path", o.path, "error", err)
+		}
+	}
+	app.exported = nil
+}
+
+// Services returns the attributes served as a GATT database, like the ones
+// of remote devices.
+func (app *gattApplication) Services() []GattService {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	var services []GattService
+	for _, a := range app.attributes {
+		switch a.iface {
+		case gattServiceInterface:
+			services = append(services, GattService{Path: string(a.path), UUID: a.uuid, Primary: a.primary})
+		case gattCharacteristicInterface:
+			s := &services[len(services)-1]
+			s.Characteristics = append(s.Characteristics, GattCharacteristic{
+				Path:      string(a.path),
+				UUID:      a.uuid,
+				Flags:     slices.Clone(a.flags),
+				Value:     slices.Clone(a.value),
+				Notifying: a.notifying,
+			})
+		case gattDescriptorInterface:
+			s := &services[len(services)-1]
+			c := &s.Characteristics[len(s.Characteristics)-1]
+			c.Descriptors = append(c.Descriptors, GattDescriptor{
+				Path:  string(a.path),
+				UUID:  a.uuid,
+				Flags: slices.Clone(a.flags),
+				Value: slices.Clone(a.value),
+			})
+		}
+	}
+	return services
+}
+
+// SetValue changes the value of a characteristic. BlueZ turns the change of
+// the Value property into a notification or an indication for the centrals
+// that subscribed, which it tells us about through StartNotify.
+func (app *gattApplication) SetValue(path string, value []byte) error {
+	var char *gattAttribute
+	for _, a := range app.attributes {
+		if string(a.path) == path && a.iface == gattCharacteristicInterface {
+			char = a
+		}
+	}
+	if char == nil {
+		return fmt.Errorf("failed to set value of characteristic %s: %w", path, ErrDoesNotExist)
+	}
+
+	app.mu.Lock()
+	if app.closed {
+		app.mu.Unlock()
+		return fmt.Errorf("failed to set value of characteristic %s: application closed", path)
+	}
+	char.value = slices.Clone(value)
+	notifying := char.notifying
+	app.mu.Unlock()
+
+	if !notifying {
+		return nil
+	}
+	err := app.adapter.conn.Emit(char.path, propertiesChangedSignal, gattCharacteristicInterface,
+		map[string]dbus.Variant{"Value": dbus.MakeVariant(value)}, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to notify characteristic %s: %w", path, err)
+	}
+	return nil
+}
+
+// Close unregisters the application and unexports its objects. The objects
+// are unexported even if BlueZ fails to unregister it (e.g. because the
+// adapter is gone), since they are of no use by then.
+func (app *gattApplication) Close(ctx context.Context) error {
+	app.mu.Lock()
+	if app.closed {
+		app.mu.Unlock()
+		return nil
+	}
+	app.closed = true
+	app.mu.Unlock()
+	defer app.unexport()
+
+	b := app.adapter
+	manager := b.conn.Object(b.destination, dbus.ObjectPath(b.path))
+	if err := call(ctx, manager, gattManagerInterface+".UnregisterApplication", app.root); err != nil {
+		return fmt.Errorf("failed to unregister GATT application: %w", err)
+	}
+	return nil
+}
+
+// properties returns the properties of the attribute, as BlueZ expects them.
+// The caller must hold the lock of the application.
+func (a *gattAttribute) properties() map[string]dbus.Variant {
+	props := map[string]dbus.Variant{"UUID": dbus.MakeVariant(a.uuid)}
+	switch a.iface {
+	case gattServiceInterface:
+		props["Primary"] = dbus.MakeVariant(a.primary)
+	case gattCharacteristicInterface:
+		props["Service"] = dbus.MakeVariant(a.parent)
+		props["Flags"] = dbus.MakeVariant(a.flags)
+		props["Value"] = dbus.MakeVariant(slices.Clone(a.value))
+		props["Notifying"] = dbus.MakeVariant(a.notifying)
+	case gattDescriptorInterface:
+		props["Characteristic"] = dbus.MakeVariant(a.parent)
+		props["Flags"] = dbus.MakeVariant(a.flags)
+		props["Value"] = dbus.MakeVariant(slices.Clone(a.value))
+	}
+	return props
+}
+
+// lockedProperties returns the properties of the attribute, taking the lock
+// of the application.
+func (a *gattAttribute) lockedProperties() map[string]dbus.Variant {
+	a.app.mu.Lock()
+	defer a.app.mu.Unlock()
+	return a.properties()
+}
+
+// request builds the request given to the handler out of the options BlueZ
+// passes to ReadValue and WriteValue.
+func (a *gattAttribute) request(options map[string]dbus.Variant) GattRequest {
+	p := properties(options)
+	req := GattRequest{Path: string(a.path), UUID: a.uuid}
+	if device, ok := p.objectPathValue("device"); ok {
+		req.Device = addressFromPath(device)
+	}
+	if offset, ok := p.uint16Value("offset"); ok {
+		req.Offset = int(offset)
+	}
+	if mtu, ok := p.uint16Value("mtu"); ok {
+		req.MTU = int(mtu)
+	}
+	req.Type, _ = p.stringValue("type")
+	return req
+}
+
+// readValue answers a read with the value held for the attribute, or the one
+// given by the handler, from the offset asked for.
+func (a *gattAttribute) readValue(options map[string]dbus.Variant) ([]byte, *dbus.Error) {
+	req := a.request(options)
+
+	a.app.mu.Lock()
+	value := slices.Clone(a.value)
+	a.app.mu.Unlock()
+
+	if a.app.handler != nil {
+		v, err := a.app.handler.ReadValue(req, value)
+		if err != nil {
+			return nil, gattError(err)
+		}
+		value = v
+	}
+
+	if req.Offset > len(value) {
+		return nil, gattError(ErrInvalidOffset)
+	}
+	return value[req.Offset:], nil
+}
+
+// writeValue applies a write at its offset, and keeps the value if the
+// handler accepts it.
+func (a *gattAttribute) writeValue(value []byte, options map[string]dbus.Variant) *dbus.Error {
+	req := a.request(options)
+
+	a.app.mu.Lock()
+	current := slices.Clone(a.value)
+	a.app.mu.Unlock()
+
+	if req.Offset > len(current) {
+		return gattError(ErrInvalidOffset)
+	}
+	value = append(current[:req.Offset], value...)
+
+	if a.app.handler != nil {
+		if err := a.app.handler.WriteValue(req, slices.Clone(value)); err != nil {
+			return gattError(err)
+		}
+	}
+
+	a.app.mu.Lock()
+	a.value = value
+	a.app.mu.Unlock()
+	return nil
+}
+
+// gattError translates the error returned by a GattServerHandler into the one
+// BlueZ expects from a GATT attribute.
+func gattError(err error) *dbus.Error {
+	name := "org.bluez.Error.Failed"
+	switch {
+	case errors.Is(err, ErrInvalidOffset):
+		name = "org.bluez.Error.InvalidOffset"
+	case errors.Is(err, ErrInvalidValueLength):
+		name = "org.bluez.Error.InvalidValueLength"
+	case errors.Is(err, ErrNotPermitted):
+		name = "org.bluez.Error.NotPermitted"
+	case errors.Is(err, ErrNotAuthorized):
+		name = "org.bluez.Error.NotAuthorized"
+	case errors.Is(err, ErrNotSupported):
+		name = "org.bluez.Error.NotSupported"
+	}
+	return dbus.NewError(name, []any{err.Error()})
+}
+
+// gattObjectManager is the org.freedesktop.DBus.ObjectManager object at the
+// root of the application.
+type gattObjectManager struct {
+	app *gattApplication
+}
+
+func (m *gattObjectManager) GetManagedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, *dbus.Error) {
+	m.app.mu.Lock()
+	defer m.app.mu.Unlock()
+
+	objs := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant, len(m.app.attributes))
+	for _, a := range m.app.attributes {
+		objs[a.path] = map[string]map[string]dbus.Variant{a.iface: a.properties()}
+	}
+	return objs, nil
+}
+
+// gattCharacteristicObject is the org.bluez.GattCharacteristic1 object of a
+// characteristic.
+type gattCharacteristicObject struct {
+	a *gattAttribute
+}
+
+func (c *gattCharacteristicObject) ReadValue(options map[string]dbus.Variant) ([]byte, *dbus.Error) {
+	return c.a.readValue(options)
+}
+
+func (c *gattCharacteristicObject) WriteValue(value []byte, options map[string]dbus.Variant) *dbus.Error {
+	return c.a.writeValue(value, options)
+}
+
+// StartNotify is called by BlueZ when the first central subscribes, and
+// StopNotify when the last one is gone.
+func (c *gattCharacteristicObject) StartNotify() *dbus.Error {
+	return c.setNotifying(true)
+}
+
+func (c *gattCharacteristicObject) StopNotify() *dbus.Error {
+	return c.setNotifying(false)
+}
+
+func (c *gattCharacteristicObject) setNotifying(notifying bool) *dbus.Error {
+	// Any of the notify and indicate flags, encrypted or not, will do.
+	if notifying && !slices.ContainsFunc(c.a.flags, func(f string) bool {
+		return strings.HasSuffix(f, "notify") || strings.HasSuffix(f, "indicate")
+	}) {
+		return gattError(ErrNotSupported)
+	}
+	c.a.app.mu.Lock()
+	c.a.notifying = notifying
+	c.a.app.mu.Unlock()
+	return nil
+}
+
+// Confirm is called when a central confirms an indication. There's nothing
+// to do with it.
+func (c *gattCharacteristicObject) Confirm() *dbus.Error {
+	return nil
+}
+
+// gattDescriptorObject is the org.bluez.GattDescriptor1 object of a
+// descriptor.
+type gattDescriptorObject struct {
+	a *gattAttribute
+}
+
+func (d *gattDescriptorObject) ReadValue(options map[string]dbus.Variant) ([]byte, *dbus.Error) {
+	return d.a.readValue(options)
+}
+
+func (d *gattDescriptorObject) WriteValue(value []byte, options map[string]dbus.Variant) *dbus.Error {
+	return d.a.writeValue(value, options)
+}
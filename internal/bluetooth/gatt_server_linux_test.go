@@ -0,0 +1,120 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeGattServerHandler refuses the writes of empty values and records the
+// requests.
+type fakeGattServerHandler struct {
+	requests []GattRequest
+}
+
+func (f *fakeGattServerHandler) ReadValue(req GattRequest, value []byte) ([]byte, error) {
+	f.requests = append(f.requests, req)
+	return value, nil
+}
+
+func (f *fakeGattServerHandler) WriteValue(req GattRequest, value []byte) error {
+	f.requests = append(f.requests, req)
+	if len(value) == 0 {
+		return ErrInvalidValueLength
+	}
+	return nil
+}
+
+func TestServeGatt(t *testing.T) {
+	const (
+		root  = gattServerPath + "/hci0"
+		level = root + "/service0/char0"
+	)
+
+	adapter, conn := newTestAdapter(t)
+	manager := &mockBusObject{}
+	conn.objects[adapter.Path()] = manager
+
+	def := GattServerDefinition{Services: []GattServiceDefinition{{
+		UUID: "180f",
+		Characteristics: []GattCharacteristicDefinition{{
+			UUID:        "2a19",
+			Flags:       []string{"read", "write", "notify"},
+			Hex:         "6465",
+			Descriptors: []GattDescriptorDefinition{{UUID: "2901", Flags: []string{"read"}, Value: "Level"}},
+		}},
+	}}}
+	handler := &fakeGattServerHandler{}
+	app, err := adapter.ServeGatt(t.Context(), def, handler)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(manager.CallHistory, []string{gattManagerInterface + ".RegisterApplication"}) {
+		t.Errorf("expected the application to be registered, got: %v", manager.CallHistory)
+	}
+
+	// BlueZ finds the whole tree through the object manager.
+	objs, dbusErr := conn.exported[root+" "+objectManagerInterface].(*gattObjectManager).GetManagedObjects()
+	if dbusErr != nil || len(objs) != 3 {
+		t.Fatalf("expected 3 managed objects, got: %v (%v)", objs, dbusErr)
+	}
+	props := properties(objs[level][gattCharacteristicInterface])
+	if uuid, _ := props.stringValue("UUID"); uuid != "00002a19-0000-1000-8000-00805f9b34fb" {
+		t.Errorf("expected the full UUID of the battery level, got: %s", uuid)
+	}
+	if service, _ := props.objectPathValue("Service"); service != root+"/service0" {
+		t.Errorf("expected the characteristic to point to its service, got: %s", service)
+	}
+
+	char := conn.exported[level+" "+gattCharacteristicInterface].(*gattCharacteristicObject)
+	options := map[string]dbus.Variant{
+		"device": dbus.MakeVariant(dbus.ObjectPath("/org/bluez/hci0/dev_00_11_22_33_44_55")),
+		"offset": dbus.MakeVariant(uint16(1)),
+	}
+	if value, err := char.ReadValue(options); err != nil || !bytes.Equal(value, []byte{0x65}) {
+		t.Errorf("expected the value from offset 1, got: % x (%v)", value, err)
+	}
+	if req := handler.requests[0]; req.Device != "00:11:22:33:44:55" || req.Offset != 1 {
+		t.Errorf("expected the request of the central at offset 1, got: %+v", req)
+	}
+
+	// Writes apply at their offset, unless the handler refuses them.
+	if err := char.WriteValue([]byte{0x01, 0x02}, options); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := char.WriteValue(nil, map[string]dbus.Variant{}); err == nil || err.Name != "org.bluez.Error.InvalidValueLength" {
+		t.Errorf("expected the write to be refused, got: %v", err)
+	}
+	if value := app.Services()[0].Characteristics[0].Value; !bytes.Equal(value, []byte{0x64, 0x01, 0x02}) {
+		t.Errorf("expected value 64 01 02, got: % x", value)
+	}
+
+	// Values only notify once a central subscribed.
+	if err := app.SetValue(level, []byte{0x10}); err != nil || len(conn.emitted) != 0 {
+		t.Fatalf("expected no notification, got: %v (%v)", conn.emitted, err)
+	}
+	if err := char.StartNotify(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := app.SetValue(level, []byte{0x20}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	n, ok := notificationFromSignal(level, conn.emitted[0], time.Now())
+	if !ok || !bytes.Equal(n.Value, []byte{0x20}) {
+		t.Errorf("expected a notification of 20, got: %+v", conn.emitted)
+	}
+
+	if err := app.Close(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(conn.exported) != 0 {
+		t.Errorf("expected every object to be unexported, got: %v", conn.exported)
+	}
+	if manager.CallHistory[1] != gattManagerInterface+".UnregisterApplication" {
+		t.Errorf("expected the application to be unregistered, got: %v", manager.CallHistory)
+	}
+}
@@ -0,0 +1,85 @@
+package bluetooth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadGattServerDefinition(t *testing.T) {
+	def, err := ReadGattServerDefinition(strings.NewReader(`{
+		"services": [{
+			"uuid": "180f",
+			"characteristics": [{
+				"uuid": "2a19",
+				"flags": ["read", "notify"],
+				"hex": "64",
+				"descriptors": [{"uuid": "2901", "flags": ["read"], "value": "Battery"}]
+			}]
+		}, {
+			"uuid": "6e400001-b5a3-f393-e0a9-e50e24dcca9e",
+			"primary": false,
+			"characteristics": [{"uuid": "6e400002-b5a3-f393-e0a9-e50e24dcca9e", "flags": ["write"]}]
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(def.Services) != 2 || !def.Services[0].IsPrimary() || def.Services[1].IsPrimary() {
+		t.Fatalf("expected a primary and a secondary service, got: %+v", def.Services)
+	}
+	level := def.Services[0].Characteristics[0]
+	if value, err := level.InitialValue(); err != nil || !bytes.Equal(value, []byte{0x64}) {
+		t.Errorf("expected value 64, got: % x (%v)", value, err)
+	}
+	if value, err := level.Descriptors[0].InitialValue(); err != nil || string(value) != "Battery" {
+		t.Errorf("expected value Battery, got: %q (%v)", value, err)
+	}
+}
+
+func TestReadGattServerDefinitionErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{name: "no services", json: `{"services": []}`, expected: "no services"},
+		{name: "unknown field", json: `{"services": [], "name": "x"}`, expected: "unknown field"},
+		{
+			name:     "invalid UUID",
+			json:     `{"services": [{"uuid": "18z0", "characteristics": []}]}`,
+			expected: `invalid UUID "18z0"`,
+		},
+		{
+			name:     "unknown flag",
+			json:     `{"services": [{"uuid": "180f", "characteristics": [{"uuid": "2a19", "flags": ["reed"]}]}]}`,
+			expected: `unknown flag "reed"`,
+		},
+		{
+			name:     "value and hex",
+			json:     `{"services": [{"uuid": "180f", "characteristics": [{"uuid": "2a19", "flags": ["read"], "value": "d", "hex": "64"}]}]}`,
+			expected: "can't be both set",
+		},
+		{
+			name:     "invalid hex",
+			json:     `{"services": [{"uuid": "180f", "characteristics": [{"uuid": "2a19", "flags": ["read"], "hex": "6"}]}]}`,
+			expected: "invalid hex value",
+		},
+		{
+			name: "client characteristic configuration",
+			json: `{"services": [{"uuid": "180f", "characteristics": [{"uuid": "2a19", "flags": ["notify"],
+				"descriptors": [{"uuid": "2902", "flags": ["read", "write"]}]}]}]}`,
+			expected: "served by BlueZ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadGattServerDefinition(strings.NewReader(tc.json))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error containing %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoPlayer is returned when the device doesn't expose a media player,
+// usually because it's not connected over AVRCP or nothing is playing yet.
+var ErrNoPlayer = errors.New("no media player")
+
+// Media controls the media players that connected devices (e.g. phones)
+// expose over AVRCP. Devices are identified by their address.
+type Media interface {
+	// Player returns the current state of the player of the device.
+	Player(ctx context.Context, addr string) (PlayerState, error)
+	// WatchPlayer streams the state of the player of the device every time
+	// it changes, starting with the current one, until the context is done.
+	// When the player goes away, a PlayerState without Path is sent.
+	WatchPlayer(ctx context.Context, addr string) (<-chan PlayerState, error)
+	// ControlPlayer sends a transport command to the player of the device.
+	ControlPlayer(ctx context.Context, addr string, cmd PlayerCommand) error
+}
+
+// PlayerCommand is one of the transport controls of a media player. Their
+// values are the names of the MediaPlayer1 methods.
+type PlayerCommand string
+
+const (
+	PlayerPlay        PlayerCommand = "Play"
+	PlayerPause       PlayerCommand = "Pause"
+	PlayerStop        PlayerCommand = "Stop"
+	PlayerNext        PlayerCommand = "Next"
+	PlayerPrevious    PlayerCommand = "Previous"
+	PlayerFastForward PlayerCommand = "FastForward"
+	PlayerRewind      PlayerCommand = "Rewind"
+)
+
+// PlayerStatus tells what the player is doing.
+type PlayerStatus string
+
+const (
+	PlayerPlaying     PlayerStatus = "playing"
+	PlayerStopped     PlayerStatus = "stopped"
+	PlayerPaused      PlayerStatus = "paused"
+	PlayerForwardSeek PlayerStatus = "forward-seek"
+	PlayerReverseSeek PlayerStatus = "reverse-seek"
+	PlayerError       PlayerStatus = "error"
+)
+
+// Track is the metadata of the track loaded in a player. Any of the fields
+// may be empty, since it depends on what the device sends.
+type Track struct {
+	Title          string
+	Artist         string
+	Album          string
+	Genre          string
+	TrackNumber    uint32
+	NumberOfTracks uint32
+	Duration       time.Duration
+}
+
+// PlayerState is the state of a media player.
+type PlayerState struct {
+	// Path is the object path of the player. It's empty when the device has
+	// no player.
+	Path string
+	// Device is the address of the device the player belongs to.
+	Device string
+	// Name is the name of the player, usually the app that plays.
+	Name   string
+	Status PlayerStatus
+	Track  Track
+	// Position is the playback position at UpdatedAt. BlueZ only reports it
+	// when it jumps (e.g. on seeks and status changes), so it must be moved
+	// forward while playing. See CurrentPosition.
+	Position  time.Duration
+	UpdatedAt time.Time
+	Repeat    string
+	Shuffle   string
+}
+
+// CurrentPosition estimates the playback position at the given time.
+func (s PlayerState) CurrentPosition(now time.Time) time.Duration {
+	position := s.Position
+	if s.Status == PlayerPlaying && !s.UpdatedAt.IsZero() {
+		position += now.Sub(s.UpdatedAt)
+	}
+	if s.Track.Duration > 0 && position > s.Track.Duration {
+		position = s.Track.Duration
+	}
+	return position
+}
@@ -0,0 +1,263 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const mediaPlayerInterface = "org.bluez.MediaPlayer1"
+
+// playerFromProperties builds the state of a player out of its MediaPlayer1
+// properties.
+func playerFromProperties(path dbus.ObjectPath, raw map[string]dbus.Variant) PlayerState {
+	state := PlayerState{Path: string(path), Device: addressFromPath(dbus.ObjectPath(playerDevicePath(path)))}
+	state.applyProperties(raw, time.Now())
+	return state
+}
+
+// playerDevicePath returns the path of the device a player belongs to. BlueZ
+// puts players right below their device, e.g. .../dev_XX_XX/player0.
+func playerDevicePath(path dbus.ObjectPath) string {
+	p := string(path)
+	if i := strings.LastIndex(p, "/"); i > 0 {
+		return p[:i]
+	}
+	return p
+}
+
+// applyProperties applies the changes of a PropertiesChanged signal, which
+// happened at the given time.
+func (s *PlayerState) applyProperties(raw map[string]dbus.Variant, now time.Time) {
+	p := properties(raw)
+
+	if v, ok := p.stringValue("Name"); ok {
+		s.Name = v
+	}
+	if v, ok := p.stringValue("Status"); ok {
+		// The position keeps moving while playing, so it has to be taken
+		// from where it was before the status changes.
+		s.Position = s.CurrentPosition(now)
+		s.UpdatedAt = now
+		s.Status = PlayerStatus(v)
+	}
+	if v, ok := p.uint32Value("Position"); ok {
+		s.Position = time.Duration(v) * time.Millisecond
+		s.UpdatedAt = now
+	}
+	if v, ok := p.stringValue("Repeat"); ok {
+		s.Repeat = v
+	}
+	if v, ok := p.stringValue("Shuffle"); ok {
+		s.Shuffle = v
+	}
+	if v, ok := raw["Track"].Value().(map[string]dbus.Variant); ok {
+		s.Track = trackFromProperties(v)
+	}
+}
+
+// trackFromProperties decodes the Track property of a player.
+func trackFromProperties(raw map[string]dbus.Variant) Track {
+	p := properties(raw)
+
+	var track Track
+	track.Title, _ = p.stringValue("Title")
+	track.Artist, _ = p.stringValue("Artist")
+	track.Album, _ = p.stringValue("Album")
+	track.Genre, _ = p.stringValue("Genre")
+	track.TrackNumber, _ = p.uint32Value("TrackNumber")
+	track.NumberOfTracks, _ = p.uint32Value("NumberOfTracks")
+	if v, ok := p.uint32Value("Duration"); ok {
+		track.Duration = time.Duration(v) * time.Millisecond
+	}
+	return track
+}
+
+// findPlayer looks for the player of the device. Devices may expose more than
+// one (e.g. one per app), in which case the one playing wins.
+func (b *linuxAdapter) findPlayer(ctx context.Context, addr string) (PlayerState, error) {
+	dev, err := b.resolveDevice(ctx, addr)
+	if err != nil {
+		return PlayerState{}, err
+	}
+
+	objs, err := managedObjects(ctx, b.conn, b.destination)
+	if err != nil {
+		return PlayerState{}, err
+	}
+
+	var players []PlayerState
+	for path, ifaces := range objs {
+		props, ok := ifaces[mediaPlayerInterface]
+		if !ok || playerDevicePath(path) != dev.Path() {
+			continue
+		}
+		players = append(players, playerFromProperties(path, props))
+	}
+	if len(players) == 0 {
+		return PlayerState{}, ErrNoPlayer
+	}
+
+	slices.SortFunc(players, func(a, b PlayerState) int {
+		if (a.Status == PlayerPlaying) != (b.Status == PlayerPlaying) {
+			if a.Status == PlayerPlaying {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return players[0], nil
+}
+
+// Player returns the current state of the player of the device.
+func (b *linuxAdapter) Player(ctx context.Context, addr string) (PlayerState, error) {
+	state, err := b.findPlayer(ctx, addr)
+	if err != nil {
+		return PlayerState{}, fmt.Errorf("failed to get player of device at addr %s: %w", addr, err)
+	}
+	return state, nil
+}
+
+// ControlPlayer sends a transport command to the player of the device.
+func (b *linuxAdapter) ControlPlayer(ctx context.Context, addr string, cmd PlayerCommand) error {
+	state, err := b.findPlayer(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to %s player of device at addr %s: %w", strings.ToLower(string(cmd)), addr, err)
+	}
+
+	player := b.conn.Object(b.destination, dbus.ObjectPath(state.Path))
+	if err := call(ctx, player, mediaPlayerInterface+"."+string(cmd)); err != nil {
+		return fmt.Errorf("failed to %s player of device at addr %s: %w", strings.ToLower(string(cmd)), addr, err)
+	}
+	return nil
+}
+
+// WatchPlayer follows the player of the device through the PropertiesChanged
+// signals of MediaPlayer1. Players come and go (e.g. when the phone switches
+// apps), so InterfacesAdded and InterfacesRemoved are followed too.
+func (b *linuxAdapter) WatchPlayer(ctx context.Context, addr string) (<-chan PlayerState, error) {
+	dev, err := b.resolveDevice(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch player of device at addr %s: %w", addr, err)
+	}
+
+	signals, unsubscribe, err := b.subscribe([][]dbus.MatchOption{
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(objectManagerInterface),
+			dbus.WithMatchMember("InterfacesAdded"),
+		},
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(objectManagerInterface),
+			dbus.WithMatchMember("InterfacesRemoved"),
+		},
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(propertiesInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchPathNamespace(dbus.ObjectPath(dev.Path())),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Not having a player yet is fine, we'll get it once it's added.
+	state, err := b.findPlayer(ctx, addr)
+	if err != nil && !errors.Is(err, ErrNoPlayer) {
+		unsubscribe()
+		return nil, fmt.Errorf("failed to watch player of device at addr %s: %w", addr, err)
+	}
+	state.Device = dev.Address()
+
+	states := make(chan PlayerState, 8)
+	go func() {
+		defer close(states)
+		defer unsubscribe()
+
+		send := func(s PlayerState) bool {
+			select {
+			case states <- s:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if !send(state) {
+			return
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				next, changed := handlePlayerSignal(state, dev.Path(), sig)
+				if !changed {
+					continue
+				}
+				state = next
+				if !send(state) {
+					return
+				}
+			}
+		}
+	}()
+
+	return states, nil
+}
+
+// handlePlayerSignal applies a BlueZ signal to the state of the player of the
+// device at devicePath. It returns false if the signal didn't change it.
+func handlePlayerSignal(state PlayerState, devicePath string, sig *dbus.Signal) (PlayerState, bool) {
+	switch sig.Name {
+	case interfacesAddedSignal:
+		var path dbus.ObjectPath
+		var ifaces map[string]map[string]dbus.Variant
+		if err := dbus.Store(sig.Body, &path, &ifaces); err != nil {
+			return state, false
+		}
+		props, ok := ifaces[mediaPlayerInterface]
+		if !ok || playerDevicePath(path) != devicePath {
+			return state, false
+		}
+		return playerFromProperties(path, props), true
+
+	case interfacesRemovedSignal:
+		var path dbus.ObjectPath
+		var ifaces []string
+		if err := dbus.Store(sig.Body, &path, &ifaces); err != nil {
+			return state, false
+		}
+		if string(path) != state.Path || !slices.Contains(ifaces, mediaPlayerInterface) {
+			return state, false
+		}
+		return PlayerState{Device: state.Device}, true
+
+	case propertiesChangedSignal:
+		var iface string
+		var changed map[string]dbus.Variant
+		var invalidated []string
+		if err := dbus.Store(sig.Body, &iface, &changed, &invalidated); err != nil {
+			return state, false
+		}
+		if iface != mediaPlayerInterface || string(sig.Path) != state.Path {
+			return state, false
+		}
+		state.applyProperties(changed, time.Now())
+		return state, true
+	}
+
+	return state, false
+}
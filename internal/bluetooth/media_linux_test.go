@@ -0,0 +1,57 @@
+package bluetooth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPlayerState(t *testing.T) {
+	const playerPath = "/org/bluez/hci0/dev_00_00_00_00_00_01/player0"
+
+	state := playerFromProperties(playerPath, map[string]dbus.Variant{
+		"Name":     dbus.MakeVariant("Music"),
+		"Status":   dbus.MakeVariant("paused"),
+		"Position": dbus.MakeVariant(uint32(30000)),
+		"Track": dbus.MakeVariant(map[string]dbus.Variant{
+			"Title":    dbus.MakeVariant("Song"),
+			"Artist":   dbus.MakeVariant("Band"),
+			"Duration": dbus.MakeVariant(uint32(180000)),
+		}),
+	})
+
+	if state.Device != "00:00:00:00:00:01" || state.Track.Title != "Song" || state.Track.Duration != 3*time.Minute {
+		t.Fatalf("unexpected player state: %+v", state)
+	}
+
+	// The position stays put while paused, and moves once playing.
+	now := state.UpdatedAt.Add(10 * time.Second)
+	if got := state.CurrentPosition(now); got != 30*time.Second {
+		t.Errorf("expected paused position to be 30s, got: %s", got)
+	}
+
+	state, ok := handlePlayerSignal(state, "/org/bluez/hci0/dev_00_00_00_00_00_01", &dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: playerPath,
+		Body: []any{mediaPlayerInterface, map[string]dbus.Variant{"Status": dbus.MakeVariant("playing")}, []string{}},
+	})
+	if !ok || state.Status != PlayerPlaying {
+		t.Fatalf("expected player to be playing, got: %+v", state)
+	}
+	if got := state.CurrentPosition(state.UpdatedAt.Add(5 * time.Second)); got != 35*time.Second {
+		t.Errorf("expected position to move while playing, got: %s", got)
+	}
+	if got := state.CurrentPosition(state.UpdatedAt.Add(time.Hour)); got != 3*time.Minute {
+		t.Errorf("expected position to stop at the end of the track, got: %s", got)
+	}
+
+	state, ok = handlePlayerSignal(state, "/org/bluez/hci0/dev_00_00_00_00_00_01", &dbus.Signal{
+		Name: interfacesRemovedSignal,
+		Path: "/",
+		Body: []any{dbus.ObjectPath(playerPath), []string{mediaPlayerInterface}},
+	})
+	if !ok || state.Path != "" || state.Device != "00:00:00:00:00:01" {
+		t.Errorf("expected player to be gone, got: %+v", state)
+	}
+}
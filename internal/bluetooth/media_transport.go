@@ -0,0 +1,78 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMediaTransport is returned when the device has no audio stream set up,
+// usually because it's not connected over A2DP.
+var ErrNoMediaTransport = errors.New("no media transport")
+
+// MaxVolume is the highest volume of a transport. It's the AVRCP absolute
+// volume, which goes from 0 to 127.
+const MaxVolume = 127
+
+// Audio inspects the audio streams (transports) set up with the connected
+// devices, and controls their volume. Devices are identified by their
+// address.
+type Audio interface {
+	// Transports returns the transports of the device. There's usually one
+	// per audio profile connected, e.g. A2DP and HFP.
+	Transports(ctx context.Context, addr string) ([]MediaTransport, error)
+	// SetVolume sets the volume of the main transport of the device, from
+	// 0 to MaxVolume.
+	SetVolume(ctx context.Context, addr string, volume uint16) error
+}
+
+// MediaTransportState tells whether audio flows through a transport.
+type MediaTransportState string
+
+const (
+	// MediaTransportIdle is not streaming.
+	MediaTransportIdle MediaTransportState = "idle"
+	// MediaTransportPending is streaming, but nobody has acquired it yet.
+	MediaTransportPending MediaTransportState = "pending"
+	// MediaTransportActive is streaming and acquired (e.g. by PipeWire).
+	MediaTransportActive MediaTransportState = "active"
+)
+
+// MediaTransport is an audio stream set up with a device.
+type MediaTransport struct {
+	// Path is the object path of the transport.
+	Path string
+	// Device is the address of the device the transport belongs to.
+	Device string
+	// UUID is the profile the transport is set up for, e.g. UUIDAudioSink.
+	UUID  string
+	State MediaTransportState
+	// Codec is the negotiated codec and its configuration. CodecErr is set
+	// when the configuration couldn't be decoded, in which case Codec only
+	// has its ID and raw configuration.
+	Codec    CodecConfig
+	CodecErr error
+	// Volume goes from 0 to MaxVolume. HasVolume is false when the device
+	// doesn't support AVRCP absolute volume.
+	Volume    uint16
+	HasVolume bool
+}
+
+// MainTransport picks the transport whose volume SetVolume controls: the one
+// that streams, then one about to stream, or the first one with a volume
+// otherwise.
+func MainTransport(transports []MediaTransport) (MediaTransport, bool) {
+	var main MediaTransport
+	found := false
+	for _, t := range transports {
+		if !t.HasVolume {
+			continue
+		}
+		if t.State == MediaTransportActive {
+			return t, true
+		}
+		if !found || (t.State == MediaTransportPending && main.State != MediaTransportPending) {
+			main, found = t, true
+		}
+	}
+	return main, found
+}
@@ -0,0 +1,87 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const mediaTransportInterface = "org.bluez.MediaTransport1"
+
+// transportFromProperties builds a transport out of its MediaTransport1
+// properties. BlueZ puts transports below the endpoint of the device they
+// stream to (e.g. .../dev_XX_XX/sep1/fd0), so the device is taken from the
+// Device property instead of the path.
+func transportFromProperties(path dbus.ObjectPath, raw map[string]dbus.Variant) MediaTransport {
+	p := properties(raw)
+
+	t := MediaTransport{Path: string(path)}
+	if v, ok := p.objectPathValue("Device"); ok {
+		t.Device = addressFromPath(v)
+	}
+	t.UUID, _ = p.stringValue("UUID")
+	if v, ok := p.stringValue("State"); ok {
+		t.State = MediaTransportState(v)
+	}
+	t.Volume, t.HasVolume = p.uint16Value("Volume")
+
+	codec, _ := p.byteValue("Codec")
+	config, _ := p.bytesValue("Configuration")
+	t.Codec, t.CodecErr = DecodeTransportCodec(t.UUID, codec, config)
+	return t
+}
+
+// Transports returns the transports of the device, sorted by path.
+func (b *linuxAdapter) Transports(ctx context.Context, addr string) ([]MediaTransport, error) {
+	dev, err := b.resolveDevice(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transports of device at addr %s: %w", addr, err)
+	}
+
+	objs, err := managedObjects(ctx, b.conn, b.destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transports of device at addr %s: %w", addr, err)
+	}
+
+	var transports []MediaTransport
+	for path, ifaces := range objs {
+		props, ok := ifaces[mediaTransportInterface]
+		if !ok {
+			continue
+		}
+		if v, ok := properties(props).objectPathValue("Device"); !ok || string(v) != dev.Path() {
+			continue
+		}
+		transports = append(transports, transportFromProperties(path, props))
+	}
+
+	slices.SortFunc(transports, func(a, b MediaTransport) int { return strings.Compare(a.Path, b.Path) })
+	return transports, nil
+}
+
+// SetVolume sets the volume of the main transport of the device. BlueZ
+// sends it to the device over AVRCP.
+func (b *linuxAdapter) SetVolume(ctx context.Context, addr string, volume uint16) error {
+	if volume > MaxVolume {
+		volume = MaxVolume
+	}
+
+	transports, err := b.Transports(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to set volume of device at addr %s: %w", addr, err)
+	}
+	t, ok := MainTransport(transports)
+	if !ok {
+		return fmt.Errorf("failed to set volume of device at addr %s: %w", addr, ErrNoMediaTransport)
+	}
+
+	obj := b.conn.Object(b.destination, dbus.ObjectPath(t.Path))
+	err = call(ctx, obj, propertiesInterface+".Set", mediaTransportInterface, "Volume", dbus.MakeVariant(volume))
+	if err != nil {
+		return fmt.Errorf("failed to set volume of device at addr %s: %w", addr, err)
+	}
+	return nil
+}
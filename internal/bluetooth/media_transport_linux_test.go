@@ -0,0 +1,39 @@
+package bluetooth
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMediaTransportFromProperties(t *testing.T) {
+	transport := transportFromProperties("/org/bluez/hci0/dev_00_00_00_00_00_01/sep1/fd0", map[string]dbus.Variant{
+		"Device":        dbus.MakeVariant(dbus.ObjectPath("/org/bluez/hci0/dev_00_00_00_00_00_01")),
+		"UUID":          dbus.MakeVariant(UUIDAudioSink),
+		"State":         dbus.MakeVariant("active"),
+		"Codec":         dbus.MakeVariant(CodecSBC),
+		"Configuration": dbus.MakeVariant([]byte{0x21, 0x15, 0x02, 0x35}),
+		"Volume":        dbus.MakeVariant(uint16(64)),
+	})
+
+	if transport.Device != "00:00:00:00:00:01" || transport.State != MediaTransportActive || transport.Codec.Name != "SBC" {
+		t.Errorf("unexpected transport: %+v", transport)
+	}
+	if !transport.HasVolume || transport.Volume != 64 {
+		t.Errorf("expected volume to be 64, got: %d (has volume %t)", transport.Volume, transport.HasVolume)
+	}
+}
+
+func TestMainMediaTransport(t *testing.T) {
+	transports := []MediaTransport{
+		{Path: "fd0", State: MediaTransportActive},
+		{Path: "fd1", State: MediaTransportIdle, HasVolume: true},
+		{Path: "fd2", State: MediaTransportPending, HasVolume: true},
+	}
+	if got, ok := MainTransport(transports); !ok || got.Path != "fd2" {
+		t.Errorf("expected the pending transport with a volume, got: %+v (found %t)", got, ok)
+	}
+	if _, ok := MainTransport(transports[:1]); ok {
+		t.Error("expected no transport without a volume")
+	}
+}
@@ -1,42 +1,152 @@
 package bluetooth
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/godbus/dbus/v5"
 )
 
 // mockDbusConn is a fake dbusConn used only for tests.
 type mockDbusConn struct {
-	objects map[string]mockBusObject
+	objects map[string]*mockBusObject
 	closed  bool
+
+	mu       sync.Mutex
+	matches  int
+	signals  []chan<- *dbus.Signal
+	exported map[string]any
+	emitted  []*dbus.Signal
 }
 
 func (m *mockDbusConn) Object(destination string, path dbus.ObjectPath) dbusObject {
+	if obj, ok := m.objects[string(path)]; ok {
+		return obj
+	}
 	// Return a no-op object if not found (can be improved to simulate errors)
 	return &mockBusObject{}
 }
 
+func (m *mockDbusConn) AddMatchSignal(options ...dbus.MatchOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matches++
+	return nil
+}
+
+func (m *mockDbusConn) RemoveMatchSignal(options ...dbus.MatchOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matches--
+	return nil
+}
+
+func (m *mockDbusConn) Signal(ch chan<- *dbus.Signal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signals = append(m.signals, ch)
+}
+
+func (m *mockDbusConn) RemoveSignal(ch chan<- *dbus.Signal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range m.signals {
+		if c == ch {
+			m.signals = append(m.signals[:i], m.signals[i+1:]...)
+			return
+		}
+	}
+}
+
+// emit delivers a fake signal to every channel registered through Signal.
+func (m *mockDbusConn) emit(sig *dbus.Signal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.signals {
+		ch <- sig
+	}
+}
+
+func (m *mockDbusConn) Export(v any, path dbus.ObjectPath, iface string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.exported == nil {
+		m.exported = make(map[string]any)
+	}
+	key := string(path) + " " + iface
+	if v == nil {
+		delete(m.exported, key)
+		return nil
+	}
+	m.exported[key] = v
+	return nil
+}
+
+// Emit records the signals sent from our own objects.
+func (m *mockDbusConn) Emit(path dbus.ObjectPath, name string, values ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emitted = append(m.emitted, &dbus.Signal{Path: path, Name: name, Body: values})
+	return nil
+}
+
+// Close closes the channels registered through Signal, like godbus does.
 func (m *mockDbusConn) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.closed = true
+	for _, ch := range m.signals {
+		close(ch)
+	}
+	m.signals = nil
 	return nil
 }
 
 // mockBusObject simulates a dbus.BusObject for testing.
 type mockBusObject struct {
 	CallHistory []string
+	// Replies holds the body returned by each method, keyed by the full
+	// method name (e.g. "org.bluez.Adapter1.StartDiscovery").
+	Replies map[string][]any
+	// Errors holds the error returned by each method, keyed like Replies.
+	Errors map[string]error
+	// OnCall, if set, answers the calls it returns a reply for, before
+	// Replies and Errors are looked at.
+	OnCall func(method string, args ...any) *dbus.Call
 }
 
 func (m *mockBusObject) Call(method string, flags dbus.Flags, args ...any) *dbus.Call {
 	m.CallHistory = append(m.CallHistory, method)
+	if m.OnCall != nil {
+		if call := m.OnCall(method, args...); call != nil {
+			return call
+		}
+	}
 	// If the args contain the string "error", simulate an error
 	if len(args) > 0 && args[0] == "error" {
 		return &dbus.Call{Err: fmt.Errorf("simulated error")}
 	}
+	if err, ok := m.Errors[method]; ok {
+		return &dbus.Call{Err: err}
+	}
+	if body, ok := m.Replies[method]; ok {
+		return &dbus.Call{Body: body}
+	}
 	// Fake successful call
 	return &dbus.Call{}
 }
 
+// CallWithContext behaves like Call, but fails with the error of the context
+// if it's already done.
+func (m *mockBusObject) CallWithContext(ctx context.Context, method string, flags dbus.Flags, args ...any) *dbus.Call {
+	if err := ctx.Err(); err != nil {
+		m.CallHistory = append(m.CallHistory, method)
+		return &dbus.Call{Err: err}
+	}
+	return m.Call(method, flags, args...)
+}
+
 // NewMockConnection is a factory that returns a mock connection for testing.
 func NewMockConnection() (dbusConn, error) {
 	return &mockDbusConn{}, nil
@@ -0,0 +1,165 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrObexUnavailable is returned when the OBEX daemon of BlueZ (obexd) can't
+// be reached. It runs on the session bus, usually started on demand, so it's
+// missing on headless setups without a user session.
+var ErrObexUnavailable = errors.New("OBEX daemon not available")
+
+// ErrTransferFailed is returned when a transfer ends with an error status.
+// obexd doesn't tell why, the details (if any) are in its logs.
+var ErrTransferFailed = errors.New("transfer failed")
+
+// Obex talks to devices over OBEX, which is how files are exchanged with
+// phones and computers. Unlike the rest of the package, it goes through
+// obexd, which lives on the session bus.
+type Obex interface {
+	// SendFile pushes a file to the device through the Object Push
+	// profile, like sharing it from a phone. The device usually asks its
+	// user to accept it. The transfer is canceled once the context is done.
+	SendFile(ctx context.Context, address, file string) (*Transfer, error)
+	// FileTransfer opens a session with the File Transfer profile of the
+	// device, to browse its file system. The context only bounds the
+	// connection.
+	FileTransfer(ctx context.Context, address string) (FileTransfer, error)
+	// PhonebookAccess opens a session with the Phone Book Access profile
+	// of the device, to read its contacts and call history. The phone
+	// usually asks its user to allow it. The context only bounds the
+	// connection.
+	PhonebookAccess(ctx context.Context, address string) (PhonebookAccess, error)
+	Close() error
+}
+
+// WithSource makes an Obex client connect to devices from the adapter with the
+// given address, usually the one given to NewAdapter. obexd picks one on its
+// own otherwise, which isn't always the one the device is paired with.
+func WithSource(address string) Option {
+	return func(b *adapterBase) {
+		b.source = address
+	}
+}
+
+// ObexSession is a connection to one of the OBEX services of a device. It
+// lasts until it's closed, or until the device goes away.
+type ObexSession interface {
+	Address() string
+	// Target is the service the session talks to, e.g. "ftp".
+	Target() string
+	Close(ctx context.Context) error
+}
+
+// TransferStatus is the state of an OBEX transfer.
+type TransferStatus string
+
+const (
+	TransferQueued    TransferStatus = "queued"
+	TransferActive    TransferStatus = "active"
+	TransferSuspended TransferStatus = "suspended"
+	TransferComplete  TransferStatus = "complete"
+	TransferError     TransferStatus = "error"
+)
+
+// Done tells whether the transfer is over, for good or bad.
+func (s TransferStatus) Done() bool {
+	return s == TransferComplete || s == TransferError
+}
+
+// TransferProgress is a snapshot of a transfer.
+type TransferProgress struct {
+	Status TransferStatus
+	// Transferred is how many bytes were sent or received so far.
+	Transferred uint64
+	// Size is the size of the file, or 0 if the device didn't tell (which
+	// happens when pulling objects).
+	Size uint64
+}
+
+// Fraction returns how much of the file was transferred, between 0 and 1. It's
+// 0 while the size is unknown, unless the transfer is complete.
+func (p TransferProgress) Fraction() float64 {
+	if p.Status == TransferComplete {
+		return 1
+	}
+	if p.Size == 0 {
+		return 0
+	}
+	return min(float64(p.Transferred)/float64(p.Size), 1)
+}
+
+// Transfer is an OBEX transfer started by the Obex methods. Its progress must
+// be drained until the channel is closed, which happens once it ends.
+type Transfer struct {
+	// Path is the object path of the transfer in obexd.
+	Path string
+	// Name is the name of the object as the device sees it, and Filename
+	// the local file it's read from or written to.
+	Name     string
+	Filename string
+
+	progress chan TransferProgress
+	err      error
+}
+
+func newTransfer(path, name, filename string) *Transfer {
+	return &Transfer{
+		Path:     path,
+		Name:     name,
+		Filename: filename,
+		progress: make(chan TransferProgress, 32),
+	}
+}
+
+// Progress streams the progress of the transfer. The channel is closed once
+// the transfer is over, after which Err tells how it went.
+func (t *Transfer) Progress() <-chan TransferProgress {
+	return t.progress
+}
+
+// Err returns why the transfer didn't complete, or nil if it did. It's only
+// meaningful once the progress channel is closed.
+func (t *Transfer) Err() error {
+	return t.err
+}
+
+// Wait drains the progress of the transfer and returns how it ended.
+func (t *Transfer) Wait() error {
+	for range t.progress {
+	}
+	return t.err
+}
+
+// FileTransfer browses the file system of a device through the OBEX File
+// Transfer profile. Like a shell, it has a current folder, which the names
+// given to its methods are relative to.
+type FileTransfer interface {
+	ObexSession
+	// Folder returns the current folder, as an absolute path.
+	Folder() string
+	ListFolder(ctx context.Context) ([]RemoteFile, error)
+	// ChangeFolder enters a folder of the current one, or leaves it with
+	// "..".
+	ChangeFolder(ctx context.Context, name string) error
+	CreateFolder(ctx context.Context, name string) error
+	// Delete removes a file, or an empty folder.
+	Delete(ctx context.Context, name string) error
+	// GetFile copies a file of the current folder to a local one, and
+	// PutFile the other way around. The transfer is canceled once the
+	// context is done.
+	GetFile(ctx context.Context, name, file string) (*Transfer, error)
+	PutFile(ctx context.Context, file, name string) (*Transfer, error)
+}
+
+// RemoteFile is an entry of a folder of a device.
+type RemoteFile struct {
+	Name   string
+	Folder bool
+	// Size is 0 for folders, and when the device doesn't tell.
+	Size uint64
+	// Modified is the zero time when the device doesn't tell.
+	Modified time.Time
+}
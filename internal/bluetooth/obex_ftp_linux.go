@@ -0,0 +1,146 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const obexFileTransferInterface = "org.bluez.obex.FileTransfer1"
+
+// obexTimeLayouts are the forms of the Modified property of the entries of
+// folders, which is UTC when it ends in Z, and local time otherwise.
+var obexTimeLayouts = []string{"20060102T150405Z", "20060102T150405"}
+
+// fileTransferSession is the Linux implementation of FileTransfer.
+type fileTransferSession struct {
+	*obexSession
+	// mu guards folder, the names of the folders from the root to the
+	// current one. obexd keeps track of it too, but doesn't tell.
+	mu     sync.Mutex
+	folder []string
+}
+
+// FileTransfer creates an FTP session with the device. It starts at the root
+// folder.
+func (o *linuxObex) FileTransfer(ctx context.Context, address string) (FileTransfer, error) {
+	session, err := o.newSession(ctx, address, "ftp")
+	if err != nil {
+		return nil, err
+	}
+	return &fileTransferSession{obexSession: session}, nil
+}
+
+func (s *fileTransferSession) Folder() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return "/" + strings.Join(s.folder, "/")
+}
+
+func (s *fileTransferSession) ListFolder(ctx context.Context) ([]RemoteFile, error) {
+	var entries []map[string]dbus.Variant
+	if err := s.call(ctx, obexFileTransferInterface+".ListFolder").Store(&entries); err != nil {
+		return nil, fmt.Errorf("failed to list folder %s: %w", s.Folder(), err)
+	}
+
+	files := make([]RemoteFile, 0, len(entries))
+	for _, props := range entries {
+		files = append(files, remoteFileFromProperties(props))
+	}
+	return files, nil
+}
+
+// remoteFileFromProperties builds a RemoteFile out of an entry returned by
+// ListFolder.
+func remoteFileFromProperties(props map[string]dbus.Variant) RemoteFile {
+	p := properties(props)
+	var f RemoteFile
+	f.Name, _ = p.stringValue("Name")
+	kind, _ := p.stringValue("Type")
+	f.Folder = kind == "folder"
+	f.Size, _ = p.uint64Value("Size")
+	if v, ok := p.stringValue("Modified"); ok {
+		for _, layout := range obexTimeLayouts {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				f.Modified = t
+				break
+			}
+		}
+	}
+	return f
+}
+
+// ChangeFolder only takes a single name, so that we know where we end up.
+func (s *fileTransferSession) ChangeFolder(ctx context.Context, name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid folder name %q", name)
+	}
+	if err := s.call(ctx, obexFileTransferInterface+".ChangeFolder", name).Err; err != nil {
+		return fmt.Errorf("failed to change to folder %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == ".." {
+		s.folder = s.folder[:max(len(s.folder)-1, 0)]
+	} else {
+		s.folder = append(s.folder, name)
+	}
+	return nil
+}
+
+// CreateFolder leaves us in the current folder, even though obexd enters the
+// new one, like the SETPATH request it sends does.
+func (s *fileTransferSession) CreateFolder(ctx context.Context, name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid folder name %q", name)
+	}
+	if err := s.call(ctx, obexFileTransferInterface+".CreateFolder", name).Err; err != nil {
+		return fmt.Errorf("failed to create folder %s: %w", name, err)
+	}
+	if err := s.call(context.WithoutCancel(ctx), obexFileTransferInterface+".ChangeFolder", "..").Err; err != nil {
+		return fmt.Errorf("failed to leave new folder %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *fileTransferSession) Delete(ctx context.Context, name string) error {
+	if err := s.call(ctx, obexFileTransferInterface+".Delete", name).Err; err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *fileTransferSession) GetFile(ctx context.Context, name, file string) (*Transfer, error) {
+	// obexd writes the file itself, from a working directory of its own.
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	t, err := s.transfer(ctx, obexFileTransferInterface+".GetFile", nil, file, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func (s *fileTransferSession) PutFile(ctx context.Context, file, name string) (*Transfer, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put %s: %w", file, err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("failed to put %s: %w", file, err)
+	}
+	t, err := s.transfer(ctx, obexFileTransferInterface+".PutFile", nil, file, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put %s: %w", file, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,111 @@
+package bluetooth
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestFileTransfer(t *testing.T) {
+	o, conn, _ := newTestObex(t)
+	session := conn.objects[testSessionPath]
+	session.Replies[obexFileTransferInterface+".ListFolder"] = []any{[]map[string]dbus.Variant{
+		{"Name": dbus.MakeVariant("DCIM"), "Type": dbus.MakeVariant("folder")},
+		{
+			"Name":     dbus.MakeVariant("notes.txt"),
+			"Type":     dbus.MakeVariant("file"),
+			"Size":     dbus.MakeVariant(uint64(42)),
+			"Modified": dbus.MakeVariant("20261018T093000Z"),
+		},
+	}}
+
+	ftp, err := o.FileTransfer(t.Context(), "00:00:00:00:00:01")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	files, err := ftp.ListFolder(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := []RemoteFile{
+		{Name: "DCIM", Folder: true},
+		{Name: "notes.txt", Size: 42, Modified: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, got: %v", expected, files)
+	}
+	for i := range expected {
+		if files[i].Name != expected[i].Name || files[i].Folder != expected[i].Folder ||
+			files[i].Size != expected[i].Size || !files[i].Modified.Equal(expected[i].Modified) {
+			t.Errorf("expected file %v, got: %v", expected[i], files[i])
+		}
+	}
+
+	var folders []string
+	session.OnCall = func(method string, args ...any) *dbus.Call {
+		if method == obexFileTransferInterface+".ChangeFolder" {
+			folders = append(folders, args[0].(string))
+		}
+		return nil
+	}
+	for _, name := range []string{"DCIM", "Camera", ".."} {
+		if err := ftp.ChangeFolder(t.Context(), name); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	if ftp.Folder() != "/DCIM" {
+		t.Errorf("expected to be in /DCIM, got: %s", ftp.Folder())
+	}
+	if err := ftp.ChangeFolder(t.Context(), "DCIM/Camera"); err == nil {
+		t.Error("expected paths to be refused")
+	}
+
+	// obexd enters the folders it creates, so we leave them right away.
+	if err := ftp.CreateFolder(t.Context(), "Backup"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(folders, []string{"DCIM", "Camera", "..", ".."}) {
+		t.Errorf("unexpected folder changes: %v", folders)
+	}
+	if ftp.Folder() != "/DCIM" {
+		t.Errorf("expected to stay in /DCIM, got: %s", ftp.Folder())
+	}
+
+	if err := ftp.Close(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if history := conn.objects[obexClientPath].CallHistory; !slices.Contains(history, obexClientInterface+".RemoveSession") {
+		t.Errorf("expected the session to be removed, got: %v", history)
+	}
+}
+
+func TestFileTransferGetFile(t *testing.T) {
+	o, conn, _ := newTestObex(t)
+	session := conn.objects[testSessionPath]
+	session.Replies[obexFileTransferInterface+".GetFile"] = []any{
+		dbus.ObjectPath(testTransferPath),
+		map[string]dbus.Variant{"Status": dbus.MakeVariant("queued"), "Name": dbus.MakeVariant("notes.txt")},
+	}
+
+	ftp, err := o.FileTransfer(t.Context(), "00:00:00:00:00:01")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	transfer, err := ftp.GetFile(t.Context(), "notes.txt", "notes.txt")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	<-transfer.Progress()
+	conn.emit(transferChangedSignal(map[string]dbus.Variant{"Status": dbus.MakeVariant("complete")}))
+	if err := transfer.Wait(); err != nil {
+		t.Errorf("expected the transfer to complete, got: %v", err)
+	}
+
+	// The session outlives its transfers.
+	if history := conn.objects[obexClientPath].CallHistory; slices.Contains(history, obexClientInterface+".RemoveSession") {
+		t.Errorf("expected the session to stay, got: %v", history)
+	}
+}
@@ -0,0 +1,273 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// These are the names used by obexd, which can be listed with
+// busctl --user tree org.bluez.obex.
+const (
+	obexDestination = "org.bluez.obex"
+	// obexClientPath is the object of the client, which creates the
+	// sessions. Each session and transfer gets an object below it.
+	obexClientPath          = "/org/bluez/obex"
+	obexClientInterface     = "org.bluez.obex.Client1"
+	obexObjectPushInterface = "org.bluez.obex.ObjectPush1"
+	obexTransferInterface   = "org.bluez.obex.Transfer1"
+)
+
+// linuxObex is the Linux implementation of the Obex interface. It shares the
+// options of the adapters, but not their connection: obexd is on the session
+// bus.
+type linuxObex struct {
+	adapterBase
+	conn   dbusConn
+	client dbusObject
+}
+
+// NewObex creates an OBEX client. The factory should connect to the session
+// bus (see NewSessionBusConnection).
+func NewObex(destination string, dbusConnFact DbusConnectionFactory, opts ...Option) (Obex, error) {
+	conn, err := dbusConnFact()
+	if err != nil {
+		return nil, err
+	}
+
+	dest := destination
+	if dest == "" {
+		dest = obexDestination
+	}
+
+	o := &linuxObex{
+		conn:   conn,
+		client: conn.Object(dest, obexClientPath),
+	}
+	o.init(dest, obexClientPath, opts...)
+	return o, nil
+}
+
+func (o *linuxObex) Close() error {
+	return o.conn.Close()
+}
+
+// obexError is mapError, except that it points at obexd when nothing answers
+// on the session bus, which is the most common reason for OBEX to fail.
+func obexError(err error) error {
+	var dbusErr dbus.Error
+	var dbusErrPtr *dbus.Error
+	switch {
+	case errors.As(err, &dbusErr):
+	case errors.As(err, &dbusErrPtr):
+		dbusErr = *dbusErrPtr
+	}
+	if dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
+		return fmt.Errorf("%w (is obexd running?): %w", ErrObexUnavailable, err)
+	}
+	return mapError(err)
+}
+
+// obexSession is a session with one of the OBEX services of a device, which
+// the calls and transfers of that service go through.
+type obexSession struct {
+	obex    *linuxObex
+	obj     dbusObject
+	path    dbus.ObjectPath
+	address string
+	target  string
+}
+
+// newSession connects to the OBEX service of the device that target names
+// (opp, ftp, pbap...). This is when the device is actually connected to, so
+// it can take a while.
+func (o *linuxObex) newSession(ctx context.Context, address, target string) (*obexSession, error) {
+	var path dbus.ObjectPath
+	args := map[string]dbus.Variant{"Target": dbus.MakeVariant(target)}
+	if o.source != "" {
+		args["Source"] = dbus.MakeVariant(o.source)
+	}
+	err := obexError(o.client.CallWithContext(ctx, obexClientInterface+".CreateSession", 0, address, args).Store(&path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s session with %s: %w", target, address, err)
+	}
+	o.logger.Debug("created OBEX session", "address", address, "target", target, "session", path)
+
+	return &obexSession{
+		obex:    o,
+		obj:     o.conn.Object(o.destination, path),
+		path:    path,
+		address: address,
+		target:  target,
+	}, nil
+}
+
+func (s *obexSession) Address() string {
+	return s.address
+}
+
+func (s *obexSession) Target() string {
+	return s.target
+}
+
+// call calls a method of the session, translating the errors of obexd.
+func (s *obexSession) call(ctx context.Context, method string, args ...any) *dbus.Call {
+	c := s.obj.CallWithContext(ctx, method, 0, args...)
+	c.Err = obexError(c.Err)
+	return c
+}
+
+// Close disconnects the session. It's also called when giving up, so it
+// doesn't care about the context being done.
+func (s *obexSession) Close(ctx context.Context) error {
+	err := obexError(s.obex.client.CallWithContext(context.WithoutCancel(ctx), obexClientInterface+".RemoveSession", 0, s.path).Err)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s session with %s: %w", s.target, s.address, err)
+	}
+	return nil
+}
+
+// transfer calls a method of the session that starts a transfer (e.g.
+// SendFile), and follows the transfer until it's over. release, if not nil,
+// is called then, or right away if the transfer couldn't be started.
+func (s *obexSession) transfer(ctx context.Context, method string, release func(), args ...any) (*Transfer, error) {
+	if release == nil {
+		release = func() {}
+	}
+	o := s.obex
+
+	// We subscribe first, so that a transfer that ends right away is not
+	// missed.
+	signals, unsubscribe, err := subscribe(o.conn, [][]dbus.MatchOption{{
+		dbus.WithMatchSender(o.destination),
+		dbus.WithMatchInterface(propertiesInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(s.path),
+	}})
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	var path dbus.ObjectPath
+	var props map[string]dbus.Variant
+	if err := s.call(ctx, method, args...).Store(&path, &props); err != nil {
+		unsubscribe()
+		release()
+		return nil, err
+	}
+
+	p := properties(props)
+	name, _ := p.stringValue("Name")
+	filename, _ := p.stringValue("Filename")
+	t := newTransfer(string(path), name, filename)
+	progress := updateTransferProgress(TransferProgress{Status: TransferQueued}, p)
+
+	go func() {
+		defer close(t.progress)
+		defer release()
+		defer unsubscribe()
+
+		for {
+			select {
+			case t.progress <- progress:
+			case <-ctx.Done():
+			}
+			if progress.Status.Done() {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				err := call(context.WithoutCancel(ctx), o.conn.Object(o.destination, path), obexTransferInterface+".Cancel")
+				if err != nil {
+					o.logger.Warn("failed to cancel transfer", "path", path, "error", err)
+				}
+				t.err = ctx.Err()
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					// The connection to obexd was closed under us.
+					t.err = fmt.Errorf("%w: connection closed during %s", ErrObexUnavailable, t.Name)
+					return
+				}
+				changed, ok := transferChanges(path, sig)
+				if !ok {
+					continue
+				}
+				progress = updateTransferProgress(progress, changed)
+			}
+		}
+
+		if progress.Status == TransferError {
+			t.err = fmt.Errorf("%w: %s", ErrTransferFailed, t.Name)
+		}
+		o.logger.Debug("transfer over", "path", path, "status", progress.Status, "transferred", progress.Transferred)
+	}()
+
+	return t, nil
+}
+
+// transferChanges returns the properties changed by a PropertiesChanged
+// signal of the transfer at path.
+func transferChanges(path dbus.ObjectPath, sig *dbus.Signal) (properties, bool) {
+	if sig.Name != propertiesChangedSignal || sig.Path != path {
+		return nil, false
+	}
+
+	var iface string
+	var changed map[string]dbus.Variant
+	var invalidated []string
+	if err := dbus.Store(sig.Body, &iface, &changed, &invalidated); err != nil || iface != obexTransferInterface {
+		return nil, false
+	}
+	return properties(changed), true
+}
+
+// updateTransferProgress applies the Transfer1 properties in p to progress.
+func updateTransferProgress(progress TransferProgress, p properties) TransferProgress {
+	if v, ok := p.stringValue("Status"); ok {
+		progress.Status = TransferStatus(v)
+	}
+	if v, ok := p.uint64Value("Transferred"); ok {
+		progress.Transferred = v
+	}
+	if v, ok := p.uint64Value("Size"); ok {
+		progress.Size = v
+	}
+	return progress
+}
+
+// SendFile creates an Object Push session with the device, sends the file
+// through it, and removes the session once the transfer is over.
+func (o *linuxObex) SendFile(ctx context.Context, address, file string) (*Transfer, error) {
+	// obexd opens the file itself, from a working directory of its own.
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", file, err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", file, err)
+	}
+
+	session, err := o.newSession(ctx, address, "opp")
+	if err != nil {
+		return nil, err
+	}
+
+	// The session only serves this transfer.
+	release := func() {
+		if err := session.Close(ctx); err != nil {
+			o.logger.Warn("failed to close OBEX session", "error", err)
+		}
+	}
+	t, err := session.transfer(ctx, obexObjectPushInterface+".SendFile", release, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", file, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,211 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	testSessionPath  = "/org/bluez/obex/client/session0"
+	testTransferPath = testSessionPath + "/transfer0"
+)
+
+// newTestObex returns an OBEX client whose sessions push to a transfer
+// object, and a file to send.
+func newTestObex(t *testing.T) (*linuxObex, *mockDbusConn, string) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := &mockDbusConn{
+		objects: map[string]*mockBusObject{
+			obexClientPath: {
+				Replies: map[string][]any{
+					obexClientInterface + ".CreateSession": {dbus.ObjectPath(testSessionPath)},
+				},
+			},
+			testSessionPath: {
+				Replies: map[string][]any{
+					obexObjectPushInterface + ".SendFile": {
+						dbus.ObjectPath(testTransferPath),
+						map[string]dbus.Variant{
+							"Status":   dbus.MakeVariant("queued"),
+							"Name":     dbus.MakeVariant("log.txt"),
+							"Filename": dbus.MakeVariant(file),
+							"Size":     dbus.MakeVariant(uint64(5)),
+						},
+					},
+				},
+			},
+			testTransferPath: {},
+		},
+	}
+
+	o, err := NewObex("", func() (dbusConn, error) { return conn, nil })
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return o.(*linuxObex), conn, file
+}
+
+func transferChangedSignal(props map[string]dbus.Variant) *dbus.Signal {
+	return &dbus.Signal{
+		Path: testTransferPath,
+		Name: propertiesChangedSignal,
+		Body: []any{obexTransferInterface, props, []string{}},
+	}
+}
+
+func TestSendFile(t *testing.T) {
+	o, conn, file := newTestObex(t)
+
+	transfer, err := o.SendFile(t.Context(), "00:00:00:00:00:01", file)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if transfer.Path != testTransferPath || transfer.Name != "log.txt" || transfer.Filename != file {
+		t.Errorf("unexpected transfer: %+v", transfer)
+	}
+
+	if p := <-transfer.Progress(); p != (TransferProgress{Status: TransferQueued, Size: 5}) {
+		t.Errorf("expected the transfer to be queued, got: %+v", p)
+	}
+	conn.emit(transferChangedSignal(map[string]dbus.Variant{"Status": dbus.MakeVariant("active"), "Transferred": dbus.MakeVariant(uint64(2))}))
+	if p := <-transfer.Progress(); p.Status != TransferActive || p.Fraction() != 0.4 {
+		t.Errorf("expected the transfer to be 40%% done, got: %+v", p)
+	}
+	conn.emit(transferChangedSignal(map[string]dbus.Variant{"Status": dbus.MakeVariant("complete")}))
+
+	if err := transfer.Wait(); err != nil {
+		t.Errorf("expected the transfer to complete, got: %v", err)
+	}
+	client := conn.objects[obexClientPath]
+	expected := []string{obexClientInterface + ".CreateSession", obexClientInterface + ".RemoveSession"}
+	if !slices.Equal(client.CallHistory, expected) {
+		t.Errorf("expected calls %v, got: %v", expected, client.CallHistory)
+	}
+	if conn.matches != 0 || len(conn.signals) != 0 {
+		t.Errorf("expected to unsubscribe, got %d matches and %d channels", conn.matches, len(conn.signals))
+	}
+}
+
+func TestSendFileFailed(t *testing.T) {
+	o, conn, file := newTestObex(t)
+
+	transfer, err := o.SendFile(t.Context(), "00:00:00:00:00:01", file)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	<-transfer.Progress()
+	conn.emit(transferChangedSignal(map[string]dbus.Variant{"Status": dbus.MakeVariant("error")}))
+
+	if err := transfer.Wait(); !errors.Is(err, ErrTransferFailed) {
+		t.Errorf("expected the transfer to fail, got: %v", err)
+	}
+}
+
+func TestSendFileCanceled(t *testing.T) {
+	o, conn, file := newTestObex(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	transfer, err := o.SendFile(ctx, "00:00:00:00:00:01", file)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cancel()
+
+	if err := transfer.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the transfer to be canceled, got: %v", err)
+	}
+	if history := conn.objects[testTransferPath].CallHistory; !slices.Equal(history, []string{obexTransferInterface + ".Cancel"}) {
+		t.Errorf("expected the transfer to be canceled in obexd, got: %v", history)
+	}
+	if history := conn.objects[obexClientPath].CallHistory; !slices.Contains(history, obexClientInterface+".RemoveSession") {
+		t.Errorf("expected the session to be removed, got: %v", history)
+	}
+}
+
+// Closing the connection closes the signal channels, which must end the
+// transfer rather than spin on them.
+func TestSendFileConnectionClosed(t *testing.T) {
+	o, _, file := newTestObex(t)
+
+	transfer, err := o.SendFile(t.Context(), "00:00:00:00:00:01", file)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	<-transfer.Progress()
+	if err := o.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if err := transfer.Wait(); !errors.Is(err, ErrObexUnavailable) {
+		t.Errorf("expected obexd to be unavailable, got: %v", err)
+	}
+}
+
+func TestSendFileErrors(t *testing.T) {
+	o, conn, file := newTestObex(t)
+
+	if _, err := o.SendFile(t.Context(), "00:00:00:00:00:01", filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing file to be refused, got: %v", err)
+	}
+
+	conn.objects[obexClientPath].Errors = map[string]error{
+		obexClientInterface + ".CreateSession": dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"},
+	}
+	if _, err := o.SendFile(t.Context(), "00:00:00:00:00:01", file); !errors.Is(err, ErrObexUnavailable) {
+		t.Errorf("expected obexd to be unavailable, got: %v", err)
+	}
+
+	conn.objects[obexClientPath].Errors = nil
+	conn.objects[testSessionPath].Errors = map[string]error{
+		obexObjectPushInterface + ".SendFile": dbus.Error{Name: "org.bluez.obex.Error.Forbidden"},
+	}
+	if _, err := o.SendFile(t.Context(), "00:00:00:00:00:01", file); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("expected the push to be refused, got: %v", err)
+	}
+	if history := conn.objects[obexClientPath].CallHistory; history[len(history)-1] != obexClientInterface+".RemoveSession" {
+		t.Errorf("expected the session to be removed, got: %v", history)
+	}
+	if conn.matches != 0 {
+		t.Errorf("expected to unsubscribe, got %d matches", conn.matches)
+	}
+}
+
+func TestSendFileSource(t *testing.T) {
+	o, conn, file := newTestObex(t)
+	WithSource("AA:BB:CC:DD:EE:00")(&o.adapterBase)
+
+	var args map[string]dbus.Variant
+	conn.objects[obexClientPath].OnCall = func(method string, a ...any) *dbus.Call {
+		if method == obexClientInterface+".CreateSession" {
+			args = a[1].(map[string]dbus.Variant)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	transfer, err := o.SendFile(ctx, "00:00:00:00:00:01", file)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cancel()
+	transfer.Wait()
+
+	if source, _ := args["Source"].Value().(string); source != "AA:BB:CC:DD:EE:00" {
+		t.Errorf("expected the session to be created from AA:BB:CC:DD:EE:00, got: %v", args)
+	}
+	if target, _ := args["Target"].Value().(string); target != "opp" {
+		t.Errorf("expected an opp session, got: %v", args)
+	}
+}
@@ -0,0 +1,129 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Phonebook is one of the objects a phone serves through PBAP. Call
+// histories are phonebooks too, whose vCards carry the time of the calls.
+type Phonebook string
+
+const (
+	PhonebookContacts  Phonebook = "pb"
+	PhonebookIncoming  Phonebook = "ich"
+	PhonebookOutgoing  Phonebook = "och"
+	PhonebookMissed    Phonebook = "mch"
+	PhonebookCombined  Phonebook = "cch"
+	PhonebookSpeedDial Phonebook = "spd"
+	PhonebookFavorites Phonebook = "fav"
+)
+
+// phonebookNames are the names of the phonebooks, in the order they are
+// exported in.
+var phonebookNames = []struct {
+	phonebook Phonebook
+	name      string
+}{
+	{PhonebookContacts, "contacts"},
+	{PhonebookIncoming, "incoming calls"},
+	{PhonebookOutgoing, "outgoing calls"},
+	{PhonebookMissed, "missed calls"},
+	{PhonebookFavorites, "favourites"},
+	{PhonebookCombined, "call history"},
+	{PhonebookSpeedDial, "speed dial"},
+}
+
+// ExportedPhonebooks returns the phonebooks worth backing up: the contacts,
+// the incoming, outgoing and missed calls, and the favourites. The combined
+// call history is left out since it repeats the other three.
+func ExportedPhonebooks() []Phonebook {
+	return []Phonebook{PhonebookContacts, PhonebookIncoming, PhonebookOutgoing, PhonebookMissed, PhonebookFavorites}
+}
+
+// ParsePhonebook returns the phonebook given by its PBAP name (e.g. ich) or
+// by its name (e.g. "incoming calls", or incoming-calls).
+func ParsePhonebook(s string) (Phonebook, error) {
+	s = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", " ")
+	for _, p := range phonebookNames {
+		if s == string(p.phonebook) || s == p.name {
+			return p.phonebook, nil
+		}
+	}
+	return "", fmt.Errorf("unknown phonebook %q", s)
+}
+
+// Name returns a readable name for the phonebook.
+func (p Phonebook) Name() string {
+	for _, n := range phonebookNames {
+		if n.phonebook == p {
+			return n.name
+		}
+	}
+	return string(p)
+}
+
+// FileName returns the name of the vCard file the phonebook is exported to,
+// e.g. incoming-calls.vcf.
+func (p Phonebook) FileName() string {
+	return strings.ReplaceAll(p.Name(), " ", "-") + ".vcf"
+}
+
+// Where the phonebooks are read from. Phones without a SIM card only have
+// the internal ones.
+const (
+	PhonebookInternal = "int"
+	PhonebookSIM      = "sim1"
+)
+
+// VCardFormat is the version of the vCards pulled from the phone.
+type VCardFormat string
+
+const (
+	VCard21 VCardFormat = "vcard21"
+	VCard30 VCardFormat = "vcard30"
+)
+
+// ParseVCardFormat takes the version of the format, e.g. 2.1 or 3.0.
+func ParseVCardFormat(s string) (VCardFormat, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), "vcard") {
+	case "2.1", "21":
+		return VCard21, nil
+	case "3.0", "30", "3":
+		return VCard30, nil
+	}
+	return "", fmt.Errorf("invalid vCard format %q, expected 2.1 or 3.0", s)
+}
+
+func (f VCardFormat) String() string {
+	switch f {
+	case VCard21:
+		return "vCard 2.1"
+	case VCard30:
+		return "vCard 3.0"
+	}
+	return string(f)
+}
+
+// PhonebookEntry is a vCard of the selected phonebook, as listed by the phone.
+type PhonebookEntry struct {
+	// Handle is the name of the vCard in the phonebook, e.g. 1.vcf. The
+	// one of the owner of the phone is 0.vcf.
+	Handle string
+	Name   string
+}
+
+// PhonebookAccess reads the phonebooks of a phone through PBAP. Like
+// FileTransfer, it's stateful: a phonebook must be selected before it can be
+// listed or pulled.
+type PhonebookAccess interface {
+	ObexSession
+	// Select picks the phonebook at location (see PhonebookInternal and
+	// PhonebookSIM). Phones refuse the ones they don't have.
+	Select(ctx context.Context, location string, phonebook Phonebook) error
+	List(ctx context.Context) ([]PhonebookEntry, error)
+	// PullAll downloads the whole selected phonebook to a vCard file. The
+	// transfer is canceled once the context is done.
+	PullAll(ctx context.Context, file string, format VCardFormat) (*Transfer, error)
+}
@@ -0,0 +1,54 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const obexPhonebookAccessInterface = "org.bluez.obex.PhonebookAccess1"
+
+// phonebookAccessSession is the Linux implementation of PhonebookAccess.
+type phonebookAccessSession struct {
+	*obexSession
+}
+
+// PhonebookAccess creates a PBAP session with the phone.
+func (o *linuxObex) PhonebookAccess(ctx context.Context, address string) (PhonebookAccess, error) {
+	session, err := o.newSession(ctx, address, "pbap")
+	if err != nil {
+		return nil, err
+	}
+	return &phonebookAccessSession{obexSession: session}, nil
+}
+
+func (s *phonebookAccessSession) Select(ctx context.Context, location string, phonebook Phonebook) error {
+	if err := s.call(ctx, obexPhonebookAccessInterface+".Select", location, string(phonebook)).Err; err != nil {
+		return fmt.Errorf("failed to select %s: %w", phonebook.Name(), err)
+	}
+	return nil
+}
+
+func (s *phonebookAccessSession) List(ctx context.Context) ([]PhonebookEntry, error) {
+	var entries []PhonebookEntry
+	if err := s.call(ctx, obexPhonebookAccessInterface+".List", map[string]dbus.Variant{}).Store(&entries); err != nil {
+		return nil, fmt.Errorf("failed to list phonebook: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *phonebookAccessSession) PullAll(ctx context.Context, file string, format VCardFormat) (*Transfer, error) {
+	// obexd writes the file itself, from a working directory of its own.
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull phonebook: %w", err)
+	}
+	filters := map[string]dbus.Variant{"Format": dbus.MakeVariant(string(format))}
+	t, err := s.transfer(ctx, obexPhonebookAccessInterface+".PullAll", nil, file, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull phonebook: %w", err)
+	}
+	return t, nil
+}
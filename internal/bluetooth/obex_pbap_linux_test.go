@@ -0,0 +1,68 @@
+package bluetooth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPhonebookAccess(t *testing.T) {
+	o, conn, _ := newTestObex(t)
+	session := conn.objects[testSessionPath]
+	session.Replies[obexPhonebookAccessInterface+".List"] = []any{[][]any{{"0.vcf", "Owner"}, {"1.vcf", "Alice"}}}
+	session.Replies[obexPhonebookAccessInterface+".PullAll"] = []any{
+		dbus.ObjectPath(testTransferPath),
+		map[string]dbus.Variant{"Status": dbus.MakeVariant("queued")},
+	}
+	session.Errors = map[string]error{
+		obexPhonebookAccessInterface + ".Select": dbus.Error{Name: "org.bluez.obex.Error.Failed", Body: []any{"Not Found"}},
+	}
+
+	var args []any
+	session.OnCall = func(method string, a ...any) *dbus.Call {
+		if method == obexPhonebookAccessInterface+".Select" || method == obexPhonebookAccessInterface+".PullAll" {
+			args = append(args, a...)
+		}
+		return nil
+	}
+
+	pbap, err := o.PhonebookAccess(t.Context(), "00:00:00:00:00:01")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Phones without favourites refuse to select them.
+	if err := pbap.Select(t.Context(), PhonebookInternal, PhonebookFavorites); !errors.Is(err, ErrFailed) {
+		t.Errorf("expected the phonebook to be refused, got: %v", err)
+	}
+	session.Errors = nil
+	if err := pbap.Select(t.Context(), PhonebookInternal, PhonebookContacts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(args, []any{"int", "fav", "int", "pb"}) {
+		t.Errorf("unexpected selections: %v", args)
+	}
+
+	entries, err := pbap.List(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(entries, []PhonebookEntry{{"0.vcf", "Owner"}, {"1.vcf", "Alice"}}) {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+
+	transfer, err := pbap.PullAll(t.Context(), "/tmp/contacts.vcf", VCard21)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if filters := args[len(args)-1].(map[string]dbus.Variant); filters["Format"].Value() != "vcard21" {
+		t.Errorf("expected vCard 2.1 to be asked for, got: %v", filters)
+	}
+	<-transfer.Progress()
+	conn.emit(transferChangedSignal(map[string]dbus.Variant{"Status": dbus.MakeVariant("complete")}))
+	if err := transfer.Wait(); err != nil {
+		t.Errorf("expected the transfer to complete, got: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package bluetooth
+
+import "testing"
+
+func TestParsePhonebook(t *testing.T) {
+	testCases := map[string]Phonebook{
+		"pb":             PhonebookContacts,
+		"ICH":            PhonebookIncoming,
+		"missed calls":   PhonebookMissed,
+		"outgoing-calls": PhonebookOutgoing,
+		"favourites":     PhonebookFavorites,
+	}
+	for s, expected := range testCases {
+		p, err := ParsePhonebook(s)
+		if err != nil || p != expected {
+			t.Errorf("expected %q to be %s, got: %s (%v)", s, expected, p, err)
+		}
+	}
+	if _, err := ParsePhonebook("calendar"); err == nil {
+		t.Error("expected an unknown phonebook to be refused")
+	}
+
+	if name := PhonebookIncoming.FileName(); name != "incoming-calls.vcf" {
+		t.Errorf("expected incoming-calls.vcf, got: %s", name)
+	}
+}
+
+func TestParseVCardFormat(t *testing.T) {
+	for s, expected := range map[string]VCardFormat{"2.1": VCard21, "21": VCard21, "3.0": VCard30, "vcard30": VCard30} {
+		f, err := ParseVCardFormat(s)
+		if err != nil || f != expected {
+			t.Errorf("expected %q to be %s, got: %s (%v)", s, expected, f, err)
+		}
+	}
+	if _, err := ParseVCardFormat("4.0"); err == nil {
+		t.Error("expected vCard 4.0 to be refused")
+	}
+}
@@ -0,0 +1,132 @@
+package bluetooth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Profile is one of the well-known Bluetooth profiles that can be connected
+// on its own through ConnectProfile.
+type Profile struct {
+	// Key is the short name used to refer to the profile, e.g. "a2dp-sink".
+	Key  string
+	Name string
+	UUID string
+}
+
+// UUIDs of the well-known profiles. Devices advertise the role they play, so
+// e.g. headphones list A2DP sink while a phone lists A2DP source.
+const (
+	UUIDSerialPort       = "00001101-0000-1000-8000-00805f9b34fb"
+	UUIDHeadset          = "00001108-0000-1000-8000-00805f9b34fb"
+	UUIDAudioSource      = "0000110a-0000-1000-8000-00805f9b34fb"
+	UUIDAudioSink        = "0000110b-0000-1000-8000-00805f9b34fb"
+	UUIDHeadsetGateway   = "00001112-0000-1000-8000-00805f9b34fb"
+	UUIDPANU             = "00001115-0000-1000-8000-00805f9b34fb"
+	UUIDNAP              = "00001116-0000-1000-8000-00805f9b34fb"
+	UUIDHandsfree        = "0000111e-0000-1000-8000-00805f9b34fb"
+	UUIDHandsfreeGateway = "0000111f-0000-1000-8000-00805f9b34fb"
+	UUIDHumanInterface   = "00001124-0000-1000-8000-00805f9b34fb"
+	UUIDAVRemoteTarget   = "0000110c-0000-1000-8000-00805f9b34fb"
+	UUIDAVRemoteControl  = "0000110e-0000-1000-8000-00805f9b34fb"
+)
+
+// bluetoothBaseUUIDTail is what follows the short form in the Bluetooth base
+// UUID, 0000xxxx-0000-1000-8000-00805f9b34fb.
+const bluetoothBaseUUIDTail = "-0000-1000-8000-00805f9b34fb"
+
+// profiles is the table of well-known profiles, in the order they are listed.
+var profiles = []Profile{
+	{Key: "a2dp-sink", Name: "A2DP Sink", UUID: UUIDAudioSink},
+	{Key: "a2dp-source", Name: "A2DP Source", UUID: UUIDAudioSource},
+	{Key: "hfp", Name: "Handsfree", UUID: UUIDHandsfree},
+	{Key: "hfp-ag", Name: "Handsfree Audio Gateway", UUID: UUIDHandsfreeGateway},
+	{Key: "hsp", Name: "Headset", UUID: UUIDHeadset},
+	{Key: "hsp-ag", Name: "Headset Audio Gateway", UUID: UUIDHeadsetGateway},
+	{Key: "avrcp-target", Name: "AV Remote Control Target", UUID: UUIDAVRemoteTarget},
+	{Key: "avrcp", Name: "AV Remote Control", UUID: UUIDAVRemoteControl},
+	{Key: "hid", Name: "Human Interface Device", UUID: UUIDHumanInterface},
+	{Key: "panu", Name: "PAN User", UUID: UUIDPANU},
+	{Key: "nap", Name: "Network Access Point", UUID: UUIDNAP},
+	{Key: "spp", Name: "Serial Port", UUID: UUIDSerialPort},
+}
+
+// Profiles returns the table of well-known profiles.
+func Profiles() []Profile {
+	return slices.Clone(profiles)
+}
+
+// NormalizeUUID returns the full lower case form of a UUID. The 16 and 32-bit
+// short forms (e.g. "110b" or "0x110B") are expanded with the Bluetooth base
+// UUID.
+func NormalizeUUID(uuid string) string {
+	uuid = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(uuid, "0x"), "0X"))
+	switch len(uuid) {
+	case 4:
+		return "0000" + uuid + bluetoothBaseUUIDTail
+	case 8:
+		return uuid + bluetoothBaseUUIDTail
+	}
+	return uuid
+}
+
+// validUUID tells whether uuid is a UUID in any of the forms NormalizeUUID
+// understands.
+func validUUID(uuid string) bool {
+	uuid = NormalizeUUID(uuid)
+	if len(uuid) != 36 {
+		return false
+	}
+	for i, r := range uuid {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// LookupProfile finds a well-known profile by its key (e.g. "hfp") or its
+// UUID, in any of its forms.
+func LookupProfile(s string) (Profile, bool) {
+	key := strings.ToLower(s)
+	uuid := NormalizeUUID(s)
+	for _, p := range profiles {
+		if p.Key == key || p.UUID == uuid {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ProfileName returns the name of the profile with the given UUID, or the
+// UUID itself if it's not a well-known one.
+func ProfileName(uuid string) string {
+	if p, ok := LookupProfile(uuid); ok {
+		return p.Name
+	}
+	return uuid
+}
+
+// profileUUID resolves the profile given to ConnectProfile and
+// DisconnectProfile, which may be a key or a UUID.
+func profileUUID(s string) (string, error) {
+	if s == "" {
+		return "", errors.New("a profile is required")
+	}
+	if p, ok := LookupProfile(s); ok {
+		return p.UUID, nil
+	}
+	if !validUUID(s) {
+		return "", fmt.Errorf("unknown profile %q", s)
+	}
+	return NormalizeUUID(s), nil
+}
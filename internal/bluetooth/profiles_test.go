@@ -0,0 +1,40 @@
+package bluetooth
+
+import "testing"
+
+func TestLookupProfile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected string
+		found    bool
+	}{
+		{name: "Key", query: "a2dp-sink", expected: UUIDAudioSink, found: true},
+		{name: "Upper case key", query: "HFP", expected: UUIDHandsfree, found: true},
+		{name: "Full UUID", query: "0000111E-0000-1000-8000-00805F9B34FB", expected: UUIDHandsfree, found: true},
+		{name: "Short UUID", query: "0x1124", expected: UUIDHumanInterface, found: true},
+		{name: "Unknown", query: "0000180f-0000-1000-8000-00805f9b34fb"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := LookupProfile(tc.query)
+			if ok != tc.found || p.UUID != tc.expected {
+				t.Errorf("expected profile %q (found %t), got: %q (found %t)", tc.expected, tc.found, p.UUID, ok)
+			}
+		})
+	}
+}
+
+func TestProfileUUID(t *testing.T) {
+	if uuid, err := profileUUID("180f"); err != nil || uuid != "0000180f-0000-1000-8000-00805f9b34fb" {
+		t.Errorf("expected short UUIDs to be expanded, got: %q, %v", uuid, err)
+	}
+	if _, err := profileUUID("headphones"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+	// A typo must fail here, rather than as an error from BlueZ.
+	if _, err := profileUUID("0000110b-0000-1000-8000-00805f9b34fx"); err == nil {
+		t.Error("expected an error for a malformed UUID")
+	}
+}
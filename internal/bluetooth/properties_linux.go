@@ -0,0 +1,302 @@
+package bluetooth
+
+import (
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// properties gives typed access to the property map of a D-Bus object, as
+// returned by GetManagedObjects or carried by PropertiesChanged. None of its
+// lookups panic: a property that is missing or holds a value of an unexpected
+// type is reported as not found.
+type properties map[string]dbus.Variant
+
+func (p properties) stringValue(name string) (string, bool) {
+	v, ok := p[name].Value().(string)
+	return v, ok
+}
+
+func (p properties) boolValue(name string) (bool, bool) {
+	v, ok := p[name].Value().(bool)
+	return v, ok
+}
+
+func (p properties) byteValue(name string) (byte, bool) {
+	v, ok := p[name].Value().(byte)
+	return v, ok
+}
+
+func (p properties) int16Value(name string) (int16, bool) {
+	v, ok := p[name].Value().(int16)
+	return v, ok
+}
+
+func (p properties) uint16Value(name string) (uint16, bool) {
+	v, ok := p[name].Value().(uint16)
+	return v, ok
+}
+
+func (p properties) uint32Value(name string) (uint32, bool) {
+	v, ok := p[name].Value().(uint32)
+	return v, ok
+}
+
+func (p properties) uint64Value(name string) (uint64, bool) {
+	v, ok := p[name].Value().(uint64)
+	return v, ok
+}
+
+func (p properties) bytesValue(name string) ([]byte, bool) {
+	v, ok := p[name].Value().([]byte)
+	return v, ok
+}
+
+func (p properties) objectPathValue(name string) (dbus.ObjectPath, bool) {
+	v, ok := p[name].Value().(dbus.ObjectPath)
+	return v, ok
+}
+
+func (p properties) stringsValue(name string) ([]string, bool) {
+	v, ok := p[name].Value().([]string)
+	return v, ok
+}
+
+// bytesByIDValue decodes an a{qv} property whose variants hold byte arrays,
+// like Device1.ManufacturerData. Entries holding something else are skipped.
+func (p properties) bytesByIDValue(name string) (map[uint16][]byte, bool) {
+	raw, ok := p[name].Value().(map[uint16]dbus.Variant)
+	if !ok {
+		return nil, false
+	}
+
+	values := make(map[uint16][]byte, len(raw))
+	for id, val := range raw {
+		if b, ok := val.Value().([]byte); ok {
+			values[id] = b
+		}
+	}
+	return values, true
+}
+
+// bytesByKeyValue decodes an a{sv} property whose variants hold byte arrays,
+// like Device1.ServiceData. Entries holding something else are skipped.
+func (p properties) bytesByKeyValue(name string) (map[string][]byte, bool) {
+	raw, ok := p[name].Value().(map[string]dbus.Variant)
+	if !ok {
+		return nil, false
+	}
+
+	values := make(map[string][]byte, len(raw))
+	for key, val := range raw {
+		if b, ok := val.Value().([]byte); ok {
+			values[key] = b
+		}
+	}
+	return values, true
+}
+
+// deviceFromProperties builds a Device out of the properties of an
+// org.bluez.Device1 object. It returns false if the properties don't carry
+// an address, since we can't do anything with such a device.
+func deviceFromProperties(path dbus.ObjectPath, props map[string]dbus.Variant) (Device, bool) {
+	dev := Device{path: string(path), name: "<unknown>"}
+	dev.applyProperties(props)
+
+	if dev.address == "" {
+		return Device{}, false
+	}
+
+	return dev, true
+}
+
+// applyProperties updates the device with the given org.bluez.Device1
+// properties. Properties that are not present are left untouched.
+func (d *Device) applyProperties(raw map[string]dbus.Variant) {
+	p := properties(raw)
+
+	if v, ok := p.stringValue("Address"); ok {
+		d.address = v
+	}
+	if v, ok := p.stringValue("AddressType"); ok {
+		d.addressType = v
+	}
+	if v, ok := p.stringValue("Name"); ok {
+		d.name = v
+	}
+	if v, ok := p.stringValue("Alias"); ok {
+		d.alias = v
+	}
+	if v, ok := p.stringValue("Icon"); ok {
+		d.icon = v
+	}
+	if v, ok := p.stringValue("Modalias"); ok {
+		d.modalias = v
+	}
+	if v, ok := p.uint32Value("Class"); ok {
+		d.class = v
+	}
+	if v, ok := p.uint16Value("Appearance"); ok {
+		d.appearance = v
+	}
+	if v, ok := p.int16Value("RSSI"); ok {
+		d.rssi, d.hasRSSI = v, true
+	}
+	if v, ok := p.int16Value("TxPower"); ok {
+		d.txPower, d.hasTxPower = v, true
+	}
+	if v, ok := p.boolValue("Paired"); ok {
+		d.paired = v
+	}
+	if v, ok := p.boolValue("Bonded"); ok {
+		d.bonded = v
+	}
+	if v, ok := p.boolValue("Trusted"); ok {
+		d.trusted = v
+	}
+	if v, ok := p.boolValue("Blocked"); ok {
+		d.blocked = v
+	}
+	if v, ok := p.boolValue("Connected"); ok {
+		d.connected = v
+	}
+	if v, ok := p.boolValue("ServicesResolved"); ok {
+		d.servicesResolved = v
+	}
+	if v, ok := p.boolValue("LegacyPairing"); ok {
+		d.legacyPairing = v
+	}
+	if v, ok := p.stringsValue("UUIDs"); ok {
+		d.uuids = v
+	}
+	if v, ok := p.bytesByIDValue("ManufacturerData"); ok {
+		d.manufacturerData = v
+	}
+	if v, ok := p.bytesByKeyValue("ServiceData"); ok {
+		d.serviceData = v
+	}
+}
+
+// invalidateProperties forgets the values of the given properties, as
+// requested by the invalidated list of a PropertiesChanged signal. Only the
+// optional ones can be invalidated by BlueZ.
+func (d *Device) invalidateProperties(names []string) {
+	for _, name := range names {
+		switch name {
+		case "RSSI":
+			d.rssi, d.hasRSSI = 0, false
+		case "TxPower":
+			d.txPower, d.hasTxPower = 0, false
+		case "ManufacturerData":
+			d.manufacturerData = nil
+		case "ServiceData":
+			d.serviceData = nil
+		case "UUIDs":
+			d.uuids = nil
+		}
+	}
+}
+
+// deviceFromInterfaces builds a Device out of all the interfaces of its
+// object, as reported by GetManagedObjects or InterfacesAdded. It returns
+// false if the object is not a device.
+func deviceFromInterfaces(path dbus.ObjectPath, ifaces map[string]map[string]dbus.Variant) (Device, bool) {
+	props, ok := ifaces[deviceInterface]
+	if !ok {
+		return Device{}, false
+	}
+
+	dev, ok := deviceFromProperties(path, props)
+	if !ok {
+		return Device{}, false
+	}
+
+	for iface, props := range ifaces {
+		if iface != deviceInterface {
+			dev.applyInterfaceProperties(iface, props)
+		}
+	}
+	return dev, true
+}
+
+// applyInterfaceProperties applies the properties of any of the interfaces
+// that we track on device objects. It returns false for the rest of them.
+func (d *Device) applyInterfaceProperties(iface string, raw map[string]dbus.Variant) bool {
+	switch iface {
+	case deviceInterface:
+		d.applyProperties(raw)
+	case batteryInterface:
+		p := properties(raw)
+		if v, ok := p.byteValue("Percentage"); ok {
+			d.battery, d.hasBattery = v, true
+		}
+		if v, ok := p.stringValue("Source"); ok {
+			d.batterySource = v
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// invalidateInterfaceProperties is the counterpart of invalidateProperties
+// for any of the interfaces that we track.
+func (d *Device) invalidateInterfaceProperties(iface string, names []string) {
+	if iface == deviceInterface {
+		d.invalidateProperties(names)
+	}
+}
+
+// removeInterface forgets everything that came from the given interface, once
+// the device doesn't have it anymore (e.g. the battery of a headset that was
+// disconnected). It returns false for the interfaces that we don't track.
+func (d *Device) removeInterface(iface string) bool {
+	switch iface {
+	case batteryInterface:
+		d.battery, d.hasBattery, d.batterySource = 0, false, ""
+	default:
+		return false
+	}
+	return true
+}
+
+// changedKey returns the key used in DeviceEvent.Changed for a property of
+// the given interface. Properties of Device1 keep their name, and the rest
+// are prefixed by their interface, e.g. "Battery1.Percentage".
+func changedKey(iface, name string) string {
+	if iface == deviceInterface {
+		return name
+	}
+	return strings.TrimPrefix(iface, "org.bluez.") + "." + name
+}
+
+// exportedProperties is the org.freedesktop.DBus.Properties object of the
+// objects we export (GATT attributes, advertisements), which BlueZ reads
+// them through. Every property is read-only, they change on our side only.
+type exportedProperties struct {
+	iface string
+	props func() map[string]dbus.Variant
+}
+
+func (p *exportedProperties) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	props, err := p.GetAll(iface)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	v, ok := props[name]
+	if !ok {
+		return dbus.Variant{}, dbus.NewError("org.freedesktop.DBus.Error.UnknownProperty", []any{name})
+	}
+	return v, nil
+}
+
+func (p *exportedProperties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	if iface != p.iface {
+		return nil, dbus.NewError("org.freedesktop.DBus.Error.UnknownInterface", []any{iface})
+	}
+	return p.props(), nil
+}
+
+func (p *exportedProperties) Set(iface, name string, value dbus.Variant) *dbus.Error {
+	return dbus.NewError("org.freedesktop.DBus.Error.PropertyReadOnly", []any{name})
+}
@@ -0,0 +1,100 @@
+package bluetooth
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestDeviceFromProperties(t *testing.T) {
+	props := map[string]dbus.Variant{
+		"Address":          dbus.MakeVariant("00:11:22:33:44:55"),
+		"AddressType":      dbus.MakeVariant("random"),
+		"Name":             dbus.MakeVariant("Headset"),
+		"Alias":            dbus.MakeVariant("My Headset"),
+		"Class":            dbus.MakeVariant(uint32(0x240404)),
+		"Appearance":       dbus.MakeVariant(uint16(0x0941)),
+		"Icon":             dbus.MakeVariant("audio-headset"),
+		"RSSI":             dbus.MakeVariant(int16(-58)),
+		"Paired":           dbus.MakeVariant(true),
+		"Connected":        dbus.MakeVariant(true),
+		"UUIDs":            dbus.MakeVariant([]string{"0000110b-0000-1000-8000-00805f9b34fb"}),
+		"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{0x004c: dbus.MakeVariant([]byte{0x02, 0x15})}),
+		"ServiceData":      dbus.MakeVariant(map[string]dbus.Variant{"0000feaa-0000-1000-8000-00805f9b34fb": dbus.MakeVariant([]byte{0x10})}),
+	}
+
+	dev, ok := deviceFromProperties("/org/bluez/hci0/dev_00_11_22_33_44_55", props)
+	if !ok {
+		t.Fatal("expected device to be built")
+	}
+
+	if dev.Address() != "00:11:22:33:44:55" || dev.AddressType() != "random" {
+		t.Errorf("unexpected address: %s (%s)", dev.Address(), dev.AddressType())
+	}
+	if dev.Name() != "Headset" || dev.Alias() != "My Headset" || dev.Icon() != "audio-headset" {
+		t.Errorf("unexpected names: %s, %s, %s", dev.Name(), dev.Alias(), dev.Icon())
+	}
+	if dev.Class() != 0x240404 || dev.Appearance() != 0x0941 {
+		t.Errorf("unexpected class/appearance: %x, %x", dev.Class(), dev.Appearance())
+	}
+	if rssi, ok := dev.RSSI(); !ok || rssi != -58 {
+		t.Errorf("expected RSSI -58, got: %d (%t)", rssi, ok)
+	}
+	if _, ok := dev.TxPower(); ok {
+		t.Errorf("expected TX power to be unknown")
+	}
+	if !dev.Paired() || !dev.Connected() || dev.Trusted() {
+		t.Errorf("unexpected state: paired %t, connected %t, trusted %t", dev.Paired(), dev.Connected(), dev.Trusted())
+	}
+	if !slices.Equal(dev.UUIDs(), []string{"0000110b-0000-1000-8000-00805f9b34fb"}) {
+		t.Errorf("unexpected UUIDs: %v", dev.UUIDs())
+	}
+	if !bytes.Equal(dev.ManufacturerData()[0x004c], []byte{0x02, 0x15}) {
+		t.Errorf("unexpected manufacturer data: %v", dev.ManufacturerData())
+	}
+	if !bytes.Equal(dev.ServiceData()["0000feaa-0000-1000-8000-00805f9b34fb"], []byte{0x10}) {
+		t.Errorf("unexpected service data: %v", dev.ServiceData())
+	}
+
+	dev.invalidateProperties([]string{"RSSI"})
+	if _, ok := dev.RSSI(); ok {
+		t.Errorf("expected RSSI to be unknown after invalidation")
+	}
+}
+
+func TestDeviceFromPropertiesUnexpectedTypes(t *testing.T) {
+	props := map[string]dbus.Variant{
+		"Address":          dbus.MakeVariant("00:11:22:33:44:55"),
+		"Name":             dbus.MakeVariant(42),
+		"RSSI":             dbus.MakeVariant("-58"),
+		"Paired":           dbus.MakeVariant("yes"),
+		"UUIDs":            dbus.MakeVariant("0000110b-0000-1000-8000-00805f9b34fb"),
+		"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{0x004c: dbus.MakeVariant("not bytes")}),
+	}
+
+	dev, ok := deviceFromProperties("/org/bluez/hci0/dev_00_11_22_33_44_55", props)
+	if !ok {
+		t.Fatal("expected device to be built")
+	}
+
+	if dev.Name() != "<unknown>" {
+		t.Errorf("expected name to fall back to <unknown>, got: %s", dev.Name())
+	}
+	if _, ok := dev.RSSI(); ok {
+		t.Errorf("expected RSSI to be unknown")
+	}
+	if dev.Paired() || dev.UUIDs() != nil {
+		t.Errorf("expected ill-typed properties to be ignored")
+	}
+	if len(dev.ManufacturerData()) != 0 {
+		t.Errorf("expected ill-typed manufacturer data to be skipped, got: %v", dev.ManufacturerData())
+	}
+
+	if _, ok := deviceFromProperties("/org/bluez/hci0/dev_00_11_22_33_44_55", map[string]dbus.Variant{
+		"Address": dbus.MakeVariant(42),
+	}); ok {
+		t.Errorf("expected device without a valid address to be skipped")
+	}
+}
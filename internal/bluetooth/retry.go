@@ -0,0 +1,166 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// RetryPolicy tells how Connect and Pair are retried when they fail for a
+// reason that is likely to go away on its own, like the device not answering
+// the page in time. Set it through WithRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, counting the first one.
+	// Anything below 2 disables retries.
+	MaxAttempts int
+	// InitialDelay is the wait before the second attempt. Every other wait
+	// is Multiplier times the previous one, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// Jitter randomizes each wait by up to this fraction of it (e.g. 0.2 is
+	// ±20%), so retries from different devices don't line up.
+	Jitter float64
+	// Retryable decides which errors are worth another attempt. It defaults
+	// to IsTransient.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns a policy that makes three attempts, one second
+// apart and then two.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Second,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// Delay returns how long to wait after the given attempt failed, before the
+// next one.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsTransient(err)
+}
+
+// transientReasons are the messages that BlueZ gives along with a generic
+// org.bluez.Error.Failed when a connection couldn't be set up this time, but
+// may work on the next try.
+var transientReasons = []string{
+	"br-connection-page-timeout",
+	"br-connection-canceled",
+	"br-connection-refused",
+	"br-connection-aborted-by-local",
+	"le-connection-abort-by-local",
+	"le-connection-concurrent-connection-limit",
+	"page timeout",
+	"software caused connection abort",
+	"host is down",
+	"resource temporarily unavailable",
+}
+
+// IsTransient tells whether err is likely to go away if the operation is
+// tried again. Errors that need a decision from the user (e.g. a rejected
+// pairing) and canceled operations are never transient. Neither is
+// ErrNotReady: the adapter is powered off, which waiting won't fix.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, ErrConnectionAttempt),
+		errors.Is(err, ErrInProgress):
+		return true
+	}
+
+	var bluezErr *Error
+	if !errors.As(err, &bluezErr) {
+		return false
+	}
+	if !errors.Is(bluezErr, ErrFailed) && !errors.Is(bluezErr, ErrNotAvailable) {
+		return false
+	}
+
+	message := strings.ToLower(bluezErr.Message)
+	for _, reason := range transientReasons {
+		if strings.Contains(message, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRetryPolicy makes Connect and Pair retry with the given policy. They
+// are only tried once by default. The policy is the same for every call, but
+// the context of a call still bounds how long its retries go on.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(b *adapterBase) {
+		b.retry = policy
+	}
+}
+
+// withRetry runs fn until it succeeds, fails for good or the retry policy
+// gives up. Every attempt is reported to the observer, along with the wait
+// before the next one.
+func (b *adapterBase) withRetry(ctx context.Context, op Operation, addr string, fn func() error) error {
+	attempts := max(b.retry.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		// Attempts are only numbered when there may be more than one.
+		started := OperationEvent{Operation: op, Address: addr, Status: OperationStarted}
+		if attempts > 1 {
+			started.Attempt, started.MaxAttempts = attempt, attempts
+		}
+		b.notify(started)
+
+		err := fn()
+		if err == nil || attempt == attempts || ctx.Err() != nil || !b.retry.retryable(err) {
+			return err
+		}
+
+		delay := b.retry.Delay(attempt)
+		b.notify(OperationEvent{
+			Operation:   op,
+			Address:     addr,
+			Status:      OperationRetrying,
+			Message:     fmt.Sprintf("attempt %d of %d failed, retrying in %s", attempt, attempts, delay.Round(100*time.Millisecond)),
+			Err:         err,
+			Attempt:     attempt,
+			MaxAttempts: attempts,
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
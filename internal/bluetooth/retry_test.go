@@ -0,0 +1,52 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("expected delay after attempt %d to be %s, got: %s", i+1, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		if got := policy.Delay(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("expected delay to be within 50%% of 1s, got: %s", got)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Page timeout", err: &Error{Message: "br-connection-page-timeout", kind: ErrFailed}, expected: true},
+		{name: "Aborted by local", err: fmt.Errorf("connecting failed: %w", &Error{Message: "le-connection-abort-by-local", kind: ErrFailed}), expected: true},
+		{name: "Connection attempt", err: &Error{kind: ErrConnectionAttempt}, expected: true},
+		{name: "In progress", err: &Error{kind: ErrInProgress}, expected: true},
+		{name: "Not ready", err: &Error{kind: ErrNotReady}},
+		{name: "Other failure", err: &Error{Message: "Protocol not available", kind: ErrFailed}},
+		{name: "Rejected pairing", err: &Error{kind: ErrAuthenticationRejected}},
+		{name: "Canceled", err: context.Canceled},
+		{name: "Unknown device", err: ErrDeviceNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTransient(tc.err); got != tc.expected {
+				t.Errorf("expected IsTransient(%v) to be %t, got: %t", tc.err, tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Names of the signals that we care about, as they are reported in
+// dbus.Signal.Name.
+const (
+	interfacesAddedSignal   = objectManagerInterface + ".InterfacesAdded"
+	interfacesRemovedSignal = objectManagerInterface + ".InterfacesRemoved"
+	propertiesChangedSignal = propertiesInterface + ".PropertiesChanged"
+)
+
+// watchMatches returns the match rules needed to receive the signals that
+// Watch translates into DeviceEvents.
+func (b *linuxAdapter) watchMatches() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(objectManagerInterface),
+			dbus.WithMatchMember("InterfacesAdded"),
+		},
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(objectManagerInterface),
+			dbus.WithMatchMember("InterfacesRemoved"),
+		},
+		{
+			dbus.WithMatchSender(b.destination),
+			dbus.WithMatchInterface(propertiesInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchPathNamespace(dbus.ObjectPath(b.path)),
+		},
+	}
+}
+
+// subscribe adds the match rules and starts receiving the signals that match
+// them. The returned function undoes both, and it must be called once the
+// signals are no longer needed.
+func (b *linuxAdapter) subscribe(matches [][]dbus.MatchOption) (<-chan *dbus.Signal, func(), error) {
+	return subscribe(b.conn, matches)
+}
+
+// subscribe is the guts of linuxAdapter.subscribe, shared with the OBEX
+// client, which has a connection of its own.
+func subscribe(conn dbusConn, matches [][]dbus.MatchOption) (<-chan *dbus.Signal, func(), error) {
+	for i, m := range matches {
+		if err := conn.AddMatchSignal(m...); err != nil {
+			for _, added := range matches[:i] {
+				_ = conn.RemoveMatchSignal(added...)
+			}
+			return nil, nil, fmt.Errorf("failed to subscribe to BlueZ signals: %w", err)
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 32)
+	conn.Signal(signals)
+
+	unsubscribe := func() {
+		conn.RemoveSignal(signals)
+		for _, m := range matches {
+			_ = conn.RemoveMatchSignal(m...)
+		}
+	}
+
+	return signals, unsubscribe, nil
+}
+
+// Watch subscribes to the InterfacesAdded, InterfacesRemoved and
+// PropertiesChanged signals of BlueZ and translates them into DeviceEvents.
+// The devices already known by BlueZ are sent first as DeviceAdded events,
+// so the stream alone is enough to build a complete view of the adapter.
+//
+// The device cache used by the rest of the adapter methods is kept up to date
+// while the watch is running. The returned channel is closed once the context
+// is done.
+func (b *linuxAdapter) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	return b.watch(ctx, true)
+}
+
+// watch does the actual work of Watch. The initial DeviceAdded events for the
+// known devices are only sent if withSnapshot is true, but the cache is
+// always refreshed.
+func (b *linuxAdapter) watch(ctx context.Context, withSnapshot bool) (<-chan DeviceEvent, error) {
+	// We subscribe before taking the snapshot of the devices so that nothing
+	// can slip between the two.
+	signals, unsubscribe, err := b.subscribe(b.watchMatches())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.getDevicesInfo(ctx); err != nil {
+		unsubscribe()
+		return nil, fmt.Errorf("failed to get info for known devices: %w", err)
+	}
+
+	var snapshot []Device
+	if withSnapshot {
+		snapshot, _ = b.Devices()
+	}
+
+	events := make(chan DeviceEvent, 16)
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+
+		send := func(ev DeviceEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, dev := range snapshot {
+			if !send(DeviceEvent{Type: DeviceAdded, Device: dev}) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					// The connection was closed.
+					return
+				}
+				ev, ok := b.handleSignal(sig)
+				if ok && !send(ev) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// handleSignal applies the change described by a BlueZ signal to the device
+// cache, and returns the matching DeviceEvent. It returns false for signals
+// that are not about one of our devices.
+//
+// Besides Device1, devices may get other interfaces (like Battery1) added and
+// removed while they are around. Those are reported as updates.
+func (b *linuxAdapter) handleSignal(sig *dbus.Signal) (DeviceEvent, bool) {
+	switch sig.Name {
+	case interfacesAddedSignal:
+		var path dbus.ObjectPath
+		var ifaces map[string]map[string]dbus.Variant
+		if err := dbus.Store(sig.Body, &path, &ifaces); err != nil {
+			return DeviceEvent{}, false
+		}
+		if !b.ownsPath(path) {
+			return DeviceEvent{}, false
+		}
+
+		if _, ok := ifaces[deviceInterface]; ok {
+			dev, ok := deviceFromInterfaces(path, ifaces)
+			if !ok {
+				return DeviceEvent{}, false
+			}
+
+			b.mu.Lock()
+			b.devices[dev.address] = dev
+			b.mu.Unlock()
+
+			values := make(map[string]any)
+			for iface, props := range ifaces {
+				maps.Copy(values, variantValues(iface, props))
+			}
+			return DeviceEvent{Type: DeviceAdded, Device: dev, Changed: values}, true
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		dev, ok := b.deviceByPathLocked(path)
+		if !ok {
+			return DeviceEvent{}, false
+		}
+
+		values := make(map[string]any)
+		for iface, props := range ifaces {
+			if dev.applyInterfaceProperties(iface, props) {
+				maps.Copy(values, variantValues(iface, props))
+			}
+		}
+		if len(values) == 0 {
+			return DeviceEvent{}, false
+		}
+		b.devices[dev.address] = dev
+
+		return DeviceEvent{Type: DeviceUpdated, Device: dev, Changed: values}, true
+
+	case interfacesRemovedSignal:
+		var path dbus.ObjectPath
+		var ifaces []string
+		if err := dbus.Store(sig.Body, &path, &ifaces); err != nil {
+			return DeviceEvent{}, false
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		dev, ok := b.deviceByPathLocked(path)
+		if !ok {
+			return DeviceEvent{}, false
+		}
+
+		if slices.Contains(ifaces, deviceInterface) {
+			delete(b.devices, dev.address)
+			return DeviceEvent{Type: DeviceRemoved, Device: dev}, true
+		}
+
+		// The properties of the interfaces that are gone are reported as
+		// invalidated.
+		values := make(map[string]any)
+		for _, iface := range ifaces {
+			if dev.removeInterface(iface) {
+				values[changedKey(iface, "*")] = nil
+			}
+		}
+		if len(values) == 0 {
+			return DeviceEvent{}, false
+		}
+		b.devices[dev.address] = dev
+
+		return DeviceEvent{Type: DeviceUpdated, Device: dev, Changed: values}, true
+
+	case propertiesChangedSignal:
+		var iface string
+		var changed map[string]dbus.Variant
+		var invalidated []string
+		if err := dbus.Store(sig.Body, &iface, &changed, &invalidated); err != nil {
+			return DeviceEvent{}, false
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		dev, ok := b.deviceByPathLocked(sig.Path)
+		if !ok || !dev.applyInterfaceProperties(iface, changed) {
+			return DeviceEvent{}, false
+		}
+		dev.invalidateInterfaceProperties(iface, invalidated)
+		b.devices[dev.address] = dev
+
+		values := variantValues(iface, changed)
+		for _, name := range invalidated {
+			values[changedKey(iface, name)] = nil
+		}
+
+		return DeviceEvent{Type: DeviceUpdated, Device: dev, Changed: values}, true
+	}
+
+	return DeviceEvent{}, false
+}
+
+// ownsPath reports whether the given object path belongs to our adapter.
+func (b *linuxAdapter) ownsPath(path dbus.ObjectPath) bool {
+	return strings.HasPrefix(string(path), b.path+"/")
+}
+
+// deviceByPathLocked looks for a cached device by its object path. The caller
+// must hold b.mu.
+func (b *linuxAdapter) deviceByPathLocked(path dbus.ObjectPath) (Device, bool) {
+	for _, dev := range b.devices {
+		if dev.path == string(path) {
+			return dev, true
+		}
+	}
+	return Device{}, false
+}
+
+// variantValues unwraps the values of a property map of the given interface,
+// so that they can be handed to callers that don't know about D-Bus. They are
+// keyed as described by changedKey.
+func variantValues(iface string, props map[string]dbus.Variant) map[string]any {
+	values := make(map[string]any, len(props))
+	for name, val := range props {
+		values[changedKey(iface, name)] = val.Value()
+	}
+	return values
+}
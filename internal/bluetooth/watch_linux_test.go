@@ -0,0 +1,212 @@
+package bluetooth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// newTestAdapter returns an adapter backed by a mock connection whose
+// object manager knows about a single device of hci0, and one of hci1.
+func newTestAdapter(t testing.TB) (*linuxAdapter, *mockDbusConn) {
+	t.Helper()
+
+	conn := &mockDbusConn{
+		objects: map[string]*mockBusObject{
+			"/": {
+				Replies: map[string][]any{
+					objectManagerInterface + ".GetManagedObjects": {
+						map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+							"/org/bluez/hci0/dev_00_00_00_00_00_01": {
+								deviceInterface: {
+									"Address": dbus.MakeVariant("00:00:00:00:00:01"),
+									"Name":    dbus.MakeVariant("Keyboard"),
+								},
+							},
+							"/org/bluez/hci1/dev_00_00_00_00_00_04": {
+								deviceInterface: {
+									"Address": dbus.MakeVariant("00:00:00:00:00:04"),
+									"Name":    dbus.MakeVariant("Mouse"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	adapter, err := NewAdapter("", "", func() (dbusConn, error) { return conn, nil })
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	return adapter.(*linuxAdapter), conn
+}
+
+func receiveEvent(t *testing.T, events <-chan DeviceEvent) DeviceEvent {
+	t.Helper()
+
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("events channel closed unexpectedly")
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a device event")
+	}
+	return DeviceEvent{}
+}
+
+func TestWatch(t *testing.T) {
+	adapter, conn := newTestAdapter(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := adapter.Watch(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ev := receiveEvent(t, events)
+	if ev.Type != DeviceAdded || ev.Device.Address() != "00:00:00:00:00:01" {
+		t.Fatalf("expected known device to be added first, got: %v %s", ev.Type, ev.Device.Address())
+	}
+
+	// A device from another adapter must be ignored, so the next event we get
+	// is the one for hci0.
+	conn.emit(&dbus.Signal{
+		Name: interfacesAddedSignal,
+		Path: "/",
+		Body: []any{
+			dbus.ObjectPath("/org/bluez/hci1/dev_00_00_00_00_00_03"),
+			map[string]map[string]dbus.Variant{
+				deviceInterface: {"Address": dbus.MakeVariant("00:00:00:00:00:03")},
+			},
+		},
+	})
+	conn.emit(&dbus.Signal{
+		Name: interfacesAddedSignal,
+		Path: "/",
+		Body: []any{
+			dbus.ObjectPath("/org/bluez/hci0/dev_00_00_00_00_00_02"),
+			map[string]map[string]dbus.Variant{
+				deviceInterface: {"Address": dbus.MakeVariant("00:00:00:00:00:02")},
+			},
+		},
+	})
+
+	ev = receiveEvent(t, events)
+	if ev.Type != DeviceAdded || ev.Device.Address() != "00:00:00:00:00:02" {
+		t.Fatalf("expected device 02 to be added, got: %v %s", ev.Type, ev.Device.Address())
+	}
+
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: "/org/bluez/hci0/dev_00_00_00_00_00_02",
+		Body: []any{
+			deviceInterface,
+			map[string]dbus.Variant{"Name": dbus.MakeVariant("Headset")},
+			[]string{"RSSI"},
+		},
+	})
+
+	ev = receiveEvent(t, events)
+	if ev.Type != DeviceUpdated || ev.Device.Name() != "Headset" {
+		t.Fatalf("expected device 02 to be renamed, got: %v %s", ev.Type, ev.Device.Name())
+	}
+	if _, ok := ev.Changed["RSSI"]; !ok {
+		t.Errorf("expected invalidated RSSI to be reported as changed")
+	}
+
+	conn.emit(&dbus.Signal{
+		Name: interfacesRemovedSignal,
+		Path: "/",
+		Body: []any{
+			dbus.ObjectPath("/org/bluez/hci0/dev_00_00_00_00_00_01"),
+			[]string{deviceInterface},
+		},
+	})
+
+	ev = receiveEvent(t, events)
+	if ev.Type != DeviceRemoved || ev.Device.Address() != "00:00:00:00:00:01" {
+		t.Fatalf("expected device 01 to be removed, got: %v %s", ev.Type, ev.Device.Address())
+	}
+
+	devices, err := adapter.Devices()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(devices) != 1 || devices[0].Name() != "Headset" {
+		t.Errorf("expected cache to only hold the renamed device, got: %v", devices)
+	}
+
+	cancel()
+	for range events {
+	}
+
+	if conn.matches != 0 {
+		t.Errorf("expected every match rule to be removed, %d left", conn.matches)
+	}
+}
+
+func TestWatchBattery(t *testing.T) {
+	const devicePath = "/org/bluez/hci0/dev_00_00_00_00_00_01"
+
+	adapter, conn := newTestAdapter(t)
+
+	events, err := adapter.Watch(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	receiveEvent(t, events)
+
+	// The battery shows up once the device connects.
+	conn.emit(&dbus.Signal{
+		Name: interfacesAddedSignal,
+		Path: "/",
+		Body: []any{
+			dbus.ObjectPath(devicePath),
+			map[string]map[string]dbus.Variant{
+				batteryInterface: {
+					"Percentage": dbus.MakeVariant(byte(80)),
+					"Source":     dbus.MakeVariant("HFP 1.7"),
+				},
+			},
+		},
+	})
+
+	ev := receiveEvent(t, events)
+	if level, ok := ev.Device.Battery(); ev.Type != DeviceUpdated || !ok || level != 80 || ev.Device.BatterySource() != "HFP 1.7" {
+		t.Fatalf("expected battery at 80%% from HFP 1.7, got: %v %d %t %q", ev.Type, level, ok, ev.Device.BatterySource())
+	}
+
+	conn.emit(&dbus.Signal{
+		Name: propertiesChangedSignal,
+		Path: devicePath,
+		Body: []any{batteryInterface, map[string]dbus.Variant{"Percentage": dbus.MakeVariant(byte(75))}, []string{}},
+	})
+
+	ev = receiveEvent(t, events)
+	if level, _ := ev.Device.Battery(); level != 75 {
+		t.Errorf("expected battery at 75%%, got: %d", level)
+	}
+	if _, ok := ev.Changed["Battery1.Percentage"]; !ok {
+		t.Errorf("expected Battery1.Percentage to be reported as changed, got: %v", ev.Changed)
+	}
+
+	conn.emit(&dbus.Signal{
+		Name: interfacesRemovedSignal,
+		Path: "/",
+		Body: []any{dbus.ObjectPath(devicePath), []string{batteryInterface}},
+	})
+
+	ev = receiveEvent(t, events)
+	if _, ok := ev.Device.Battery(); ev.Type != DeviceUpdated || ok {
+		t.Errorf("expected the battery to be gone from the device, got: %v", ev.Type)
+	}
+}
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// adapterField is one of the adapter properties that are edited through a
+// text input.
+type adapterField int
+
+const (
+	noField adapterField = iota
+	aliasField
+	discoverableTimeoutField
+	pairableTimeoutField
+)
+
+// adapterPanel shows the state of the adapter and lets the user change it.
+type adapterPanel struct {
+	info   bluetooth.AdapterInfo
+	loaded bool
+	keys   adapterKeyMap
+	// editing is the property being edited through input, if any.
+	editing adapterField
+	input   textinput.Model
+	// err is the last error we got while changing the adapter.
+	err error
+}
+
+func newAdapterPanel() *adapterPanel {
+	return &adapterPanel{keys: newAdapterKeyMap()}
+}
+
+// adapterInfoMsg carries the state of the adapter after loading or changing
+// it.
+type adapterInfoMsg struct {
+	info bluetooth.AdapterInfo
+	err  error
+}
+
+// loadAdapterInfo gets the current state of the adapter.
+func loadAdapterInfo(ctx context.Context, adapter bluetooth.Adapter) tea.Cmd {
+	return func() tea.Msg {
+		info, err := adapter.Info(ctx)
+		return adapterInfoMsg{info: info, err: err}
+	}
+}
+
+// changeAdapter runs set, which changes one of the adapter properties, and
+// then reloads the state of the adapter.
+func changeAdapter(ctx context.Context, adapter bluetooth.Adapter, set func(context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		if err := set(ctx); err != nil {
+			return adapterInfoMsg{err: err}
+		}
+		info, err := adapter.Info(ctx)
+		return adapterInfoMsg{info: info, err: err}
+	}
+}
+
+// handleAdapterInfo updates the panel with the new state of the adapter.
+func (m *model) handleAdapterInfo(msg adapterInfoMsg) tea.Cmd {
+	if m.panel == nil {
+		return nil
+	}
+
+	m.panel.err = msg.err
+	if msg.err == nil {
+		m.panel.info = msg.info
+		m.panel.loaded = true
+	}
+	return nil
+}
+
+// updateAdapterPanel handles the keys pressed while the panel is shown.
+func (m *model) updateAdapterPanel(msg tea.KeyMsg) tea.Cmd {
+	p := m.panel
+
+	if p.editing != noField {
+		switch msg.Type {
+		case tea.KeyEnter:
+			field, value := p.editing, strings.TrimSpace(p.input.Value())
+			p.editing = noField
+			return m.applyAdapterField(field, value)
+		case tea.KeyEsc:
+			p.editing = noField
+			return nil
+		}
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, p.keys.back):
+		m.panel = nil
+		return nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	}
+
+	// Nothing can be changed until we know the current state.
+	if !p.loaded {
+		return nil
+	}
+
+	info := p.info
+	switch {
+	case key.Matches(msg, p.keys.power):
+		return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetPowered(ctx, !info.Powered) })
+	case key.Matches(msg, p.keys.discoverable):
+		return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetDiscoverable(ctx, !info.Discoverable) })
+	case key.Matches(msg, p.keys.pairable):
+		return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetPairable(ctx, !info.Pairable) })
+	case key.Matches(msg, p.keys.alias):
+		return p.edit(aliasField, "Alias", info.Alias)
+	case key.Matches(msg, p.keys.discoverableTimeout):
+		return p.edit(discoverableTimeoutField, "Seconds, 0 = forever", secondsValue(info.DiscoverableTimeout))
+	case key.Matches(msg, p.keys.pairableTimeout):
+		return p.edit(pairableTimeoutField, "Seconds, 0 = forever", secondsValue(info.PairableTimeout))
+	}
+
+	return nil
+}
+
+// edit starts editing the given property through the text input.
+func (p *adapterPanel) edit(field adapterField, placeholder, value string) tea.Cmd {
+	p.editing = field
+	p.input = textinput.New()
+	p.input.Placeholder = placeholder
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	p.input.Focus()
+	return textinput.Blink
+}
+
+// applyAdapterField sets the edited property to the value typed by the user.
+func (m *model) applyAdapterField(field adapterField, value string) tea.Cmd {
+	if field == aliasField {
+		return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetAlias(ctx, value) })
+	}
+
+	secs, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		m.panel.err = fmt.Errorf("invalid timeout %q, expected a number of seconds", value)
+		return nil
+	}
+	timeout := time.Duration(secs) * time.Second
+
+	if field == discoverableTimeoutField {
+		return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetDiscoverableTimeout(ctx, timeout) })
+	}
+	return changeAdapter(m.ctx, m.adapter, func(ctx context.Context) error { return m.adapter.SetPairableTimeout(ctx, timeout) })
+}
+
+func secondsValue(timeout time.Duration) string {
+	return strconv.Itoa(int(timeout.Seconds()))
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
+
+func timeoutValue(timeout time.Duration) string {
+	if timeout == 0 {
+		return "forever"
+	}
+	return timeout.String()
+}
+
+// View renders the panel.
+func (p *adapterPanel) View() string {
+	if !p.loaded {
+		if p.err != nil {
+			return errorStyle.Render(p.err.Error())
+		}
+		return "Loading adapter..."
+	}
+
+	info := p.info
+	rows := []struct {
+		label string
+		value string
+		field adapterField
+	}{
+		{label: "Address", value: info.Address},
+		{label: "Name", value: info.Name},
+		{label: "Alias", value: info.Alias, field: aliasField},
+		{label: "Powered", value: onOff(info.Powered)},
+		{label: "Discoverable", value: onOff(info.Discoverable)},
+		{label: "Discoverable timeout", value: timeoutValue(info.DiscoverableTimeout), field: discoverableTimeoutField},
+		{label: "Pairable", value: onOff(info.Pairable)},
+		{label: "Pairable timeout", value: timeoutValue(info.PairableTimeout), field: pairableTimeoutField},
+		{label: "Discovering", value: onOff(info.Discovering)},
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		value := row.value
+		if row.field != noField && row.field == p.editing {
+			value = p.input.View()
+		}
+		lines = append(lines, panelLabelStyle.Render(row.label)+value)
+	}
+
+	if p.err != nil {
+		lines = append(lines, "", errorStyle.Render(p.err.Error()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// adapterView renders the adapter panel as a full screen.
+func (m model) adapterView() string {
+	title := titleStyle.Render("Adapter " + m.panel.info.ID())
+
+	helpView := m.help.View(m.panel.keys)
+	if m.panel.editing != noField {
+		helpView = modalHintStyle.Render("enter save • esc cancel")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		"",
+		m.panel.View(),
+		"",
+		helpView,
+	))
+}
@@ -0,0 +1,453 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// adField is one of the fields of the advertisement form.
+type adField int
+
+const (
+	adTypeField adField = iota
+	adNameField
+	adUUIDsField
+	adManufacturerField
+	adServiceDataField
+	adTxPowerField
+	adAppearanceField
+	adDiscoverableField
+	adTimeoutField
+	adFieldCount
+)
+
+// advertisingPane shows the advertisements we broadcast, along with the free
+// slots of the adapter, and a form to start new ones. The cursor goes over
+// the fields of the form first, and then over the advertisements.
+type advertisingPane struct {
+	keys         advertisingKeyMap
+	adType       bluetooth.AdvertisementType
+	discoverable bool
+	// values holds the text fields as typed, which are only parsed when
+	// the advertisement starts.
+	values  [adFieldCount]string
+	cursor  int
+	editing bool
+	input   textinput.Model
+	// info is nil until loaded.
+	info   *bluetooth.AdvertisingInfo
+	status string
+	err    error
+	// ticking is set while the countdowns of the advertisements with a
+	// timeout are kept up to date.
+	ticking bool
+}
+
+func newAdvertisingPane() *advertisingPane {
+	return &advertisingPane{keys: newAdvertisingKeyMap(), adType: bluetooth.AdvertisementBroadcast}
+}
+
+// advertisingInfoMsg carries the advertising capabilities of the adapter.
+type advertisingInfoMsg struct {
+	info bluetooth.AdvertisingInfo
+	err  error
+}
+
+// advertiseMsg is sent once an advertisement is registered.
+type advertiseMsg struct {
+	ad  *bluetooth.ActiveAdvertisement
+	err error
+}
+
+// advertisementReleasedMsg is sent when an advertisement stops being
+// broadcast, be it stopped by us or released by BlueZ.
+type advertisementReleasedMsg struct {
+	ad *bluetooth.ActiveAdvertisement
+}
+
+// advertisingTickMsg redraws the pane every second while an advertisement
+// with a timeout is shown, so its countdown goes down.
+type advertisingTickMsg struct {
+	pane *advertisingPane
+}
+
+// advertisementStoppedMsg carries the error of stopping an advertisement.
+// Its removal is left to advertisementReleasedMsg.
+type advertisementStoppedMsg struct {
+	err error
+}
+
+// tickAdvertising starts the ticks of the pane, unless they already run or
+// no advertisement has a timeout.
+func (m *model) tickAdvertising() tea.Cmd {
+	a := m.advertising
+	timed := slices.ContainsFunc(m.advertisements, func(ad *bluetooth.ActiveAdvertisement) bool {
+		return ad.Advertisement.Timeout > 0
+	})
+	if a == nil || a.ticking || !timed {
+		return nil
+	}
+	a.ticking = true
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return advertisingTickMsg{a}
+	})
+}
+
+func loadAdvertisingInfo(ctx context.Context, adapter bluetooth.Adapter) tea.Cmd {
+	return func() tea.Msg {
+		info, err := adapter.AdvertisingInfo(ctx)
+		return advertisingInfoMsg{info: info, err: err}
+	}
+}
+
+func startAdvertising(ctx context.Context, adapter bluetooth.Adapter, ad bluetooth.Advertisement) tea.Cmd {
+	return func() tea.Msg {
+		active, err := adapter.Advertise(ctx, ad)
+		return advertiseMsg{ad: active, err: err}
+	}
+}
+
+// waitForRelease waits until the advertisement stops being broadcast.
+func waitForRelease(ad *bluetooth.ActiveAdvertisement) tea.Cmd {
+	return func() tea.Msg {
+		<-ad.Released()
+		return advertisementReleasedMsg{ad}
+	}
+}
+
+func stopAdvertising(ctx context.Context, ad *bluetooth.ActiveAdvertisement) tea.Cmd {
+	return func() tea.Msg {
+		return advertisementStoppedMsg{err: ad.Stop(ctx)}
+	}
+}
+
+// handleAdvertisingMsg handles the messages of the advertisements. They are
+// kept by the model, so they go on while the pane is closed.
+func (m *model) handleAdvertisingMsg(msg tea.Msg) tea.Cmd {
+	a := m.advertising
+
+	switch msg := msg.(type) {
+	case advertisingInfoMsg:
+		if a == nil {
+			return nil
+		}
+		a.err = msg.err
+		if msg.err == nil {
+			a.info = &msg.info
+		}
+	case advertiseMsg:
+		if msg.err != nil {
+			if a != nil {
+				a.err = msg.err
+			}
+			return nil
+		}
+		m.advertisements = append(m.advertisements, msg.ad)
+		cmds := []tea.Cmd{waitForRelease(msg.ad)}
+		if a != nil {
+			a.status, a.err = "Advertising as "+msg.ad.Path+".", nil
+			cmds = append(cmds, loadAdvertisingInfo(m.ctx, m.adapter), m.tickAdvertising())
+		}
+		return tea.Batch(cmds...)
+	case advertisementReleasedMsg:
+		for i, ad := range m.advertisements {
+			if ad == msg.ad {
+				m.advertisements = append(m.advertisements[:i], m.advertisements[i+1:]...)
+				break
+			}
+		}
+		if a != nil {
+			a.cursor = min(a.cursor, int(adFieldCount)+len(m.advertisements)-1)
+			return loadAdvertisingInfo(m.ctx, m.adapter)
+		}
+	case advertisementStoppedMsg:
+		if a != nil {
+			a.err = msg.err
+		}
+	case advertisingTickMsg:
+		// The view is redrawn after every message, which is all we need.
+		// The ticks stop with the pane, or once no countdown is left.
+		if a != msg.pane {
+			return nil
+		}
+		a.ticking = false
+		return m.tickAdvertising()
+	}
+	return nil
+}
+
+// updateAdvertisingPane handles the keys pressed while the pane is shown.
+func (m *model) updateAdvertisingPane(msg tea.KeyMsg) tea.Cmd {
+	a := m.advertising
+
+	if a.editing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			a.values[a.cursor] = strings.TrimSpace(a.input.Value())
+			a.editing = false
+		case tea.KeyEsc:
+			a.editing = false
+		default:
+			var cmd tea.Cmd
+			a.input, cmd = a.input.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	rows := int(adFieldCount) + len(m.advertisements)
+	switch {
+	case key.Matches(msg, a.keys.up):
+		a.cursor = (a.cursor + rows - 1) % rows
+	case key.Matches(msg, a.keys.down):
+		a.cursor = (a.cursor + 1) % rows
+	case key.Matches(msg, a.keys.back):
+		m.advertising = nil
+	case key.Matches(msg, a.keys.start):
+		ad, err := a.advertisement()
+		if err == nil {
+			err = ad.Validate()
+		}
+		if err != nil {
+			a.err = err
+			return nil
+		}
+		a.status, a.err = "Registering advertisement...", nil
+		return startAdvertising(m.ctx, m.adapter, ad)
+	case a.cursor >= int(adFieldCount):
+		// The keys below act on the advertisement under the cursor.
+		if key.Matches(msg, a.keys.clear) {
+			a.status, a.err = "Stopping advertisement...", nil
+			return stopAdvertising(m.ctx, m.advertisements[a.cursor-int(adFieldCount)])
+		}
+	case key.Matches(msg, a.keys.edit):
+		return a.edit()
+	case key.Matches(msg, a.keys.clear):
+		a.values[a.cursor] = ""
+		a.err = nil
+	}
+	return nil
+}
+
+// edit toggles the field under the cursor, or starts editing it through the
+// text input.
+func (a *advertisingPane) edit() tea.Cmd {
+	field := adField(a.cursor)
+	switch field {
+	case adTypeField:
+		if a.adType == bluetooth.AdvertisementBroadcast {
+			a.adType = bluetooth.AdvertisementPeripheral
+		} else {
+			a.adType, a.discoverable = bluetooth.AdvertisementBroadcast, false
+		}
+		return nil
+	case adDiscoverableField:
+		a.discoverable = !a.discoverable
+		return nil
+	}
+
+	a.editing = true
+	a.err = nil
+	a.input = textinput.New()
+	a.input.SetValue(a.values[field])
+	a.input.CursorEnd()
+	a.input.Focus()
+
+	switch field {
+	case adNameField:
+		a.input.Placeholder = "Local name"
+	case adUUIDsField:
+		a.input.Placeholder = "Comma-separated UUIDs"
+	case adManufacturerField:
+		a.input.Placeholder = "company=hex, e.g. 004c=0215..."
+	case adServiceDataField:
+		a.input.Placeholder = "uuid=hex, e.g. feaa=10..."
+	case adTxPowerField:
+		a.input.Placeholder = "dBm, e.g. -8"
+	case adAppearanceField:
+		a.input.Placeholder = "e.g. 0x0200"
+	case adTimeoutField:
+		a.input.Placeholder = "seconds"
+	}
+	return textinput.Blink
+}
+
+// advertisement builds the advertisement out of the form.
+func (a *advertisingPane) advertisement() (bluetooth.Advertisement, error) {
+	ad := bluetooth.Advertisement{
+		Type:         a.adType,
+		LocalName:    a.values[adNameField],
+		Discoverable: a.discoverable,
+	}
+
+	for uuid := range strings.SplitSeq(a.values[adUUIDsField], ",") {
+		if uuid = strings.TrimSpace(uuid); uuid != "" {
+			ad.ServiceUUIDs = append(ad.ServiceUUIDs, uuid)
+		}
+	}
+
+	var err error
+	if v := a.values[adManufacturerField]; v != "" {
+		if ad.ManufacturerData, err = bluetooth.ParseManufacturerData(v); err != nil {
+			return bluetooth.Advertisement{}, err
+		}
+	}
+	if v := a.values[adServiceDataField]; v != "" {
+		if ad.ServiceData, err = bluetooth.ParseServiceData(v); err != nil {
+			return bluetooth.Advertisement{}, err
+		}
+	}
+	if v := a.values[adTxPowerField]; v != "" {
+		power, err := strconv.ParseInt(v, 10, 16)
+		if err != nil {
+			return bluetooth.Advertisement{}, fmt.Errorf("invalid TX power %q", v)
+		}
+		p := int16(power)
+		ad.TxPower = &p
+	}
+	if v := a.values[adAppearanceField]; v != "" {
+		appearance, err := strconv.ParseUint(v, 0, 16)
+		if err != nil {
+			return bluetooth.Advertisement{}, fmt.Errorf("invalid appearance %q", v)
+		}
+		ap := uint16(appearance)
+		ad.Appearance = &ap
+	}
+	if v := a.values[adTimeoutField]; v != "" {
+		seconds, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return bluetooth.Advertisement{}, fmt.Errorf("invalid timeout %q", v)
+		}
+		ad.Timeout = time.Duration(seconds) * time.Second
+	}
+
+	return ad, nil
+}
+
+// displayValue returns the value of a field as it's shown in the form.
+func (a *advertisingPane) displayValue(field adField) string {
+	switch field {
+	case adTypeField:
+		return string(a.adType)
+	case adDiscoverableField:
+		return onOff(a.discoverable)
+	}
+	if a.values[field] != "" {
+		return a.values[field]
+	}
+	return "-"
+}
+
+// advertisementSummary describes an active advertisement in one line.
+func advertisementSummary(active *bluetooth.ActiveAdvertisement) string {
+	ad := active.Advertisement
+	parts := []string{string(ad.Type)}
+	if ad.Type == "" {
+		parts[0] = string(bluetooth.AdvertisementPeripheral)
+	}
+	if ad.LocalName != "" {
+		parts = append(parts, strconv.Quote(ad.LocalName))
+	}
+	if len(ad.ServiceUUIDs) > 0 {
+		parts = append(parts, strings.Join(ad.ServiceUUIDs, ","))
+	}
+	// Maps are sorted, so the line doesn't shuffle on every redraw.
+	for _, id := range slices.Sorted(maps.Keys(ad.ManufacturerData)) {
+		parts = append(parts, fmt.Sprintf("%04x=%x", id, ad.ManufacturerData[id]))
+	}
+	for _, uuid := range slices.Sorted(maps.Keys(ad.ServiceData)) {
+		parts = append(parts, fmt.Sprintf("%s=%x", uuid, ad.ServiceData[uuid]))
+	}
+
+	since := "since " + active.Started.Format("15:04:05")
+	if ad.Timeout > 0 {
+		left := time.Until(active.Started.Add(ad.Timeout)).Round(time.Second)
+		since = fmt.Sprintf("%s left", max(left, 0))
+	}
+	return strings.Join(parts, " ") + " " + modalHintStyle.Render(since)
+}
+
+// View renders the form, the slots and the active advertisements.
+func (a *advertisingPane) View(advertisements []*bluetooth.ActiveAdvertisement) string {
+	labels := [adFieldCount]string{
+		adTypeField:         "Type",
+		adNameField:         "Local name",
+		adUUIDsField:        "Service UUIDs",
+		adManufacturerField: "Manufacturer data",
+		adServiceDataField:  "Service data",
+		adTxPowerField:      "TX power",
+		adAppearanceField:   "Appearance",
+		adDiscoverableField: "Discoverable",
+		adTimeoutField:      "Timeout",
+	}
+
+	lines := []string{modalTitleStyle.Render("New advertisement")}
+	for i, label := range labels {
+		field := adField(i)
+
+		value := a.displayValue(field)
+		if a.editing && i == a.cursor {
+			value = a.input.View()
+		}
+
+		cursor := "  "
+		if i == a.cursor {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+panelLabelStyle.Render(label)+value)
+	}
+
+	lines = append(lines, "", modalTitleStyle.Render("Active advertisements"))
+	if a.info != nil {
+		slots := fmt.Sprintf("%d active, %d free", a.info.ActiveInstances, a.info.SupportedInstances)
+		if len(a.info.SupportedIncludes) > 0 {
+			slots += " " + modalHintStyle.Render("(can include "+strings.Join(a.info.SupportedIncludes, ", ")+")")
+		}
+		lines = append(lines, panelLabelStyle.Render("Slots")+slots)
+	}
+	if len(advertisements) == 0 {
+		lines = append(lines, "None of ours.")
+	}
+	for i, ad := range advertisements {
+		cursor := "  "
+		if int(adFieldCount)+i == a.cursor {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+advertisementSummary(ad))
+	}
+
+	if a.err != nil {
+		lines = append(lines, "", errorStyle.Render(a.err.Error()))
+	} else if a.status != "" {
+		lines = append(lines, "", a.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// advertisingView renders the pane as a full screen.
+func (m model) advertisingView() string {
+	helpView := m.help.View(m.advertising.keys)
+	if m.advertising.editing {
+		helpView = modalHintStyle.Render("enter save • esc cancel")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Advertising"),
+		"",
+		m.advertising.View(m.advertisements),
+		"",
+		helpView,
+	))
+}
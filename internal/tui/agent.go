@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// agentRequestKind tells which of the pairing agent methods was called.
+type agentRequestKind int
+
+const (
+	requestPinCode agentRequestKind = iota
+	requestPasskey
+	displayPinCode
+	displayPasskey
+	requestConfirmation
+	requestAuthorization
+	authorizeService
+	cancelRequest
+)
+
+// agentRequest is a request made by BlueZ to our pairing agent, which is
+// shown to the user as a modal.
+type agentRequest struct {
+	kind    agentRequestKind
+	device  string
+	pinCode string
+	passkey uint32
+	entered uint16
+	uuid    string
+	// reply receives the answer of the user. It's nil for the requests that
+	// only show something.
+	reply chan agentReply
+}
+
+type agentReply struct {
+	value string
+	err   error
+}
+
+// agentRequestMsg is sent when BlueZ makes a request to our pairing agent.
+type agentRequestMsg agentRequest
+
+// tuiAgent is the bluetooth.AgentHandler used by the app. Its methods are
+// called from the D-Bus goroutines, so it hands every request over to the
+// model through a channel and waits for the user's answer.
+type tuiAgent struct {
+	requests chan agentRequest
+}
+
+func newTUIAgent() *tuiAgent {
+	return &tuiAgent{requests: make(chan agentRequest)}
+}
+
+// ask sends the request to the model and blocks until the user answers it.
+func (a *tuiAgent) ask(req agentRequest) agentReply {
+	req.reply = make(chan agentReply, 1)
+	a.requests <- req
+	return <-req.reply
+}
+
+func (a *tuiAgent) RequestPinCode(device string) (string, error) {
+	reply := a.ask(agentRequest{kind: requestPinCode, device: device})
+	return reply.value, reply.err
+}
+
+func (a *tuiAgent) DisplayPinCode(device, pinCode string) error {
+	a.requests <- agentRequest{kind: displayPinCode, device: device, pinCode: pinCode}
+	return nil
+}
+
+func (a *tuiAgent) RequestPasskey(device string) (uint32, error) {
+	reply := a.ask(agentRequest{kind: requestPasskey, device: device})
+	if reply.err != nil {
+		return 0, reply.err
+	}
+	passkey, err := strconv.ParseUint(reply.value, 10, 32)
+	if err != nil {
+		return 0, bluetooth.ErrRejected
+	}
+	return uint32(passkey), nil
+}
+
+func (a *tuiAgent) DisplayPasskey(device string, passkey uint32, entered uint16) {
+	a.requests <- agentRequest{kind: displayPasskey, device: device, passkey: passkey, entered: entered}
+}
+
+func (a *tuiAgent) RequestConfirmation(device string, passkey uint32) error {
+	return a.ask(agentRequest{kind: requestConfirmation, device: device, passkey: passkey}).err
+}
+
+func (a *tuiAgent) RequestAuthorization(device string) error {
+	return a.ask(agentRequest{kind: requestAuthorization, device: device}).err
+}
+
+func (a *tuiAgent) AuthorizeService(device, uuid string) error {
+	return a.ask(agentRequest{kind: authorizeService, device: device, uuid: uuid}).err
+}
+
+func (a *tuiAgent) Cancel() {
+	a.requests <- agentRequest{kind: cancelRequest}
+}
+
+// waitForAgentRequest waits for the next request made to our pairing agent.
+// It must be issued again after each agentRequestMsg to keep receiving them.
+func waitForAgentRequest(agent *tuiAgent) tea.Cmd {
+	return func() tea.Msg {
+		return agentRequestMsg(<-agent.requests)
+	}
+}
+
+// agentPrompt is the modal shown while answering a request of the pairing
+// agent.
+type agentPrompt struct {
+	req   agentRequest
+	input textinput.Model
+}
+
+func newAgentPrompt(req agentRequest) *agentPrompt {
+	p := &agentPrompt{req: req}
+
+	if req.kind == requestPinCode || req.kind == requestPasskey {
+		p.input = textinput.New()
+		p.input.Placeholder = "PIN code"
+		p.input.CharLimit = 16
+		if req.kind == requestPasskey {
+			p.input.Placeholder = "000000"
+			p.input.CharLimit = 6
+		}
+		p.input.Focus()
+	}
+
+	return p
+}
+
+// answer sends the user's answer back to the agent.
+func (p *agentPrompt) answer(value string, err error) {
+	if p.req.reply != nil {
+		p.req.reply <- agentReply{value: value, err: err}
+	}
+}
+
+// handleAgentRequest shows the request in a modal, or dismisses the current
+// one if BlueZ canceled it.
+func (m *model) handleAgentRequest(req agentRequest) tea.Cmd {
+	if req.kind == cancelRequest {
+		if m.prompt != nil {
+			m.prompt.answer("", bluetooth.ErrCanceled)
+			m.prompt = nil
+		}
+		return m.list.NewStatusMessage("Pairing request canceled by the device.")
+	}
+
+	// A new passkey display replaces the previous one, which has no answer
+	// pending anyway.
+	if m.prompt != nil {
+		m.prompt.answer("", bluetooth.ErrCanceled)
+	}
+	m.prompt = newAgentPrompt(req)
+
+	return textinput.Blink
+}
+
+// updateAgentPrompt handles the keys pressed while the modal is shown.
+func (m *model) updateAgentPrompt(msg tea.KeyMsg) tea.Cmd {
+	p := m.prompt
+
+	switch p.req.kind {
+	case requestPinCode, requestPasskey:
+		switch msg.Type {
+		case tea.KeyEnter:
+			p.answer(p.input.Value(), nil)
+			m.prompt = nil
+		case tea.KeyEsc:
+			p.answer("", bluetooth.ErrRejected)
+			m.prompt = nil
+		default:
+			var cmd tea.Cmd
+			p.input, cmd = p.input.Update(msg)
+			return cmd
+		}
+	case requestConfirmation, requestAuthorization, authorizeService:
+		switch msg.String() {
+		case "y", "Y", "enter":
+			p.answer("", nil)
+			m.prompt = nil
+		case "n", "N", "esc":
+			p.answer("", bluetooth.ErrRejected)
+			m.prompt = nil
+		}
+	default:
+		switch msg.Type {
+		case tea.KeyEnter, tea.KeyEsc:
+			m.prompt = nil
+		}
+	}
+
+	return nil
+}
+
+// View renders the modal.
+func (p *agentPrompt) View() string {
+	var body, hint string
+
+	switch p.req.kind {
+	case requestPinCode:
+		body = fmt.Sprintf("Enter the PIN code for %s\n\n%s", p.req.device, p.input.View())
+		hint = "enter confirm • esc reject"
+	case requestPasskey:
+		body = fmt.Sprintf("Enter the passkey shown by %s\n\n%s", p.req.device, p.input.View())
+		hint = "enter confirm • esc reject"
+	case displayPinCode:
+		body = fmt.Sprintf("Type this PIN code on %s\n\n%s", p.req.device, modalValueStyle.Render(p.req.pinCode))
+		hint = "esc close"
+	case displayPasskey:
+		body = fmt.Sprintf("Type this passkey on %s\n\n%s\n\n%d digits entered",
+			p.req.device, modalValueStyle.Render(fmt.Sprintf("%06d", p.req.passkey)), p.req.entered)
+		hint = "esc close"
+	case requestConfirmation:
+		body = fmt.Sprintf("Does %s show this passkey?\n\n%s",
+			p.req.device, modalValueStyle.Render(fmt.Sprintf("%06d", p.req.passkey)))
+		hint = "y confirm • n reject"
+	case requestAuthorization:
+		body = fmt.Sprintf("Allow %s to pair?", p.req.device)
+		hint = "y allow • n reject"
+	case authorizeService:
+		body = fmt.Sprintf("Allow %s to use service\n%s?", p.req.device, p.req.uuid)
+		hint = "y allow • n reject"
+	}
+
+	return modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		modalTitleStyle.Render("Pairing request"),
+		body,
+		"",
+		modalHintStyle.Render(hint),
+	))
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// volumeStep is how much +/- change the volume, out of MaxVolume. It's
+// roughly 6%, close to what phones do.
+const volumeStep = 8
+
+// transportsMsg carries the audio transports of a device.
+type transportsMsg struct {
+	address    string
+	transports []bluetooth.MediaTransport
+	err        error
+}
+
+// loadTransports fetches the audio transports of the device.
+func loadTransports(ctx context.Context, adapter bluetooth.Adapter, addr string) tea.Cmd {
+	return func() tea.Msg {
+		transports, err := adapter.Transports(ctx, addr)
+		return transportsMsg{address: addr, transports: transports, err: err}
+	}
+}
+
+// setVolume changes the volume of the device and fetches its transports
+// again, so the view shows what the device ended up with.
+func setVolume(ctx context.Context, adapter bluetooth.Adapter, addr string, volume uint16) tea.Cmd {
+	return func() tea.Msg {
+		if err := adapter.SetVolume(ctx, addr, volume); err != nil {
+			return transportsMsg{address: addr, err: err}
+		}
+		transports, err := adapter.Transports(ctx, addr)
+		return transportsMsg{address: addr, transports: transports, err: err}
+	}
+}
+
+// transportsChanged tells whether the event may have set up or torn down
+// audio streams, so they are worth fetching again.
+func transportsChanged(ev bluetooth.DeviceEvent) bool {
+	for _, name := range []string{"Connected", "ServicesResolved", "UUIDs"} {
+		if _, ok := ev.Changed[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTransports shows the transports in the detail view, as long as it's
+// still open on the same device.
+func (m *model) handleTransports(msg transportsMsg) {
+	if m.detail == nil || m.detail.address != msg.address {
+		return
+	}
+	if msg.err != nil {
+		m.detail.status = errorStyle.Render(msg.err.Error())
+		return
+	}
+	m.detail.transports = msg.transports
+}
+
+// changeVolume moves the volume of the device shown in the detail view by
+// delta, within 0 and MaxVolume.
+func (m *model) changeVolume(delta int) tea.Cmd {
+	d := m.detail
+
+	// The volume moves from the one of the transport SetVolume changes.
+	var volume int
+	if t, ok := bluetooth.MainTransport(d.transports); ok {
+		volume = int(t.Volume)
+	}
+	volume = min(max(volume+delta, 0), bluetooth.MaxVolume)
+
+	return setVolume(m.ctx, m.adapter, d.address, uint16(volume))
+}
+
+// transportsView renders the audio transports of the device, one per line.
+func transportsView(transports []bluetooth.MediaTransport) []string {
+	if len(transports) == 0 {
+		return []string{"No audio streams."}
+	}
+
+	lines := make([]string, 0, len(transports))
+	for _, t := range transports {
+		codec := t.Codec.String()
+		if t.CodecErr != nil {
+			codec += " " + modalHintStyle.Render(fmt.Sprintf("(% x)", t.Codec.Raw))
+		}
+
+		parts := []string{string(t.State), codec}
+		if t.HasVolume {
+			parts = append(parts, fmt.Sprintf("volume %s %d%%",
+				progressBar(10, float64(t.Volume)/bluetooth.MaxVolume), int(t.Volume)*100/bluetooth.MaxVolume))
+		}
+		lines = append(lines, panelLabelStyle.Render(bluetooth.ProfileName(t.UUID))+strings.Join(parts, "  "))
+	}
+	return lines
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchStartedMsg is sent once we are subscribed to the changes of the
+// adapter's devices.
+type watchStartedMsg struct {
+	events <-chan bluetooth.DeviceEvent
+}
+
+// deviceEventMsg wraps a change on one of the adapter's devices.
+type deviceEventMsg struct {
+	event  bluetooth.DeviceEvent
+	events <-chan bluetooth.DeviceEvent
+}
+
+// errMsg is sent when a command fails.
+type errMsg struct {
+	err error
+}
+
+// watchDevices subscribes to the device changes of the adapter.
+func watchDevices(ctx context.Context, adapter bluetooth.Adapter) tea.Cmd {
+	return func() tea.Msg {
+		events, err := adapter.Watch(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return watchStartedMsg{events}
+	}
+}
+
+// waitForDeviceEvent waits for the next change on the adapter's devices. It
+// must be issued again after each deviceEventMsg to keep receiving them.
+func waitForDeviceEvent(events <-chan bluetooth.DeviceEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		if !ok {
+			return nil
+		}
+		return deviceEventMsg{event: ev, events: events}
+	}
+}
+
+// registerAgent makes our agent answer the pairing requests of BlueZ.
+func registerAgent(ctx context.Context, adapter bluetooth.Adapter, agent *tuiAgent) tea.Cmd {
+	return func() tea.Msg {
+		if err := adapter.RegisterAgent(ctx, bluetooth.CapabilityKeyboardDisplay, agent); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmPrompt is a modal that asks the user to confirm an action before it
+// runs.
+type confirmPrompt struct {
+	title   string
+	message string
+	// onConfirm is called if the user confirms, and the command it returns
+	// is issued.
+	onConfirm func(*model) tea.Cmd
+}
+
+// updateConfirmPrompt handles the keys pressed while the modal is shown.
+func (m *model) updateConfirmPrompt(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		onConfirm := m.confirm.onConfirm
+		m.confirm = nil
+		return onConfirm(m)
+	case "n", "N", "esc":
+		m.confirm = nil
+	}
+	return nil
+}
+
+// View renders the modal.
+func (c *confirmPrompt) View() string {
+	return modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		modalTitleStyle.Render(c.title),
+		c.message,
+		"",
+		modalHintStyle.Render("y confirm • n cancel"),
+	))
+}
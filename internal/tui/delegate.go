@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// batteryGaugeCells is the number of cells of the battery gauge.
+const batteryGaugeCells = 5
+
+// deviceDelegate renders the devices like the default delegate of the list,
+// with a battery gauge next to the name of the ones that report it.
+type deviceDelegate struct {
+	list.DefaultDelegate
+}
+
+func newDeviceDelegate() deviceDelegate {
+	return deviceDelegate{DefaultDelegate: list.NewDefaultDelegate()}
+}
+
+// Render renders the item with the default delegate, and then adds the
+// gauge at the end of its title line.
+func (d deviceDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	var b strings.Builder
+	d.DefaultDelegate.Render(&b, m, index, item)
+
+	dev, ok := item.(bluetooth.Device)
+	if !ok {
+		_, _ = io.WriteString(w, b.String())
+		return
+	}
+	level, ok := dev.Battery()
+	if !ok {
+		_, _ = io.WriteString(w, b.String())
+		return
+	}
+
+	title, rest, _ := strings.Cut(b.String(), "\n")
+	_, _ = fmt.Fprintf(w, "%s  %s\n%s", title, batteryGauge(level), rest)
+}
+
+// batteryGauge renders the battery level as a row of cells followed by the
+// percentage, colored after how much charge is left.
+func batteryGauge(level uint8) string {
+	level = min(level, 100)
+	filled := (int(level)*batteryGaugeCells + 50) / 100
+
+	color := batteryHigh
+	switch {
+	case level <= 20:
+		color = batteryLow
+	case level <= 50:
+		color = batteryMid
+	}
+
+	gauge := strings.Repeat("▰", filled) + strings.Repeat("▱", batteryGaugeCells-filled)
+	return lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%s %d%%", gauge, level))
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// deviceDetail shows everything we know about a device, along with the
+// profiles it advertises, which can be connected one by one.
+type deviceDetail struct {
+	// address identifies the device. It's looked up in the list every time,
+	// so the view follows the changes reported by the watch.
+	address string
+	cursor  int
+	keys    detailKeyMap
+	// status is the last progress (or error) of the operations run on the
+	// device, since the status bar of the list isn't shown here.
+	status string
+	// transports are the audio streams of the device, fetched when the view
+	// opens and whenever they may have changed.
+	transports []bluetooth.MediaTransport
+}
+
+func newDeviceDetail(dev bluetooth.Device) *deviceDetail {
+	return &deviceDetail{address: dev.Address(), keys: newDetailKeyMap()}
+}
+
+// detailDevice returns the device shown in the detail view, which may be
+// gone if it was removed in the meantime.
+func (m model) detailDevice() (bluetooth.Device, bool) {
+	i := m.deviceIndex(m.detail.address)
+	if i < 0 {
+		return bluetooth.Device{}, false
+	}
+	dev, ok := m.list.Items()[i].(bluetooth.Device)
+	return dev, ok
+}
+
+// sortedUUIDs returns the UUIDs of the device in a stable order, so the
+// cursor doesn't jump around when they are reported again.
+func sortedUUIDs(dev bluetooth.Device) []string {
+	uuids := dev.UUIDs()
+	slices.Sort(uuids)
+	return uuids
+}
+
+// updateDeviceDetail handles the keys pressed while the detail view is shown.
+func (m *model) updateDeviceDetail(msg tea.KeyMsg) tea.Cmd {
+	d := m.detail
+
+	dev, ok := m.detailDevice()
+	if !ok {
+		// There's nothing left to show, so any key goes back.
+		m.detail = nil
+		return nil
+	}
+	uuids := sortedUUIDs(dev)
+
+	switch {
+	case key.Matches(msg, d.keys.back):
+		// Esc first aborts whatever runs on the device, like in the list.
+		if m.cancelOperation(dev) {
+			d.status = "Canceling..."
+			return nil
+		}
+		m.detail = nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case key.Matches(msg, d.keys.up):
+		if d.cursor > 0 {
+			d.cursor--
+		}
+	case key.Matches(msg, d.keys.down):
+		if d.cursor < len(uuids)-1 {
+			d.cursor++
+		}
+	case key.Matches(msg, d.keys.connect), key.Matches(msg, d.keys.disconnect):
+		if d.cursor >= len(uuids) {
+			return nil
+		}
+		uuid := uuids[d.cursor]
+
+		if key.Matches(msg, d.keys.connect) {
+			return m.runOperation(dev, bluetooth.OpConnectProfile, func(ctx context.Context, addr string) error {
+				return m.adapter.ConnectProfile(ctx, addr, uuid)
+			})
+		}
+		return m.runOperation(dev, bluetooth.OpDisconnectProfile, func(ctx context.Context, addr string) error {
+			return m.adapter.DisconnectProfile(ctx, addr, uuid)
+		})
+	}
+
+	return nil
+}
+
+// View renders the details of the device.
+func (d *deviceDetail) View(dev bluetooth.Device) string {
+	rssi := "-"
+	if v, ok := dev.RSSI(); ok {
+		rssi = fmt.Sprintf("%d dBm", v)
+	}
+
+	battery := "-"
+	if level, ok := dev.Battery(); ok {
+		battery = batteryGauge(level)
+		if source := dev.BatterySource(); source != "" {
+			battery += " (" + source + ")"
+		}
+	}
+
+	rows := []struct {
+		label string
+		value string
+	}{
+		{label: "Address", value: dev.Address() + " (" + dev.AddressType() + ")"},
+		{label: "Alias", value: dev.Alias()},
+		{label: "Icon", value: dev.Icon()},
+		{label: "RSSI", value: rssi},
+		{label: "Battery", value: battery},
+		{label: "Paired", value: onOff(dev.Paired())},
+		{label: "Trusted", value: onOff(dev.Trusted())},
+		{label: "Connected", value: onOff(dev.Connected())},
+	}
+
+	lines := make([]string, 0, len(rows)+4)
+	for _, row := range rows {
+		lines = append(lines, panelLabelStyle.Render(row.label)+row.value)
+	}
+
+	lines = append(lines, "", modalTitleStyle.Render("Profiles"))
+	uuids := sortedUUIDs(dev)
+	if len(uuids) == 0 {
+		lines = append(lines, "No profiles advertised.")
+	}
+	for i, uuid := range uuids {
+		cursor := "  "
+		if i == d.cursor {
+			cursor = "> "
+		}
+
+		name := "Unknown"
+		if p, ok := bluetooth.LookupProfile(uuid); ok {
+			name = p.Name
+		}
+		lines = append(lines, cursor+panelLabelStyle.Render(name)+modalHintStyle.Render(uuid))
+	}
+
+	lines = append(lines, "", modalTitleStyle.Render("Audio"))
+	lines = append(lines, transportsView(d.transports)...)
+
+	if d.status != "" {
+		lines = append(lines, "", d.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// detailView renders the detail view as a full screen.
+func (m model) detailView() string {
+	dev, ok := m.detailDevice()
+	if !ok {
+		return appStyle.Render("The device is gone.")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(dev.Name()),
+		"",
+		m.detail.View(dev),
+		"",
+		m.help.View(m.detail.keys),
+	))
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// applyDeviceEvent reflects a change on one of the adapter's devices in the
+// list.
+func (m *model) applyDeviceEvent(ev bluetooth.DeviceEvent) tea.Cmd {
+	idx := m.deviceIndex(ev.Device.Address())
+
+	switch ev.Type {
+	case bluetooth.DeviceAdded, bluetooth.DeviceUpdated:
+		if idx < 0 {
+			return m.list.InsertItem(len(m.list.Items()), ev.Device)
+		}
+		return m.list.SetItem(idx, ev.Device)
+	case bluetooth.DeviceRemoved:
+		if idx >= 0 {
+			m.list.RemoveItem(idx)
+		}
+	}
+
+	return nil
+}
+
+// deviceIndex returns the index in the list of the device with the given
+// address, or -1 if it's not there.
+func (m model) deviceIndex(addr string) int {
+	for i, item := range m.list.Items() {
+		if dev, ok := item.(bluetooth.Device); ok && dev.Address() == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectedDevice returns the device under the cursor, if any.
+func (m model) selectedDevice() (bluetooth.Device, bool) {
+	dev, ok := m.list.SelectedItem().(bluetooth.Device)
+	return dev, ok
+}
@@ -0,0 +1,333 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// discoveryFilterField is one of the fields of the discovery filter menu.
+type discoveryFilterField int
+
+const (
+	transportField discoveryFilterField = iota
+	rssiField
+	pathlossField
+	uuidsField
+	patternField
+	duplicateDataField
+	discoverableField
+	discoveryFilterFieldCount
+)
+
+// discoveryFilterMenu lets the user edit the filter used when discovering.
+type discoveryFilterMenu struct {
+	// filter is a working copy, which only replaces the one of the model
+	// when the user is done with the menu.
+	filter  bluetooth.DiscoveryFilter
+	cursor  discoveryFilterField
+	keys    discoveryFilterKeyMap
+	editing bool
+	input   textinput.Model
+	err     error
+}
+
+func newDiscoveryFilterMenu(filter bluetooth.DiscoveryFilter) *discoveryFilterMenu {
+	return &discoveryFilterMenu{filter: filter, keys: newDiscoveryFilterKeyMap()}
+}
+
+// updateDiscoveryFilterMenu handles the keys pressed while the menu is shown.
+func (m *model) updateDiscoveryFilterMenu(msg tea.KeyMsg) tea.Cmd {
+	f := m.filterMenu
+
+	if f.editing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			f.err = f.setValue(strings.TrimSpace(f.input.Value()))
+			f.editing = false
+		case tea.KeyEsc:
+			f.editing = false
+		default:
+			var cmd tea.Cmd
+			f.input, cmd = f.input.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, f.keys.up):
+		f.cursor = (f.cursor + discoveryFilterFieldCount - 1) % discoveryFilterFieldCount
+	case key.Matches(msg, f.keys.down):
+		f.cursor = (f.cursor + 1) % discoveryFilterFieldCount
+	case key.Matches(msg, f.keys.edit):
+		return f.edit()
+	case key.Matches(msg, f.keys.clear):
+		f.clear()
+	case key.Matches(msg, f.keys.cancel):
+		m.filterMenu = nil
+	case key.Matches(msg, f.keys.apply):
+		if err := f.filter.Validate(); err != nil {
+			f.err = err
+			return nil
+		}
+		m.discoveryFilter = f.filter
+		m.filterMenu = nil
+		return m.list.NewStatusMessage("Discovery filter updated.")
+	}
+
+	return nil
+}
+
+// edit toggles the field under the cursor, or starts editing it through the
+// text input.
+func (f *discoveryFilterMenu) edit() tea.Cmd {
+	switch f.cursor {
+	case transportField:
+		switch f.filter.Transport {
+		case "", bluetooth.TransportAuto:
+			f.filter.Transport = bluetooth.TransportLE
+		case bluetooth.TransportLE:
+			f.filter.Transport = bluetooth.TransportBREDR
+		default:
+			f.filter.Transport = ""
+		}
+		return nil
+	case duplicateDataField:
+		// Cycles through default, on and off.
+		switch {
+		case f.filter.DuplicateData == nil:
+			on := true
+			f.filter.DuplicateData = &on
+		case *f.filter.DuplicateData:
+			off := false
+			f.filter.DuplicateData = &off
+		default:
+			f.filter.DuplicateData = nil
+		}
+		return nil
+	case discoverableField:
+		f.filter.Discoverable = !f.filter.Discoverable
+		return nil
+	}
+
+	f.editing = true
+	f.err = nil
+	f.input = textinput.New()
+	f.input.SetValue(f.rawValue(f.cursor))
+	f.input.CursorEnd()
+	f.input.Focus()
+
+	switch f.cursor {
+	case rssiField:
+		f.input.Placeholder = "dBm, e.g. -70"
+	case pathlossField:
+		f.input.Placeholder = "dB"
+	case uuidsField:
+		f.input.Placeholder = "Comma-separated UUIDs"
+	case patternField:
+		f.input.Placeholder = "Name or address prefix"
+	}
+
+	return textinput.Blink
+}
+
+// setValue parses the value typed for the field under the cursor.
+func (f *discoveryFilterMenu) setValue(value string) error {
+	switch f.cursor {
+	case rssiField:
+		if value == "" {
+			f.filter.RSSI = 0
+			return nil
+		}
+		rssi, err := strconv.ParseInt(value, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid RSSI %q", value)
+		}
+		f.filter.RSSI = int16(rssi)
+	case pathlossField:
+		if value == "" {
+			f.filter.Pathloss = 0
+			return nil
+		}
+		pathloss, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid pathloss %q", value)
+		}
+		f.filter.Pathloss = uint16(pathloss)
+	case uuidsField:
+		f.filter.UUIDs = nil
+		for uuid := range strings.SplitSeq(value, ",") {
+			if uuid = strings.TrimSpace(uuid); uuid != "" {
+				f.filter.UUIDs = append(f.filter.UUIDs, uuid)
+			}
+		}
+	case patternField:
+		f.filter.Pattern = value
+	}
+	return nil
+}
+
+// clear resets the field under the cursor to its default.
+func (f *discoveryFilterMenu) clear() {
+	switch f.cursor {
+	case transportField:
+		f.filter.Transport = ""
+	case rssiField:
+		f.filter.RSSI = 0
+	case pathlossField:
+		f.filter.Pathloss = 0
+	case uuidsField:
+		f.filter.UUIDs = nil
+	case patternField:
+		f.filter.Pattern = ""
+	case duplicateDataField:
+		f.filter.DuplicateData = nil
+	case discoverableField:
+		f.filter.Discoverable = false
+	}
+	f.err = nil
+}
+
+// rawValue returns the value of a field as it's typed in the text input.
+func (f *discoveryFilterMenu) rawValue(field discoveryFilterField) string {
+	switch field {
+	case rssiField:
+		if f.filter.RSSI != 0 {
+			return strconv.Itoa(int(f.filter.RSSI))
+		}
+	case pathlossField:
+		if f.filter.Pathloss != 0 {
+			return strconv.Itoa(int(f.filter.Pathloss))
+		}
+	case uuidsField:
+		return strings.Join(f.filter.UUIDs, ",")
+	case patternField:
+		return f.filter.Pattern
+	}
+	return ""
+}
+
+// displayValue returns the value of a field as it's shown in the menu.
+func (f *discoveryFilterMenu) displayValue(field discoveryFilterField) string {
+	switch field {
+	case transportField:
+		if f.filter.Transport == "" {
+			return "auto"
+		}
+		return string(f.filter.Transport)
+	case duplicateDataField:
+		if f.filter.DuplicateData == nil {
+			return "default"
+		}
+		return onOff(*f.filter.DuplicateData)
+	case discoverableField:
+		return onOff(f.filter.Discoverable)
+	}
+
+	if v := f.rawValue(field); v != "" {
+		return v
+	}
+	return "-"
+}
+
+// View renders the menu.
+func (f *discoveryFilterMenu) View() string {
+	labels := [discoveryFilterFieldCount]string{
+		transportField:     "Transport",
+		rssiField:          "RSSI threshold",
+		pathlossField:      "Pathloss threshold",
+		uuidsField:         "Service UUIDs",
+		patternField:       "Name pattern",
+		duplicateDataField: "Duplicate data",
+		discoverableField:  "Discoverable only",
+	}
+
+	lines := make([]string, 0, len(labels))
+	for i, label := range labels {
+		field := discoveryFilterField(i)
+
+		value := f.displayValue(field)
+		if f.editing && field == f.cursor {
+			value = f.input.View()
+		}
+
+		cursor := "  "
+		if field == f.cursor {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+panelLabelStyle.Render(label)+value)
+	}
+
+	if f.err != nil {
+		lines = append(lines, "", errorStyle.Render(f.err.Error()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// discoveryFilterView renders the discovery filter menu as a full screen.
+func (m model) discoveryFilterView() string {
+	helpView := m.help.View(m.filterMenu.keys)
+	if m.filterMenu.editing {
+		helpView = modalHintStyle.Render("enter save • esc cancel")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Discovery filter"),
+		"",
+		m.filterMenu.View(),
+		"",
+		helpView,
+	))
+}
+
+// discoveryStartedMsg is sent once the adapter starts scanning.
+type discoveryStartedMsg struct {
+	session *bluetooth.DiscoverySession
+}
+
+// discoveredDeviceMsg is sent for every device found (or updated) while
+// discovering.
+type discoveredDeviceMsg struct {
+	device  bluetooth.Device
+	session *bluetooth.DiscoverySession
+}
+
+// discoverDoneMsg is sent when a discovery finishes.
+type discoverDoneMsg struct {
+	found int
+	err   error
+}
+
+// startDiscovery starts scanning for devices until the context is done.
+func startDiscovery(ctx context.Context, adapter bluetooth.Adapter, filter bluetooth.DiscoveryFilter) tea.Cmd {
+	return func() tea.Msg {
+		session, err := adapter.StartDiscovery(ctx, filter)
+		if err != nil {
+			return discoverDoneMsg{err: err}
+		}
+		return discoveryStartedMsg{session}
+	}
+}
+
+// waitForDiscoveredDevice waits for the next device found by the session. It
+// must be issued again after each discoveredDeviceMsg to keep receiving
+// them. Once the session ends, it reports what was found.
+func waitForDiscoveredDevice(session *bluetooth.DiscoverySession) tea.Cmd {
+	return func() tea.Msg {
+		dev, ok := <-session.Devices()
+		if !ok {
+			found, err := session.Stop()
+			return discoverDoneMsg{found: len(found), err: err}
+		}
+		return discoveredDeviceMsg{device: dev, session: session}
+	}
+}
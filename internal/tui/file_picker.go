@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// filePicker browses the local file system, one directory at a time. The
+// directories are listed first, and the hidden entries are left out.
+type filePicker struct {
+	dir     string
+	entries []os.DirEntry
+	cursor  int
+	err     error
+}
+
+// newFilePicker starts browsing dir, or the working directory if it's empty.
+func newFilePicker(dir string) *filePicker {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	p := &filePicker{dir: dir}
+	p.load()
+	return p
+}
+
+// load reads the current directory again.
+func (p *filePicker) load() {
+	entries, err := os.ReadDir(p.dir)
+	p.entries = slices.DeleteFunc(entries, func(e os.DirEntry) bool {
+		return strings.HasPrefix(e.Name(), ".")
+	})
+	slices.SortStableFunc(p.entries, func(a, b os.DirEntry) int {
+		switch {
+		case a.IsDir() == b.IsDir():
+			return 0
+		case a.IsDir():
+			return -1
+		default:
+			return 1
+		}
+	})
+	p.err = err
+	p.cursor = min(p.cursor, max(len(p.entries)-1, 0))
+}
+
+func (p *filePicker) up() {
+	if p.cursor > 0 {
+		p.cursor--
+	}
+}
+
+func (p *filePicker) down() {
+	if p.cursor < len(p.entries)-1 {
+		p.cursor++
+	}
+}
+
+// selected returns the entry under the cursor, if any.
+func (p *filePicker) selected() (os.DirEntry, bool) {
+	if p.cursor >= len(p.entries) {
+		return nil, false
+	}
+	return p.entries[p.cursor], true
+}
+
+// open enters the directory under the cursor, or returns the path of the file
+// under it.
+func (p *filePicker) open() (string, bool) {
+	e, ok := p.selected()
+	if !ok {
+		return "", false
+	}
+	path := filepath.Join(p.dir, e.Name())
+	if !e.IsDir() {
+		return path, true
+	}
+	p.dir, p.cursor = path, 0
+	p.load()
+	return "", false
+}
+
+// parent goes to the parent directory, with the cursor on the one we come
+// from.
+func (p *filePicker) parent() {
+	from := filepath.Base(p.dir)
+	p.dir = filepath.Dir(p.dir)
+	p.load()
+	p.cursor = max(slices.IndexFunc(p.entries, func(e os.DirEntry) bool { return e.Name() == from }), 0)
+}
+
+// View renders up to rows entries around the cursor. The cursor is only drawn
+// when focused, so that two pickers can sit side by side.
+func (p *filePicker) View(rows int, focused bool) string {
+	lines := []string{modalTitleStyle.Render(p.dir)}
+	if p.err != nil {
+		lines = append(lines, errorStyle.Render(p.err.Error()))
+	} else if len(p.entries) == 0 {
+		lines = append(lines, modalHintStyle.Render("(empty)"))
+	}
+
+	first := max(min(p.cursor-rows/2, len(p.entries)-rows), 0)
+	for i := first; i < min(first+rows, len(p.entries)); i++ {
+		e := p.entries[i]
+		cursor := "  "
+		if focused && i == p.cursor {
+			cursor = "> "
+		}
+		line := e.Name()
+		if e.IsDir() {
+			line = modalValueStyle.Render(line + "/")
+		} else if info, err := e.Info(); err == nil {
+			line += " " + modalHintStyle.Render(formatSize(uint64(info.Size())))
+		}
+		lines = append(lines, cursor+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSize renders a size in bytes with a binary unit.
+func formatSize(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,506 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ftpBrowser is a file manager with the local file system on the left, and
+// the one of a device, browsed through OBEX FTP, on the right. Files are
+// copied from the focused side to the other one.
+type ftpBrowser struct {
+	keys    ftpKeyMap
+	address string
+	name    string
+	local   *filePicker
+	// session is nil until connected.
+	session bluetooth.FileTransfer
+	remote  []bluetooth.RemoteFile
+	cursor  int
+	// remoteFocused tells which side the keys go to.
+	remoteFocused bool
+	// busy is set while a call to the device runs, which must not overlap
+	// with others since they all depend on the current folder.
+	busy bool
+	// creating is set while the name of a new folder is typed in input.
+	creating bool
+	input    textinput.Model
+	// transfer is nil until the device accepts it, and cancel is set from
+	// the moment a copy starts until it's over. download tells which side
+	// gets the file.
+	transfer *bluetooth.Transfer
+	cancel   context.CancelFunc
+	download bool
+	progress bluetooth.TransferProgress
+	status   string
+	err      error
+}
+
+func newFtpBrowser(dev bluetooth.Device) *ftpBrowser {
+	return &ftpBrowser{
+		keys:    newFtpKeyMap(),
+		address: dev.Address(),
+		name:    dev.Name(),
+		local:   newFilePicker(""),
+		busy:    true,
+		status:  "Connecting to " + dev.Name() + "...",
+	}
+}
+
+// copying tells whether a file is on its way.
+func (b *ftpBrowser) copying() bool {
+	return b.cancel != nil
+}
+
+// selectedRemote returns the remote entry under the cursor, if any.
+func (b *ftpBrowser) selectedRemote() (bluetooth.RemoteFile, bool) {
+	if b.cursor >= len(b.remote) {
+		return bluetooth.RemoteFile{}, false
+	}
+	return b.remote[b.cursor], true
+}
+
+// ftpConnectedMsg is sent once the FTP session is created.
+type ftpConnectedMsg struct {
+	browser *ftpBrowser
+	session bluetooth.FileTransfer
+	err     error
+}
+
+// ftpListMsg carries the entries of the current remote folder, listed after
+// an operation on the device, which is described by status.
+type ftpListMsg struct {
+	session bluetooth.FileTransfer
+	files   []bluetooth.RemoteFile
+	status  string
+	err     error
+}
+
+// ftpTransferMsg is sent once a copy is accepted, or refused.
+type ftpTransferMsg struct {
+	session  bluetooth.FileTransfer
+	transfer *bluetooth.Transfer
+	err      error
+}
+
+func connectFtp(ctx context.Context, obex bluetooth.Obex, browser *ftpBrowser) tea.Cmd {
+	return func() tea.Msg {
+		session, err := obex.FileTransfer(ctx, browser.address)
+		return ftpConnectedMsg{browser: browser, session: session, err: err}
+	}
+}
+
+// runFtp runs op on the device, if any, and lists the current folder
+// afterwards, since most operations change it.
+func runFtp(ctx context.Context, session bluetooth.FileTransfer, op func(context.Context) error, status string) tea.Cmd {
+	return func() tea.Msg {
+		if op != nil {
+			if err := op(ctx); err != nil {
+				// The folder may not have changed, but it's still
+				// listed, so that the browser is never stuck.
+				files, _ := session.ListFolder(ctx)
+				return ftpListMsg{session: session, files: files, err: err}
+			}
+		}
+		files, err := session.ListFolder(ctx)
+		return ftpListMsg{session: session, files: files, status: status, err: err}
+	}
+}
+
+func startFtpTransfer(session bluetooth.FileTransfer, start func() (*bluetooth.Transfer, error)) tea.Cmd {
+	return func() tea.Msg {
+		transfer, err := start()
+		return ftpTransferMsg{session: session, transfer: transfer, err: err}
+	}
+}
+
+// closeFtpSession disconnects from the device. It runs after the browser is
+// gone, so errors only make it to the status bar.
+func closeFtpSession(ctx context.Context, session bluetooth.FileTransfer) tea.Cmd {
+	return func() tea.Msg {
+		if err := session.Close(ctx); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// handleFtpMsg handles the messages of the browser. The ones of a browser
+// that was already closed are dropped.
+func (m *model) handleFtpMsg(msg tea.Msg) tea.Cmd {
+	b := m.ftp
+
+	switch msg := msg.(type) {
+	case ftpConnectedMsg:
+		if b != msg.browser {
+			// The browser was closed while we were connecting.
+			if msg.session != nil {
+				return closeFtpSession(m.ctx, msg.session)
+			}
+			return nil
+		}
+		if msg.err != nil {
+			b.busy, b.status, b.err = false, "", msg.err
+			return nil
+		}
+		b.session = msg.session
+		return runFtp(m.ctx, b.session, nil, "Connected to "+b.name+".")
+	case ftpListMsg:
+		if b == nil || b.session != msg.session {
+			return nil
+		}
+		b.busy = false
+		b.remote = msg.files
+		b.cursor = min(b.cursor, max(len(b.remote)-1, 0))
+		b.status, b.err = msg.status, msg.err
+	case ftpTransferMsg:
+		if b == nil || b.session != msg.session {
+			return nil
+		}
+		if msg.err != nil {
+			return b.finish(m.ctx, msg.err)
+		}
+		b.transfer = msg.transfer
+		return waitForTransferProgress(msg.transfer)
+	case transferProgressMsg:
+		if b == nil || b.transfer != msg.transfer {
+			return nil
+		}
+		b.progress = msg.progress
+		return waitForTransferProgress(msg.transfer)
+	case transferDoneMsg:
+		if b == nil || b.transfer != msg.transfer {
+			return nil
+		}
+		return b.finish(m.ctx, msg.err)
+	}
+	return nil
+}
+
+// finish reports how the copy went, and refreshes the side that got the file.
+func (b *ftpBrowser) finish(ctx context.Context, err error) tea.Cmd {
+	b.cancel()
+	b.cancel, b.transfer = nil, nil
+	switch {
+	case errors.Is(err, context.Canceled):
+		b.status = "Canceled."
+	case err != nil:
+		b.status, b.err = "", err
+	default:
+		b.status = "Copied."
+	}
+
+	if b.download {
+		b.local.load()
+		return nil
+	}
+	b.busy = true
+	return runFtp(ctx, b.session, nil, b.status)
+}
+
+// updateFtpBrowser handles the keys pressed while the browser is shown.
+func (m *model) updateFtpBrowser(msg tea.KeyMsg) tea.Cmd {
+	b := m.ftp
+
+	if b.creating {
+		switch msg.Type {
+		case tea.KeyEnter:
+			b.creating = false
+			return m.createFolder(strings.TrimSpace(b.input.Value()))
+		case tea.KeyEsc:
+			b.creating = false
+			return nil
+		}
+		var cmd tea.Cmd
+		b.input, cmd = b.input.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, b.keys.back):
+		// The first esc cancels the copy, the second one goes back.
+		if b.copying() {
+			b.cancel()
+			b.status = "Canceling..."
+			return nil
+		}
+		m.ftp = nil
+		if b.session != nil {
+			return closeFtpSession(m.ctx, b.session)
+		}
+		return nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case key.Matches(msg, b.keys.switchSide):
+		b.remoteFocused = !b.remoteFocused
+		return nil
+	case b.copying():
+		// Nothing else until the copy is over.
+		return nil
+	}
+
+	if b.remoteFocused {
+		return m.updateFtpRemote(msg)
+	}
+
+	switch {
+	case key.Matches(msg, b.keys.up):
+		b.local.up()
+	case key.Matches(msg, b.keys.down):
+		b.local.down()
+	case key.Matches(msg, b.keys.parent):
+		b.local.parent()
+	case key.Matches(msg, b.keys.open):
+		// Files are only copied with the copy key.
+		if e, ok := b.local.selected(); ok && e.IsDir() {
+			b.local.open()
+		}
+	case key.Matches(msg, b.keys.reload):
+		b.local.load()
+	case key.Matches(msg, b.keys.mkdir):
+		return b.startCreating()
+	case key.Matches(msg, b.keys.delete):
+		e, ok := b.local.selected()
+		if !ok {
+			return nil
+		}
+		path := filepath.Join(b.local.dir, e.Name())
+		m.confirm = &confirmPrompt{
+			title:   "Delete local file",
+			message: "Delete " + path + "?\nFolders must be empty.",
+			onConfirm: func(m *model) tea.Cmd {
+				if m.ftp != b {
+					return nil
+				}
+				b.err = os.Remove(path)
+				if b.err == nil {
+					b.status = "Deleted " + e.Name() + "."
+				}
+				b.local.load()
+				return nil
+			},
+		}
+	case key.Matches(msg, b.keys.copy):
+		e, ok := b.local.selected()
+		if !ok || b.session == nil || b.busy {
+			return nil
+		}
+		if e.IsDir() {
+			b.err = errors.New("only files can be copied")
+			return nil
+		}
+		path := filepath.Join(b.local.dir, e.Name())
+		return m.startFtpCopy(false, e.Name(), func(ctx context.Context) (*bluetooth.Transfer, error) {
+			return b.session.PutFile(ctx, path, e.Name())
+		})
+	}
+	return nil
+}
+
+// updateFtpRemote handles the keys pressed while the remote side is focused.
+// They are ignored while waiting for the device.
+func (m *model) updateFtpRemote(msg tea.KeyMsg) tea.Cmd {
+	b := m.ftp
+	if b.session == nil || b.busy {
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, b.keys.up):
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case key.Matches(msg, b.keys.down):
+		if b.cursor < len(b.remote)-1 {
+			b.cursor++
+		}
+	case key.Matches(msg, b.keys.parent):
+		if b.session.Folder() == "/" {
+			return nil
+		}
+		b.busy, b.cursor = true, 0
+		return runFtp(m.ctx, b.session, func(ctx context.Context) error {
+			return b.session.ChangeFolder(ctx, "..")
+		}, "")
+	case key.Matches(msg, b.keys.open):
+		f, ok := b.selectedRemote()
+		if !ok || !f.Folder {
+			return nil
+		}
+		b.busy, b.cursor = true, 0
+		return runFtp(m.ctx, b.session, func(ctx context.Context) error {
+			return b.session.ChangeFolder(ctx, f.Name)
+		}, "")
+	case key.Matches(msg, b.keys.reload):
+		b.busy = true
+		return runFtp(m.ctx, b.session, nil, "")
+	case key.Matches(msg, b.keys.mkdir):
+		return b.startCreating()
+	case key.Matches(msg, b.keys.delete):
+		f, ok := b.selectedRemote()
+		if !ok {
+			return nil
+		}
+		m.confirm = &confirmPrompt{
+			title:   "Delete remote file",
+			message: "Delete " + f.Name + " from " + b.name + "?\nFolders must be empty.",
+			onConfirm: func(m *model) tea.Cmd {
+				if m.ftp != b {
+					return nil
+				}
+				b.busy = true
+				return runFtp(m.ctx, b.session, func(ctx context.Context) error {
+					return b.session.Delete(ctx, f.Name)
+				}, "Deleted "+f.Name+".")
+			},
+		}
+	case key.Matches(msg, b.keys.copy):
+		f, ok := b.selectedRemote()
+		if !ok {
+			return nil
+		}
+		if f.Folder {
+			b.err = errors.New("only files can be copied")
+			return nil
+		}
+		// obexd would overwrite the file without asking.
+		path := filepath.Join(b.local.dir, f.Name)
+		if _, err := os.Stat(path); err == nil {
+			b.err = fmt.Errorf("%s already exists", path)
+			return nil
+		}
+		return m.startFtpCopy(true, f.Name, func(ctx context.Context) (*bluetooth.Transfer, error) {
+			return b.session.GetFile(ctx, f.Name, path)
+		})
+	}
+	return nil
+}
+
+// startFtpCopy starts copying a file in the given direction.
+func (m *model) startFtpCopy(download bool, name string, start func(context.Context) (*bluetooth.Transfer, error)) tea.Cmd {
+	b := m.ftp
+	ctx, cancel := context.WithCancel(m.ctx)
+	b.cancel, b.download = cancel, download
+	b.progress = bluetooth.TransferProgress{}
+	b.status, b.err = "Copying "+name+"...", nil
+	return startFtpTransfer(b.session, func() (*bluetooth.Transfer, error) { return start(ctx) })
+}
+
+func (b *ftpBrowser) startCreating() tea.Cmd {
+	if b.remoteFocused && (b.session == nil || b.busy) {
+		return nil
+	}
+	b.creating = true
+	b.input = textinput.New()
+	b.input.Placeholder = "folder name"
+	b.input.Focus()
+	return textinput.Blink
+}
+
+// createFolder creates a folder on the focused side.
+func (m *model) createFolder(name string) tea.Cmd {
+	b := m.ftp
+	if name == "" {
+		return nil
+	}
+
+	if !b.remoteFocused {
+		b.err = os.Mkdir(filepath.Join(b.local.dir, name), 0o755)
+		if b.err == nil {
+			b.status = "Created " + name + "."
+		}
+		b.local.load()
+		return nil
+	}
+
+	b.busy = true
+	return runFtp(m.ctx, b.session, func(ctx context.Context) error {
+		return b.session.CreateFolder(ctx, name)
+	}, "Created "+name+".")
+}
+
+// remoteView renders the current folder of the device.
+func (b *ftpBrowser) remoteView(rows int) string {
+	folder := "/"
+	if b.session != nil {
+		folder = b.session.Folder()
+	}
+	lines := []string{modalTitleStyle.Render(b.name + ":" + folder)}
+	if b.session != nil && !b.busy && len(b.remote) == 0 {
+		lines = append(lines, modalHintStyle.Render("(empty)"))
+	}
+
+	first := max(min(b.cursor-rows/2, len(b.remote)-rows), 0)
+	for i := first; i < min(first+rows, len(b.remote)); i++ {
+		f := b.remote[i]
+		cursor := "  "
+		if b.remoteFocused && i == b.cursor {
+			cursor = "> "
+		}
+		line := f.Name
+		if f.Folder {
+			line = modalValueStyle.Render(line + "/")
+		} else {
+			line += " " + modalHintStyle.Render(formatSize(f.Size))
+		}
+		lines = append(lines, cursor+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// View renders both sides, and the progress of the copy.
+func (b *ftpBrowser) View(width, rows int) string {
+	side := lipgloss.NewStyle().Width(width / 2).MaxWidth(width / 2)
+	lines := []string{
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			side.Render(b.local.View(rows, !b.remoteFocused)),
+			side.Render(b.remoteView(rows)),
+		),
+		"",
+	}
+
+	if b.progress.Status != "" {
+		lines = append(lines, transferView(b.progress))
+	}
+	if b.creating {
+		lines = append(lines, panelLabelStyle.Render("New folder")+b.input.View())
+	}
+	if b.err != nil {
+		lines = append(lines, errorStyle.Render(b.err.Error()))
+	} else if b.status != "" {
+		lines = append(lines, b.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ftpView renders the browser as a full screen.
+func (m model) ftpView() string {
+	helpView := m.help.View(m.ftp.keys)
+	switch {
+	case m.ftp.creating:
+		helpView = modalHintStyle.Render("enter create • esc cancel")
+	case m.ftp.copying():
+		helpView = modalHintStyle.Render("tab switch side • esc cancel copy")
+	}
+
+	h, _ := appStyle.GetFrameSize()
+	rows := max(m.height-12, 5)
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Files of "+m.ftp.name),
+		"",
+		m.ftp.View(m.width-h, rows),
+		"",
+		helpView,
+	))
+}
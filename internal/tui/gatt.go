@@ -0,0 +1,382 @@
+package tui
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gattRowKind tells what a row of the GATT browser shows.
+type gattRowKind int
+
+const (
+	serviceRow gattRowKind = iota
+	characteristicRow
+	descriptorRow
+)
+
+// gattRow is one line of the tree of the GATT browser.
+type gattRow struct {
+	kind  gattRowKind
+	path  string
+	name  string
+	uuid  string
+	flags []string
+	// canRead, canWrite and canNotify are only set for characteristics. Descriptors
+	// are read no matter their flags, BlueZ tells us if it can't.
+	canRead   bool
+	canWrite  bool
+	canNotify bool
+}
+
+// gattBrowser shows the services of a device as a tree of services,
+// characteristics and descriptors, which can be read and written.
+type gattBrowser struct {
+	address string
+	name    string
+	rows    []gattRow
+	loaded  bool
+	cursor  int
+	keys    gattKeyMap
+	// values are the values of the characteristics and descriptors, by
+	// path. They start with what BlueZ has cached and follow reads and
+	// writes.
+	values map[string][]byte
+	// writing is set while the value to write is typed in input.
+	writing      bool
+	withResponse bool
+	input        textinput.Model
+	status       string
+	err          error
+	// subscriptions are the characteristics whose notifications are
+	// followed, by path, and notifications the last ones we got from them.
+	subscriptions map[string]*gattSubscription
+	notifications []bluetooth.GattNotification
+	format        valueFormat
+	// logFile is where notifications are logged to, if enabled.
+	logFile *os.File
+}
+
+func newGattBrowser(dev bluetooth.Device) *gattBrowser {
+	return &gattBrowser{
+		address:       dev.Address(),
+		name:          dev.Name(),
+		keys:          newGattKeyMap(),
+		values:        make(map[string][]byte),
+		subscriptions: make(map[string]*gattSubscription),
+	}
+}
+
+// gattServicesMsg carries the GATT database of a device.
+type gattServicesMsg struct {
+	address  string
+	services []bluetooth.GattService
+	err      error
+}
+
+// gattValueMsg carries the result of reading or writing an attribute.
+type gattValueMsg struct {
+	path  string
+	value []byte
+	wrote bool
+	err   error
+}
+
+func loadGattServices(ctx context.Context, adapter bluetooth.Adapter, addr string) tea.Cmd {
+	return func() tea.Msg {
+		services, err := adapter.Services(ctx, addr)
+		return gattServicesMsg{address: addr, services: services, err: err}
+	}
+}
+
+func readGattValue(ctx context.Context, adapter bluetooth.Adapter, row gattRow) tea.Cmd {
+	return func() tea.Msg {
+		read := adapter.ReadCharacteristic
+		if row.kind == descriptorRow {
+			read = adapter.ReadDescriptor
+		}
+		value, err := read(ctx, row.path)
+		return gattValueMsg{path: row.path, value: value, err: err}
+	}
+}
+
+func writeGattValue(ctx context.Context, adapter bluetooth.Adapter, path string, value []byte, withResponse bool) tea.Cmd {
+	return func() tea.Msg {
+		err := adapter.WriteCharacteristic(ctx, path, value, withResponse)
+		return gattValueMsg{path: path, value: value, wrote: true, err: err}
+	}
+}
+
+// setServices flattens the services into the rows of the tree.
+func (g *gattBrowser) setServices(services []bluetooth.GattService) {
+	g.rows = g.rows[:0]
+	for _, s := range services {
+		g.rows = append(g.rows, gattRow{kind: serviceRow, path: s.Path, name: s.Name(), uuid: s.UUID})
+		for _, c := range s.Characteristics {
+			g.rows = append(g.rows, gattRow{
+				kind: characteristicRow, path: c.Path, name: c.Name(), uuid: c.UUID, flags: c.Flags,
+				canRead: c.CanRead(), canWrite: c.CanWrite(), canNotify: c.HasFlag("notify") || c.HasFlag("indicate"),
+			})
+			if len(c.Value) > 0 {
+				g.values[c.Path] = c.Value
+			}
+			for _, d := range c.Descriptors {
+				g.rows = append(g.rows, gattRow{kind: descriptorRow, path: d.Path, name: d.Name(), uuid: d.UUID, flags: d.Flags})
+				if len(d.Value) > 0 {
+					g.values[d.Path] = d.Value
+				}
+			}
+		}
+	}
+	g.cursor = min(g.cursor, max(len(g.rows)-1, 0))
+	g.loaded = true
+}
+
+// handleGattMsg handles the results of the commands of the browser. The
+// ones for a browser that was already closed are dropped.
+func (m *model) handleGattMsg(msg tea.Msg) {
+	g := m.gatt
+	if g == nil {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case gattServicesMsg:
+		if msg.address != g.address {
+			return
+		}
+		g.err = msg.err
+		if msg.err == nil {
+			g.setServices(msg.services)
+			g.status = fmt.Sprintf("%d attributes.", len(g.rows))
+		}
+	case gattValueMsg:
+		g.err = msg.err
+		if msg.err != nil {
+			return
+		}
+		g.values[msg.path] = msg.value
+		if msg.wrote {
+			g.status = "Written."
+		} else {
+			g.status = "Read."
+		}
+	}
+}
+
+// updateGattBrowser handles the keys pressed while the browser is shown.
+func (m *model) updateGattBrowser(msg tea.KeyMsg) tea.Cmd {
+	g := m.gatt
+
+	if g.writing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			g.writing = false
+			value, err := parseGattValue(g.input.Value())
+			if err != nil {
+				g.err = err
+				return nil
+			}
+			g.status, g.err = "Writing...", nil
+			return writeGattValue(m.ctx, m.adapter, g.rows[g.cursor].path, value, g.withResponse)
+		case tea.KeyEsc:
+			g.writing = false
+			return nil
+		}
+		var cmd tea.Cmd
+		g.input, cmd = g.input.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, g.keys.back):
+		g.stopNotifications()
+		m.gatt = nil
+		return nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case key.Matches(msg, g.keys.up):
+		if g.cursor > 0 {
+			g.cursor--
+		}
+	case key.Matches(msg, g.keys.down):
+		if g.cursor < len(g.rows)-1 {
+			g.cursor++
+		}
+	case key.Matches(msg, g.keys.format):
+		g.format = g.format.next()
+		return nil
+	case key.Matches(msg, g.keys.log):
+		g.err = g.toggleLog()
+		return nil
+	case key.Matches(msg, g.keys.reload):
+		g.status, g.err = "Loading services...", nil
+		return loadGattServices(m.ctx, m.adapter, g.address)
+	}
+
+	if g.cursor >= len(g.rows) {
+		return nil
+	}
+	row := g.rows[g.cursor]
+
+	switch {
+	case key.Matches(msg, g.keys.read):
+		if row.kind == serviceRow || (row.kind == characteristicRow && !row.canRead) {
+			g.err = errors.New("this attribute can't be read")
+			return nil
+		}
+		g.status, g.err = "Reading...", nil
+		return readGattValue(m.ctx, m.adapter, row)
+	case key.Matches(msg, g.keys.write), key.Matches(msg, g.keys.writeCommand):
+		if row.kind != characteristicRow || !row.canWrite {
+			g.err = errors.New("this attribute can't be written")
+			return nil
+		}
+		g.writing, g.withResponse = true, key.Matches(msg, g.keys.write)
+		g.input = textinput.New()
+		g.input.Placeholder = `hex (01 ff) or "text"`
+		g.input.Focus()
+		return textinput.Blink
+	case key.Matches(msg, g.keys.notify):
+		if row.kind != characteristicRow {
+			g.err = errors.New("only characteristics notify")
+			return nil
+		}
+		return m.toggleNotify(row)
+	}
+
+	return nil
+}
+
+// parseGattValue parses the value typed to write a characteristic: either
+// hex bytes, optionally separated by spaces or colons and prefixed by 0x,
+// or text between double quotes.
+func parseGattValue(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return []byte(s[1 : len(s)-1]), nil
+	}
+
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	s = strings.NewReplacer(" ", "", ":", "").Replace(s)
+	value, err := hex.DecodeString(s)
+	if err != nil || len(value) == 0 {
+		return nil, fmt.Errorf("invalid value %q, expected hex bytes or quoted text", s)
+	}
+	return value, nil
+}
+
+// formatGattValue renders a value as hex, followed by its text when all of
+// it is printable, which is common for the string characteristics.
+func formatGattValue(value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+
+	s := fmt.Sprintf("% x", value)
+	text := string(value)
+	printable := true
+	for _, r := range text {
+		if !unicode.IsPrint(r) {
+			printable = false
+			break
+		}
+	}
+	if printable {
+		s += fmt.Sprintf(" %q", text)
+	}
+	return s
+}
+
+// View renders the tree. Only the rows around the cursor that fit in height
+// are shown.
+func (g *gattBrowser) View(height int) string {
+	var lines []string
+
+	switch {
+	case !g.loaded && g.err == nil:
+		lines = append(lines, "Loading services...")
+	case g.loaded && len(g.rows) == 0:
+		lines = append(lines, "No services found.")
+	}
+
+	// The pane of the notifications takes its room from the tree.
+	visible := max(height-len(g.notificationsView()), 5)
+	start := min(max(g.cursor-visible/2, 0), max(len(g.rows)-visible, 0))
+	end := min(start+visible, len(g.rows))
+
+	for i := start; i < end; i++ {
+		row := g.rows[i]
+		cursor := "  "
+		if i == g.cursor {
+			cursor = "> "
+		}
+
+		var line string
+		switch row.kind {
+		case serviceRow:
+			line = modalTitleStyle.Render(row.name)
+		case characteristicRow:
+			line = "  " + row.name
+		case descriptorRow:
+			line = "    " + modalHintStyle.Render(row.name)
+		}
+		if row.name != row.uuid {
+			line += " " + modalHintStyle.Render(row.uuid)
+		}
+		if len(row.flags) > 0 && row.kind == characteristicRow {
+			line += " " + modalHintStyle.Render("["+strings.Join(row.flags, ", ")+"]")
+		}
+		if _, ok := g.subscriptions[row.path]; ok {
+			line += " " + modalValueStyle.Render("●")
+		}
+		if v := formatGattValue(g.values[row.path]); v != "" {
+			line += " " + modalValueStyle.Render(v)
+		}
+		lines = append(lines, cursor+line)
+	}
+
+	lines = append(lines, g.notificationsView()...)
+
+	if g.writing {
+		label := "Write"
+		if !g.withResponse {
+			label = "Write without response"
+		}
+		lines = append(lines, "", panelLabelStyle.Render(label)+g.input.View())
+	}
+	if g.err != nil {
+		lines = append(lines, "", errorStyle.Render(g.err.Error()))
+	} else if g.status != "" {
+		lines = append(lines, "", g.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// gattView renders the browser as a full screen.
+func (m model) gattView() string {
+	helpView := m.help.View(m.gatt.keys)
+	if m.gatt.writing {
+		helpView = modalHintStyle.Render("enter write • esc cancel")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("GATT of "+m.gatt.name),
+		"",
+		// Leave room for the title, the help and the status.
+		m.gatt.View(m.height-10),
+		"",
+		helpView,
+	))
+}
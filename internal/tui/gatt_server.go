@@ -0,0 +1,399 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxGattServerRequests is how many of the last requests the pane keeps.
+const maxGattServerRequests = 10
+
+// gattServerRequest is a read or a write made by a central to our GATT
+// server.
+type gattServerRequest struct {
+	write bool
+	req   bluetooth.GattRequest
+	value []byte
+	time  time.Time
+}
+
+// tuiGattHandler is the bluetooth.GattServerHandler used by the app. It
+// serves the values as they are and hands every request over to the model
+// to be logged. Its methods are called from the D-Bus goroutines, which
+// can't wait for the model, so requests are dropped if it falls behind.
+type tuiGattHandler struct {
+	requests chan gattServerRequest
+	// done is closed with the pane, to stop waiting for requests.
+	done chan struct{}
+}
+
+func newTUIGattHandler() *tuiGattHandler {
+	return &tuiGattHandler{requests: make(chan gattServerRequest, 32), done: make(chan struct{})}
+}
+
+func (h *tuiGattHandler) log(r gattServerRequest) {
+	r.time = time.Now()
+	select {
+	case h.requests <- r:
+	default:
+	}
+}
+
+func (h *tuiGattHandler) stop() {
+	close(h.done)
+}
+
+func (h *tuiGattHandler) ReadValue(req bluetooth.GattRequest, value []byte) ([]byte, error) {
+	h.log(gattServerRequest{req: req, value: value})
+	return value, nil
+}
+
+func (h *tuiGattHandler) WriteValue(req bluetooth.GattRequest, value []byte) error {
+	h.log(gattServerRequest{write: true, req: req, value: value})
+	return nil
+}
+
+// gattServerPane serves the services of a definition file while it's open,
+// and logs the requests of the centrals.
+type gattServerPane struct {
+	keys    gattServerKeyMap
+	handler *tuiGattHandler
+	// app is nil until the services are served. Until then, input takes
+	// the path of the definition file.
+	app    bluetooth.GattApplication
+	file   string
+	input  textinput.Model
+	rows   []gattRow
+	values map[string][]byte
+	cursor int
+	// setting is set while the value of the selected characteristic is
+	// typed in input.
+	setting  bool
+	requests []gattServerRequest
+	format   valueFormat
+	status   string
+	err      error
+}
+
+func newGattServerPane() *gattServerPane {
+	input := textinput.New()
+	input.Placeholder = "gatt.json"
+	input.Focus()
+	return &gattServerPane{
+		keys:    newGattServerKeyMap(),
+		handler: newTUIGattHandler(),
+		input:   input,
+		values:  make(map[string][]byte),
+	}
+}
+
+// gattServeMsg is sent once the services of the definition are served.
+type gattServeMsg struct {
+	handler *tuiGattHandler
+	app     bluetooth.GattApplication
+	err     error
+}
+
+// gattServerRequestMsg carries a request made to our GATT server.
+type gattServerRequestMsg struct {
+	handler *tuiGattHandler
+	request gattServerRequest
+}
+
+// gattServerValueMsg carries the result of setting the value of one of our
+// characteristics.
+type gattServerValueMsg struct {
+	app bluetooth.GattApplication
+	err error
+}
+
+func serveGatt(ctx context.Context, adapter bluetooth.Adapter, file string, handler *tuiGattHandler) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Open(file)
+		if err != nil {
+			return gattServeMsg{handler: handler, err: fmt.Errorf("failed to open GATT server definition: %w", err)}
+		}
+		defer f.Close()
+
+		def, err := bluetooth.ReadGattServerDefinition(f)
+		if err != nil {
+			return gattServeMsg{handler: handler, err: err}
+		}
+		app, err := adapter.ServeGatt(ctx, def, handler)
+		return gattServeMsg{handler: handler, app: app, err: err}
+	}
+}
+
+// waitForGattServerRequest waits for the next request made to our GATT
+// server. It must be issued again after each gattServerRequestMsg to keep
+// receiving them.
+func waitForGattServerRequest(handler *tuiGattHandler) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case r := <-handler.requests:
+			return gattServerRequestMsg{handler: handler, request: r}
+		case <-handler.done:
+			return nil
+		}
+	}
+}
+
+func setGattServerValue(app bluetooth.GattApplication, path string, value []byte) tea.Cmd {
+	return func() tea.Msg {
+		return gattServerValueMsg{app: app, err: app.SetValue(path, value)}
+	}
+}
+
+// closeGattServer stops serving the services. It runs after the pane is gone,
+// so errors only make it to the log.
+func closeGattServer(ctx context.Context, app bluetooth.GattApplication) tea.Cmd {
+	return func() tea.Msg {
+		if err := app.Close(context.WithoutCancel(ctx)); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// refresh rebuilds the rows and values out of the services served, which
+// follow the writes of the centrals.
+func (s *gattServerPane) refresh() {
+	s.rows = s.rows[:0]
+	for _, svc := range s.app.Services() {
+		s.rows = append(s.rows, gattRow{kind: serviceRow, path: svc.Path, name: svc.Name(), uuid: svc.UUID})
+		for _, c := range svc.Characteristics {
+			s.rows = append(s.rows, gattRow{
+				kind: characteristicRow, path: c.Path, name: c.Name(), uuid: c.UUID, flags: c.Flags,
+				canNotify: c.HasFlag("notify") || c.HasFlag("indicate"),
+			})
+			s.values[c.Path] = c.Value
+			for _, d := range c.Descriptors {
+				s.rows = append(s.rows, gattRow{kind: descriptorRow, path: d.Path, name: d.Name(), uuid: d.UUID, flags: d.Flags})
+				s.values[d.Path] = d.Value
+			}
+		}
+	}
+	s.cursor = min(s.cursor, max(len(s.rows)-1, 0))
+}
+
+// rowName returns the name of the attribute at path.
+func (s *gattServerPane) rowName(path string) string {
+	for _, row := range s.rows {
+		if row.path == path {
+			return row.name
+		}
+	}
+	return path
+}
+
+// handleGattServerMsg handles the messages of the pane. The ones of a pane
+// that was already closed are dropped.
+func (m *model) handleGattServerMsg(msg tea.Msg) tea.Cmd {
+	s := m.gattServer
+
+	switch msg := msg.(type) {
+	case gattServeMsg:
+		if s == nil || msg.handler != s.handler {
+			// The pane was closed while we were registering.
+			if msg.app != nil {
+				return closeGattServer(m.ctx, msg.app)
+			}
+			return nil
+		}
+		if msg.err != nil {
+			s.err = msg.err
+			s.input.Focus()
+			return nil
+		}
+		s.app = msg.app
+		s.refresh()
+		s.status = "Serving " + s.file + "."
+		return waitForGattServerRequest(s.handler)
+	case gattServerRequestMsg:
+		if s == nil || msg.handler != s.handler {
+			return nil
+		}
+		s.requests = append(s.requests, msg.request)
+		if len(s.requests) > maxGattServerRequests {
+			s.requests = s.requests[len(s.requests)-maxGattServerRequests:]
+		}
+		if msg.request.write {
+			s.refresh()
+		}
+		return waitForGattServerRequest(s.handler)
+	case gattServerValueMsg:
+		if s == nil || msg.app != s.app {
+			return nil
+		}
+		s.err = msg.err
+		if msg.err == nil {
+			s.refresh()
+			s.status = "Value set."
+		}
+	}
+	return nil
+}
+
+// updateGattServerPane handles the keys pressed while the pane is shown.
+func (m *model) updateGattServerPane(msg tea.KeyMsg) tea.Cmd {
+	s := m.gattServer
+
+	if s.app == nil || s.setting {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if s.app == nil {
+				// The input is blurred while registering.
+				if !s.input.Focused() {
+					return nil
+				}
+				s.file = strings.TrimSpace(s.input.Value())
+				s.input.Blur()
+				s.status, s.err = "Registering "+s.file+"...", nil
+				return serveGatt(m.ctx, m.adapter, s.file, s.handler)
+			}
+			s.setting = false
+			value, err := parseGattValue(s.input.Value())
+			if err != nil {
+				s.err = err
+				return nil
+			}
+			s.status, s.err = "Setting value...", nil
+			return setGattServerValue(s.app, s.rows[s.cursor].path, value)
+		case tea.KeyEsc:
+			if s.app == nil {
+				s.handler.stop()
+				m.gattServer = nil
+				return nil
+			}
+			s.setting = false
+			return nil
+		}
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, s.keys.back):
+		s.handler.stop()
+		m.gattServer = nil
+		return closeGattServer(m.ctx, s.app)
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case key.Matches(msg, s.keys.up):
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case key.Matches(msg, s.keys.down):
+		if s.cursor < len(s.rows)-1 {
+			s.cursor++
+		}
+	case key.Matches(msg, s.keys.format):
+		s.format = s.format.next()
+	case key.Matches(msg, s.keys.set):
+		if s.cursor >= len(s.rows) || s.rows[s.cursor].kind != characteristicRow {
+			s.err = errors.New("only the values of characteristics can be set")
+			return nil
+		}
+		s.setting = true
+		s.input = textinput.New()
+		s.input.Placeholder = `hex (01 ff) or "text"`
+		s.input.Focus()
+		return textinput.Blink
+	}
+	return nil
+}
+
+// View renders the services served and the last requests of the centrals.
+func (s *gattServerPane) View() string {
+	var lines []string
+
+	if s.app == nil {
+		lines = append(lines, panelLabelStyle.Render("Definition file")+s.input.View())
+	}
+
+	for i, row := range s.rows {
+		cursor := "  "
+		if i == s.cursor {
+			cursor = "> "
+		}
+
+		var line string
+		switch row.kind {
+		case serviceRow:
+			line = modalTitleStyle.Render(row.name)
+		case characteristicRow:
+			line = "  " + row.name
+		case descriptorRow:
+			line = "    " + modalHintStyle.Render(row.name)
+		}
+		if len(row.flags) > 0 {
+			line += " " + modalHintStyle.Render("["+strings.Join(row.flags, ", ")+"]")
+		}
+		if v := formatGattValue(s.values[row.path]); v != "" {
+			line += " " + modalValueStyle.Render(v)
+		}
+		lines = append(lines, cursor+line)
+	}
+
+	if s.app != nil {
+		lines = append(lines, "", modalTitleStyle.Render(fmt.Sprintf("Requests (%s)", s.format)))
+		if len(s.requests) == 0 {
+			lines = append(lines, "Waiting for centrals...")
+		}
+		for _, r := range s.requests {
+			op := "read "
+			if r.write {
+				op = "write"
+			}
+			line := modalHintStyle.Render(r.time.Format("15:04:05.000")) + " " + op + " " +
+				panelLabelStyle.Render(s.rowName(r.req.Path)) + s.format.format(r.value)
+			if r.req.Device != "" {
+				line += " " + modalHintStyle.Render("from "+r.req.Device)
+			}
+			if r.req.Offset > 0 {
+				line += " " + modalHintStyle.Render(fmt.Sprintf("at %d", r.req.Offset))
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if s.setting {
+		lines = append(lines, "", panelLabelStyle.Render("Set value")+s.input.View())
+	}
+	if s.err != nil {
+		lines = append(lines, "", errorStyle.Render(s.err.Error()))
+	} else if s.status != "" {
+		lines = append(lines, "", s.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// gattServerView renders the pane as a full screen.
+func (m model) gattServerView() string {
+	helpView := m.help.View(m.gattServer.keys)
+	if m.gattServer.app == nil {
+		helpView = modalHintStyle.Render("enter serve • esc cancel")
+	} else if m.gattServer.setting {
+		helpView = modalHintStyle.Render("enter set • esc cancel")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("GATT server"),
+		"",
+		m.gattServer.View(),
+		"",
+		helpView,
+	))
+}
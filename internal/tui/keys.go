@@ -10,21 +10,43 @@ type keyMap struct {
 	pair       key.Binding
 	connect    key.Binding
 	disconnect key.Binding
-	filter     key.Binding
-	quit       key.Binding
-	up         key.Binding
-	down       key.Binding
-	nextPage   key.Binding
-	prevPage   key.Binding
-	home       key.Binding
-	end        key.Binding
-	help       key.Binding
+	remove     key.Binding
+	// cancel aborts the operation running on the selected device.
+	cancel  key.Binding
+	details key.Binding
+	// media opens the now playing view of the selected device.
+	media key.Binding
+	// gatt opens the GATT browser of the selected device.
+	gatt key.Binding
+	// gattServer opens the pane of our own GATT server.
+	gattServer key.Binding
+	// advertising opens the pane of our LE advertisements.
+	advertising key.Binding
+	// send opens the pane that sends files to the selected device.
+	send key.Binding
+	// files opens the file browser of the selected device.
+	files key.Binding
+	// phonebook opens the phonebook export of the selected phone.
+	phonebook key.Binding
+	adapter   key.Binding
+	filter    key.Binding
+	// discoveryFilter opens the menu of the filter used when discovering,
+	// not to be confused with filter, which filters the list.
+	discoveryFilter key.Binding
+	quit            key.Binding
+	up              key.Binding
+	down            key.Binding
+	nextPage        key.Binding
+	prevPage        key.Binding
+	home            key.Binding
+	end             key.Binding
+	help            key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
 // of the key.Map interface.
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.up, k.down, k.pair, k.connect, k.help, k.quit}
+	return []key.Binding{k.up, k.down, k.discover, k.discoveryFilter, k.pair, k.connect, k.disconnect, k.remove, k.cancel, k.details, k.media, k.gatt, k.gattServer, k.advertising, k.send, k.files, k.phonebook, k.adapter, k.help, k.quit}
 }
 
 // FullHelp returns keybindings for the expanded help view. It's part of the
@@ -59,6 +81,14 @@ func newKeyMap() keyMap {
 			key.WithKeys("/"),
 			key.WithHelp("/", "filter"),
 		),
+		discover: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "scan"),
+		),
+		discoveryFilter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "scan filter"),
+		),
 		pair: key.NewBinding(
 			key.WithKeys("p"),
 			key.WithHelp("p", "pair"),
@@ -67,12 +97,60 @@ func newKeyMap() keyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "connect"),
 		),
+		disconnect: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "disconnect"),
+		),
+		remove: key.NewBinding(
+			key.WithKeys("x", "delete"),
+			key.WithHelp("x", "remove"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		details: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "details"),
+		),
+		media: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "now playing"),
+		),
+		gatt: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "browse gatt"),
+		),
+		gattServer: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "gatt server"),
+		),
+		advertising: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "advertise"),
+		),
+		send: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "send file"),
+		),
+		files: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "browse files"),
+		),
+		phonebook: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "export phonebook"),
+		),
+		adapter: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "adapter"),
+		),
 		help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
 		),
 		quit: key.NewBinding(
-			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
 	}
@@ -109,3 +187,514 @@ func newFilterKeyMap() filterKeyMap {
 		),
 	}
 }
+
+// adapterKeyMap is only used for the adapter panel.
+type adapterKeyMap struct {
+	power               key.Binding
+	discoverable        key.Binding
+	pairable            key.Binding
+	alias               key.Binding
+	discoverableTimeout key.Binding
+	pairableTimeout     key.Binding
+	back                key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the adapter panel.
+func (a adapterKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{a.power, a.discoverable, a.pairable, a.alias, a.discoverableTimeout, a.pairableTimeout, a.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (a adapterKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newAdapterKeyMap() adapterKeyMap {
+	return adapterKeyMap{
+		power: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "power"),
+		),
+		discoverable: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "discoverable"),
+		),
+		pairable: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pairable"),
+		),
+		alias: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "alias"),
+		),
+		discoverableTimeout: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "discoverable timeout"),
+		),
+		pairableTimeout: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "pairable timeout"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc", "a"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// discoveryFilterKeyMap is only used for the discovery filter menu.
+type discoveryFilterKeyMap struct {
+	up     key.Binding
+	down   key.Binding
+	edit   key.Binding
+	clear  key.Binding
+	apply  key.Binding
+	cancel key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the discovery filter menu.
+func (d discoveryFilterKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{d.up, d.down, d.edit, d.clear, d.apply, d.cancel}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (d discoveryFilterKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newDiscoveryFilterKeyMap() discoveryFilterKeyMap {
+	return discoveryFilterKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		edit: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "edit"),
+		),
+		clear: key.NewBinding(
+			key.WithKeys("x", "backspace"),
+			key.WithHelp("x", "clear"),
+		),
+		apply: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "save"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+// detailKeyMap is only used for the device detail view.
+type detailKeyMap struct {
+	up         key.Binding
+	down       key.Binding
+	connect    key.Binding
+	disconnect key.Binding
+	volumeUp   key.Binding
+	volumeDown key.Binding
+	back       key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the detail view.
+func (d detailKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{d.up, d.down, d.connect, d.disconnect, d.volumeUp, d.volumeDown, d.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (d detailKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newDetailKeyMap() detailKeyMap {
+	return detailKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		connect: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "connect profile"),
+		),
+		disconnect: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "disconnect profile"),
+		),
+		volumeUp: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "volume up"),
+		),
+		volumeDown: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "volume down"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel/back"),
+		),
+	}
+}
+
+// mediaKeyMap is only used for the now playing view.
+type mediaKeyMap struct {
+	playPause key.Binding
+	stop      key.Binding
+	next      key.Binding
+	previous  key.Binding
+	back      key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the now playing view.
+func (m mediaKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.playPause, m.stop, m.previous, m.next, m.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (m mediaKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newMediaKeyMap() mediaKeyMap {
+	return mediaKeyMap{
+		playPause: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "play/pause"),
+		),
+		stop: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "stop"),
+		),
+		next: key.NewBinding(
+			key.WithKeys("n", "right", "l"),
+			key.WithHelp("n", "next"),
+		),
+		previous: key.NewBinding(
+			key.WithKeys("p", "left", "h"),
+			key.WithHelp("p", "previous"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc", "m"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// gattKeyMap is only used for the GATT browser.
+type gattKeyMap struct {
+	up           key.Binding
+	down         key.Binding
+	read         key.Binding
+	write        key.Binding
+	writeCommand key.Binding
+	notify       key.Binding
+	format       key.Binding
+	log          key.Binding
+	reload       key.Binding
+	back         key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the GATT browser.
+func (g gattKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{g.up, g.down, g.read, g.write, g.writeCommand, g.notify, g.format, g.log, g.reload, g.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (g gattKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newGattKeyMap() gattKeyMap {
+	return gattKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		read: key.NewBinding(
+			key.WithKeys("r", "enter"),
+			key.WithHelp("r", "read"),
+		),
+		write: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "write"),
+		),
+		writeCommand: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "write without response"),
+		),
+		notify: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "notify"),
+		),
+		format: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "value format"),
+		),
+		log: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "log to file"),
+		),
+		reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// gattServerKeyMap is only used for the GATT server pane.
+type gattServerKeyMap struct {
+	up     key.Binding
+	down   key.Binding
+	set    key.Binding
+	format key.Binding
+	back   key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the GATT server pane.
+func (g gattServerKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{g.up, g.down, g.set, g.format, g.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (g gattServerKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newGattServerKeyMap() gattServerKeyMap {
+	return gattServerKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		set: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "set value"),
+		),
+		format: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "value format"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "stop serving"),
+		),
+	}
+}
+
+// advertisingKeyMap is only used for the advertising pane.
+type advertisingKeyMap struct {
+	up    key.Binding
+	down  key.Binding
+	edit  key.Binding
+	clear key.Binding
+	start key.Binding
+	back  key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the advertising pane.
+func (a advertisingKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{a.up, a.down, a.edit, a.clear, a.start, a.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (a advertisingKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newAdvertisingKeyMap() advertisingKeyMap {
+	return advertisingKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		edit: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "edit"),
+		),
+		clear: key.NewBinding(
+			key.WithKeys("x", "backspace"),
+			key.WithHelp("x", "clear/stop"),
+		),
+		start: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "start"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// sendKeyMap is only used for the pane that sends files.
+type sendKeyMap struct {
+	up     key.Binding
+	down   key.Binding
+	open   key.Binding
+	parent key.Binding
+	back   key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the send pane.
+func (s sendKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{s.up, s.down, s.open, s.parent, s.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (s sendKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newSendKeyMap() sendKeyMap {
+	return sendKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open/send"),
+		),
+		parent: key.NewBinding(
+			key.WithKeys("backspace", "left", "h"),
+			key.WithHelp("backspace", "parent dir"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// ftpKeyMap is only used for the file browser.
+type ftpKeyMap struct {
+	up         key.Binding
+	down       key.Binding
+	open       key.Binding
+	parent     key.Binding
+	switchSide key.Binding
+	copy       key.Binding
+	mkdir      key.Binding
+	delete     key.Binding
+	reload     key.Binding
+	back       key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the file browser.
+func (f ftpKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{f.up, f.down, f.open, f.parent, f.switchSide, f.copy, f.mkdir, f.delete, f.reload, f.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (f ftpKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newFtpKeyMap() ftpKeyMap {
+	return ftpKeyMap{
+		up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open"),
+		),
+		parent: key.NewBinding(
+			key.WithKeys("backspace", "left", "h"),
+			key.WithHelp("backspace", "parent dir"),
+		),
+		switchSide: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "switch side"),
+		),
+		copy: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy"),
+		),
+		mkdir: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new folder"),
+		),
+		delete: key.NewBinding(
+			key.WithKeys("x", "delete"),
+			key.WithHelp("x", "delete"),
+		),
+		reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// pbapKeyMap is only used for the phonebook export. Every other key goes to
+// the directory input.
+type pbapKeyMap struct {
+	export key.Binding
+	format key.Binding
+	back   key.Binding
+}
+
+// ShortHelp returns keys for the mini help menu of the phonebook export.
+func (p pbapKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{p.export, p.format, p.back}
+}
+
+// FullHelp returns nothing because the short help already has every key.
+func (p pbapKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+func newPbapKeyMap() pbapKeyMap {
+	return pbapKeyMap{
+		export: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "export"),
+		),
+		format: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "vcard format"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// nowPlaying shows the player of a device (e.g. a phone connected over
+// AVRCP) and lets the user control it.
+type nowPlaying struct {
+	device bluetooth.Device
+	state  bluetooth.PlayerState
+	loaded bool
+	keys   mediaKeyMap
+	// states is the stream of the player, which runs until stop is called.
+	// It also tells the messages of this view apart from the ones of a
+	// previous one.
+	states <-chan bluetooth.PlayerState
+	stop   context.CancelFunc
+	err    error
+}
+
+// playerWatchStartedMsg is sent once we follow the player of the device.
+type playerWatchStartedMsg struct {
+	states <-chan bluetooth.PlayerState
+}
+
+// playerStateMsg carries a new state of the player.
+type playerStateMsg struct {
+	state  bluetooth.PlayerState
+	states <-chan bluetooth.PlayerState
+}
+
+// playerErrMsg is sent when watching or controlling the player fails.
+type playerErrMsg struct {
+	err error
+}
+
+// playerTickMsg moves the position forward while playing, since BlueZ only
+// reports it when it jumps.
+type playerTickMsg struct {
+	states <-chan bluetooth.PlayerState
+}
+
+// openNowPlaying opens the view for the device and starts following its
+// player.
+func (m *model) openNowPlaying(dev bluetooth.Device) tea.Cmd {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.media = &nowPlaying{device: dev, keys: newMediaKeyMap(), stop: cancel}
+	return watchPlayer(ctx, m.adapter, dev.Address())
+}
+
+// closeNowPlaying stops following the player and closes the view.
+func (m *model) closeNowPlaying() {
+	m.media.stop()
+	m.media = nil
+}
+
+func watchPlayer(ctx context.Context, adapter bluetooth.Adapter, addr string) tea.Cmd {
+	return func() tea.Msg {
+		states, err := adapter.WatchPlayer(ctx, addr)
+		if err != nil {
+			return playerErrMsg{err}
+		}
+		return playerWatchStartedMsg{states}
+	}
+}
+
+// waitForPlayerState waits for the next state of the player. It must be
+// issued again after each playerStateMsg to keep receiving them.
+func waitForPlayerState(states <-chan bluetooth.PlayerState) tea.Cmd {
+	return func() tea.Msg {
+		state, ok := <-states
+		if !ok {
+			return nil
+		}
+		return playerStateMsg{state: state, states: states}
+	}
+}
+
+func tickPlayer(states <-chan bluetooth.PlayerState) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return playerTickMsg{states}
+	})
+}
+
+// controlPlayer sends a transport command to the player of the device.
+func controlPlayer(ctx context.Context, adapter bluetooth.Adapter, addr string, cmd bluetooth.PlayerCommand) tea.Cmd {
+	return func() tea.Msg {
+		if err := adapter.ControlPlayer(ctx, addr, cmd); err != nil {
+			return playerErrMsg{err}
+		}
+		return nil
+	}
+}
+
+// handlePlayerMsg handles the messages of the view. The ones that belong to
+// a view that was already closed are dropped.
+func (m *model) handlePlayerMsg(msg tea.Msg) tea.Cmd {
+	if m.media == nil {
+		return nil
+	}
+	v := m.media
+
+	switch msg := msg.(type) {
+	case playerWatchStartedMsg:
+		v.states = msg.states
+		return tea.Batch(waitForPlayerState(msg.states), tickPlayer(msg.states))
+	case playerStateMsg:
+		if msg.states != v.states {
+			return nil
+		}
+		v.state, v.loaded, v.err = msg.state, true, nil
+		return waitForPlayerState(msg.states)
+	case playerTickMsg:
+		// The view is redrawn after every message, which is all we need.
+		if msg.states != v.states {
+			return nil
+		}
+		return tickPlayer(msg.states)
+	case playerErrMsg:
+		v.err = msg.err
+	}
+	return nil
+}
+
+// updateNowPlaying handles the keys pressed while the view is shown.
+func (m *model) updateNowPlaying(msg tea.KeyMsg) tea.Cmd {
+	v := m.media
+
+	var cmd bluetooth.PlayerCommand
+	switch {
+	case key.Matches(msg, v.keys.back):
+		m.closeNowPlaying()
+		return nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case key.Matches(msg, v.keys.playPause):
+		cmd = bluetooth.PlayerPlay
+		if v.state.Status == bluetooth.PlayerPlaying {
+			cmd = bluetooth.PlayerPause
+		}
+	case key.Matches(msg, v.keys.stop):
+		cmd = bluetooth.PlayerStop
+	case key.Matches(msg, v.keys.next):
+		cmd = bluetooth.PlayerNext
+	case key.Matches(msg, v.keys.previous):
+		cmd = bluetooth.PlayerPrevious
+	default:
+		return nil
+	}
+
+	return controlPlayer(m.ctx, m.adapter, v.device.Address(), cmd)
+}
+
+// View renders the player.
+func (v *nowPlaying) View(width int) string {
+	var lines []string
+
+	switch {
+	case !v.loaded && v.err == nil:
+		lines = append(lines, "Looking for a player...")
+	case v.loaded && v.state.Path == "":
+		lines = append(lines, "Nothing is playing. Start playing something on the device.")
+	case v.loaded:
+		s := v.state
+		track := s.Track
+		position := s.CurrentPosition(time.Now())
+
+		lines = append(lines,
+			modalValueStyle.Render(valueOr(track.Title, "Unknown title")),
+			valueOr(track.Artist, "Unknown artist"),
+			modalHintStyle.Render(valueOr(track.Album, "")),
+			"",
+		)
+
+		progress := formatPosition(position)
+		if track.Duration > 0 {
+			barWidth := max(min(width-24, 50), 10)
+			progress = progressBar(barWidth, float64(position)/float64(track.Duration)) +
+				" " + formatPosition(position) + " / " + formatPosition(track.Duration)
+		}
+		lines = append(lines, progress, "")
+
+		lines = append(lines, panelLabelStyle.Render("Status")+string(s.Status))
+		if s.Name != "" {
+			lines = append(lines, panelLabelStyle.Render("Player")+s.Name)
+		}
+		if track.NumberOfTracks > 0 {
+			lines = append(lines, panelLabelStyle.Render("Track")+fmt.Sprintf("%d of %d", track.TrackNumber, track.NumberOfTracks))
+		}
+	}
+
+	if v.err != nil {
+		lines = append(lines, "", errorStyle.Render(v.err.Error()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// nowPlayingView renders the view as a full screen.
+func (m model) nowPlayingView() string {
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Now playing on "+m.media.device.Name()),
+		"",
+		m.media.View(m.width),
+		"",
+		m.help.View(m.media.keys),
+	))
+}
+
+// progressBar renders a bar of the given width, filled up to fraction.
+func progressBar(width int, fraction float64) string {
+	fraction = min(max(fraction, 0), 1)
+	filled := int(fraction * float64(width))
+	return lipgloss.NewStyle().Foreground(modalBorder).Render(strings.Repeat("━", filled)) +
+		modalHintStyle.Render(strings.Repeat("─", width-filled))
+}
+
+// formatPosition renders a playback position as m:ss, or h:mm:ss.
+func formatPosition(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
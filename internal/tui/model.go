@@ -1,14 +1,24 @@
 package tui
 
 import (
+	"context"
+	"time"
+
 	"github.com/apaydev/bluetui/internal/bluetooth"
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type model struct {
+	// ctx is used for the background work of the app (e.g. watching the
+	// adapter for device changes). It's canceled by the caller on exit.
+	ctx     context.Context
+	adapter bluetooth.Adapter
+	// obex sends files to the devices. It's nil when obexd can't be reached,
+	// in which case only the OBEX features are unavailable.
+	obex bluetooth.Obex
 	list list.Model
 	// TODO: This will be used to render the selected option with a different
 	// style.
@@ -16,35 +26,88 @@ type model struct {
 	keys       keyMap
 	filterKeys filterKeyMap
 	help       help.Model
+	// agent answers the pairing requests of BlueZ, and prompt is the modal
+	// shown while one of them is waiting for the user.
+	agent  *tuiAgent
+	prompt *agentPrompt
+	// observer receives the progress of the operations run by the adapter,
+	// and operations holds the ones that are still running, by device
+	// address, so they can be canceled.
+	observer   *Observer
+	operations map[string]context.CancelFunc
+	// confirm is the modal shown before running destructive actions.
+	confirm *confirmPrompt
+	// panel is the adapter panel, shown instead of the list when open.
+	panel *adapterPanel
+	// detail is the detail view of a device, also shown instead of the list.
+	detail *deviceDetail
+	// media is the now playing view of a device, also shown instead of the
+	// list.
+	media *nowPlaying
+	// gatt is the GATT browser of a device, also shown instead of the list.
+	gatt *gattBrowser
+	// gattServer is the pane of our own GATT server, also shown instead of
+	// the list.
+	gattServer *gattServerPane
+	// advertising is the pane of our LE advertisements, also shown instead
+	// of the list. The advertisements themselves outlive it.
+	advertising    *advertisingPane
+	advertisements []*bluetooth.ActiveAdvertisement
+	// send is the pane that sends files to a device, also shown instead of
+	// the list.
+	send *sendPane
+	// ftp is the file browser of a device, also shown instead of the list.
+	ftp *ftpBrowser
+	// pbap is the phonebook export of a phone, also shown instead of the
+	// list.
+	pbap *pbapPane
+	// discoveryFilter is used every time we discover devices, and it's
+	// edited through filterMenu.
+	discoveryFilter bluetooth.DiscoveryFilter
+	filterMenu      *discoveryFilterMenu
+	// stopDiscovery stops the ongoing discovery, if any.
+	stopDiscovery context.CancelFunc
+	width         int
+	height        int
 }
 
-// NewModel defines the app's initial state
-func NewModel() model {
+// NewModel defines the app's initial state. The devices shown are the ones
+// of the given adapter, which should report its operations to the observer.
+// Files are exchanged through obex, which may be nil.
+func NewModel(ctx context.Context, adapter bluetooth.Adapter, obex bluetooth.Obex, observer *Observer) model {
+	if observer == nil {
+		observer = NewObserver()
+	}
+
 	m := model{
+		ctx:        ctx,
+		adapter:    adapter,
+		obex:       obex,
 		keys:       newKeyMap(),
 		filterKeys: newFilterKeyMap(),
 		help:       help.New(),
+		agent:      newTUIAgent(),
+		observer:   observer,
+		operations: make(map[string]context.CancelFunc),
 	}
 
 	// Setup help
 	m.help = styledHelp(m.help)
 
-	// Make initial list of items
-	devices := bluetooth.GetDevices()
-
-	items := make([]list.Item, len(devices))
-	for i := range devices {
-		items[i] = devices[i]
-	}
-
-	// Setup List
-	deviceList := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	// Setup List. It starts empty, and it's filled as soon as we start
+	// watching the adapter.
+	deviceList := list.New([]list.Item{}, newDeviceDelegate(), 0, 0)
 	deviceList.Title = "Bluetooth Devices"
-	deviceList.Styles.Title = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFDF5")).
-		Background(lipgloss.Color("#25A065")).
-		Padding(0, 1)
+	deviceList.Styles.Title = titleStyle
 	deviceList.SetShowHelp(false)
+	// Status messages are used to report errors, so they need to stay long
+	// enough to be read.
+	deviceList.StatusMessageLifetime = 5 * time.Second
+	// Esc cancels the operation on the selected device, so it can't quit.
+	deviceList.KeyMap.Quit = key.NewBinding(
+		key.WithKeys("q"),
+		key.WithHelp("q", "quit"),
+	)
 
 	m.list = deviceList
 
@@ -52,6 +115,10 @@ func NewModel() model {
 }
 
 func (m model) Init() tea.Cmd {
-	// Just return `nil`, which means "no I/O right now, please."
-	return nil
+	return tea.Batch(
+		watchDevices(m.ctx, m.adapter),
+		registerAgent(m.ctx, m.adapter, m.agent),
+		waitForAgentRequest(m.agent),
+		waitForOperationEvent(m.observer),
+	)
 }
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxNotifications is how many of the last notifications the pane keeps.
+const maxNotifications = 10
+
+// valueFormat is how the pane renders the values of the notifications.
+type valueFormat int
+
+const (
+	hexFormat valueFormat = iota
+	utf8Format
+	// The integer formats read the value as little endian, which is what
+	// the GATT characteristics use.
+	uintFormat
+	intFormat
+)
+
+func (f valueFormat) String() string {
+	switch f {
+	case utf8Format:
+		return "utf-8"
+	case uintFormat:
+		return "unsigned"
+	case intFormat:
+		return "signed"
+	default:
+		return "hex"
+	}
+}
+
+// next returns the format that comes after f, going back to the first one.
+func (f valueFormat) next() valueFormat {
+	return (f + 1) % (intFormat + 1)
+}
+
+// format renders the value. Values that don't fit the format (e.g. invalid
+// UTF-8, or integers of odd sizes) fall back to hex.
+func (f valueFormat) format(value []byte) string {
+	switch f {
+	case utf8Format:
+		if utf8.Valid(value) {
+			return fmt.Sprintf("%q", value)
+		}
+	case uintFormat, intFormat:
+		var u uint64
+		switch len(value) {
+		case 1:
+			u = uint64(value[0])
+		case 2:
+			u = uint64(binary.LittleEndian.Uint16(value))
+		case 4:
+			u = uint64(binary.LittleEndian.Uint32(value))
+		case 8:
+			u = binary.LittleEndian.Uint64(value)
+		default:
+			return fmt.Sprintf("% x", value)
+		}
+		if f == uintFormat {
+			return fmt.Sprint(u)
+		}
+		// Sign extend from the size of the value.
+		shift := 64 - 8*len(value)
+		return fmt.Sprint(int64(u<<shift) >> shift)
+	}
+	return fmt.Sprintf("% x", value)
+}
+
+// gattSubscription is a characteristic whose notifications are followed.
+type gattSubscription struct {
+	stop          context.CancelFunc
+	notifications <-chan bluetooth.GattNotification
+}
+
+// gattNotifyStartedMsg is sent once the device notifies the characteristic.
+type gattNotifyStartedMsg struct {
+	path          string
+	notifications <-chan bluetooth.GattNotification
+	err           error
+}
+
+// gattNotificationMsg carries a notification of a characteristic.
+type gattNotificationMsg struct {
+	notification  bluetooth.GattNotification
+	notifications <-chan bluetooth.GattNotification
+}
+
+func startNotify(ctx context.Context, adapter bluetooth.Adapter, path string) tea.Cmd {
+	return func() tea.Msg {
+		notifications, err := adapter.Notify(ctx, path)
+		return gattNotifyStartedMsg{path: path, notifications: notifications, err: err}
+	}
+}
+
+// waitForNotification waits for the next notification. It must be issued
+// again after each gattNotificationMsg to keep receiving them.
+func waitForNotification(notifications <-chan bluetooth.GattNotification) tea.Cmd {
+	return func() tea.Msg {
+		n, ok := <-notifications
+		if !ok {
+			return nil
+		}
+		return gattNotificationMsg{notification: n, notifications: notifications}
+	}
+}
+
+// toggleNotify starts or stops following the notifications of the
+// characteristic.
+func (m *model) toggleNotify(row gattRow) tea.Cmd {
+	g := m.gatt
+
+	if sub, ok := g.subscriptions[row.path]; ok {
+		sub.stop()
+		delete(g.subscriptions, row.path)
+		g.status = "Stopped notifications of " + row.name + "."
+		return nil
+	}
+
+	if !row.canNotify {
+		g.err = errors.New("this characteristic can't notify")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	g.subscriptions[row.path] = &gattSubscription{stop: cancel}
+	g.status, g.err = "Subscribing to "+row.name+"...", nil
+	return startNotify(ctx, m.adapter, row.path)
+}
+
+// handleNotifyMsg handles the messages of the subscriptions. The ones of
+// subscriptions that were stopped in the meantime are dropped.
+func (m *model) handleNotifyMsg(msg tea.Msg) tea.Cmd {
+	g := m.gatt
+	if g == nil {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case gattNotifyStartedMsg:
+		sub, ok := g.subscriptions[msg.path]
+		if !ok {
+			return nil
+		}
+		if msg.err != nil {
+			sub.stop()
+			delete(g.subscriptions, msg.path)
+			g.err = msg.err
+			return nil
+		}
+		sub.notifications = msg.notifications
+		g.status = "Subscribed to " + g.rowName(msg.path) + "."
+		return waitForNotification(msg.notifications)
+	case gattNotificationMsg:
+		n := msg.notification
+		sub, ok := g.subscriptions[n.Path]
+		if !ok || sub.notifications != msg.notifications {
+			return nil
+		}
+		g.values[n.Path] = n.Value
+		g.notifications = append(g.notifications, n)
+		if len(g.notifications) > maxNotifications {
+			g.notifications = g.notifications[len(g.notifications)-maxNotifications:]
+		}
+		if err := g.logNotification(n); err != nil {
+			g.err = err
+		}
+		return waitForNotification(msg.notifications)
+	}
+	return nil
+}
+
+// rowName returns the name of the attribute at path.
+func (g *gattBrowser) rowName(path string) string {
+	for _, row := range g.rows {
+		if row.path == path {
+			return row.name
+		}
+	}
+	return path
+}
+
+// toggleLog starts or stops logging the notifications to a file in the
+// working directory.
+func (g *gattBrowser) toggleLog() error {
+	if g.logFile != nil {
+		err := g.logFile.Close()
+		g.status = "Stopped logging to " + g.logFile.Name() + "."
+		g.logFile = nil
+		return err
+	}
+
+	name := fmt.Sprintf("bluetui-%s-%s.log", strings.ReplaceAll(g.address, ":", ""), time.Now().Format("20060102-150405"))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	g.logFile = f
+	g.status = "Logging notifications to " + name + "."
+	return nil
+}
+
+// logNotification writes the notification to the log file, if logging.
+// Values are always logged as hex, so nothing is lost whatever the format
+// shown.
+func (g *gattBrowser) logNotification(n bluetooth.GattNotification) error {
+	if g.logFile == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(g.logFile, "%s\t%s\t%s\t% x\n", n.Time.Format(time.RFC3339Nano), g.rowName(n.Path), n.Path, n.Value)
+	if err != nil {
+		return fmt.Errorf("failed to log notification: %w", err)
+	}
+	return nil
+}
+
+// stopNotifications stops every subscription and the log, when the browser
+// closes.
+func (g *gattBrowser) stopNotifications() {
+	for _, sub := range g.subscriptions {
+		sub.stop()
+	}
+	if g.logFile != nil {
+		_ = g.logFile.Close()
+	}
+}
+
+// notificationsView renders the pane with the last notifications.
+func (g *gattBrowser) notificationsView() []string {
+	if len(g.subscriptions) == 0 && len(g.notifications) == 0 {
+		return nil
+	}
+
+	title := fmt.Sprintf("Notifications (%s)", g.format)
+	if g.logFile != nil {
+		title += " • logging to " + g.logFile.Name()
+	}
+	lines := []string{"", modalTitleStyle.Render(title)}
+	if len(g.notifications) == 0 {
+		lines = append(lines, "Waiting for notifications...")
+	}
+	for _, n := range g.notifications {
+		lines = append(lines, modalHintStyle.Render(n.Time.Format("15:04:05.000"))+" "+
+			panelLabelStyle.Render(g.rowName(n.Path))+g.format.format(n.Value))
+	}
+	return lines
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Observer forwards the operation events of the adapter to the app, which
+// shows them in the status bar. It has to be given both to the adapter,
+// through bluetooth.WithObserver, and to NewModel.
+type Observer struct {
+	events chan bluetooth.OperationEvent
+}
+
+// NewObserver returns an Observer ready to be plugged into an adapter.
+func NewObserver() *Observer {
+	return &Observer{events: make(chan bluetooth.OperationEvent, 16)}
+}
+
+// Notify queues the event for the app. Operations must not wait for the UI,
+// so the event is dropped if the app falls that far behind.
+func (o *Observer) Notify(ev bluetooth.OperationEvent) {
+	select {
+	case o.events <- ev:
+	default:
+	}
+}
+
+// operationEventMsg wraps an event reported by the adapter.
+type operationEventMsg bluetooth.OperationEvent
+
+// waitForOperationEvent waits for the next operation event. It must be
+// issued again after each operationEventMsg to keep receiving them.
+func waitForOperationEvent(o *Observer) tea.Cmd {
+	return func() tea.Msg {
+		return operationEventMsg(<-o.events)
+	}
+}
+
+// handleOperationEvent shows the event in the status bar. Failures are left
+// out, since the command that ran the operation already reports its error.
+func (m *model) handleOperationEvent(ev bluetooth.OperationEvent) tea.Cmd {
+	name := ev.Address
+	if i := m.deviceIndex(ev.Address); i >= 0 {
+		name = m.list.Items()[i].(bluetooth.Device).Name()
+	}
+
+	var status string
+	switch ev.Status {
+	case bluetooth.OperationStarted:
+		status = operationVerbs[ev.Operation] + " " + name + "..."
+		if ev.Attempt > 1 {
+			status += fmt.Sprintf(" (attempt %d of %d)", ev.Attempt, ev.MaxAttempts)
+		}
+	case bluetooth.OperationRetrying:
+		status = name + ": " + ev.Message
+		if ev.Err != nil {
+			status += " (" + ev.Err.Error() + ")"
+		}
+	case bluetooth.OperationSucceeded, bluetooth.OperationSkipped:
+		status = name + ": " + ev.Message
+	default:
+		return nil
+	}
+
+	if m.detail != nil && m.detail.address == ev.Address {
+		m.detail.status = status
+	}
+	return m.list.NewStatusMessage(status)
+}
+
+// operationVerbs are used to tell which operation is running.
+var operationVerbs = map[bluetooth.Operation]string{
+	bluetooth.OpPair:       "Pairing with",
+	bluetooth.OpTrust:      "Trusting",
+	bluetooth.OpConnect:    "Connecting to",
+	bluetooth.OpDisconnect: "Disconnecting from",
+	bluetooth.OpRemove:     "Removing",
+	// The profile is only named once it's done.
+	bluetooth.OpConnectProfile:    "Connecting a profile of",
+	bluetooth.OpDisconnectProfile: "Disconnecting a profile of",
+}
+
+// operationDoneMsg is sent when an operation started through runOperation
+// finishes.
+type operationDoneMsg struct {
+	op  bluetooth.Operation
+	dev bluetooth.Device
+	err error
+}
+
+// runOperation runs op on the device in the background. Until it's done, it
+// can be canceled through cancelOperation. Only one operation can run on a
+// device at a time.
+func (m *model) runOperation(dev bluetooth.Device, op bluetooth.Operation, run func(context.Context, string) error) tea.Cmd {
+	if _, ok := m.operations[dev.Address()]; ok {
+		return m.list.NewStatusMessage(dev.Name() + " is busy, press esc to cancel.")
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.operations[dev.Address()] = cancel
+
+	return func() tea.Msg {
+		return operationDoneMsg{op: op, dev: dev, err: run(ctx, dev.Address())}
+	}
+}
+
+// cancelOperation cancels the operation running on the device, if any.
+func (m *model) cancelOperation(dev bluetooth.Device) bool {
+	cancel, ok := m.operations[dev.Address()]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// handleOperationDone forgets about the finished operation and reports its
+// error, if any. Successes are reported by the adapter through our Observer.
+func (m *model) handleOperationDone(msg operationDoneMsg) tea.Cmd {
+	if cancel, ok := m.operations[msg.dev.Address()]; ok {
+		cancel()
+		delete(m.operations, msg.dev.Address())
+	}
+
+	var status string
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		status = "Canceled " + strings.ToLower(operationVerbs[msg.op]) + " " + msg.dev.Name() + "."
+	case msg.err != nil:
+		status = errorStyle.Render(msg.err.Error())
+	default:
+		return nil
+	}
+
+	if m.detail != nil && m.detail.address == msg.dev.Address() {
+		m.detail.status = status
+	}
+	return m.list.NewStatusMessage(status)
+}
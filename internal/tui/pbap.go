@@ -0,0 +1,312 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pbapBook is the state of the export of one phonebook.
+type pbapBook struct {
+	phonebook bluetooth.Phonebook
+	progress  bluetooth.TransferProgress
+	// err is set when the phone refused the phonebook, which is then
+	// skipped.
+	err  error
+	done bool
+}
+
+// pbapPane exports the phonebooks and call history of a phone to vCard files,
+// one phonebook after the other.
+type pbapPane struct {
+	keys    pbapKeyMap
+	address string
+	name    string
+	// input takes the directory to export to, until the export starts.
+	input  textinput.Model
+	dir    string
+	format bluetooth.VCardFormat
+	books  []pbapBook
+	// current is the index of the phonebook being exported. session is nil
+	// until the phone allows access, and cancel (which cancels exportCtx)
+	// is set while exporting.
+	current   int
+	session   bluetooth.PhonebookAccess
+	transfer  *bluetooth.Transfer
+	exportCtx context.Context
+	cancel    context.CancelFunc
+	status    string
+	err       error
+}
+
+func newPbapPane(dev bluetooth.Device) *pbapPane {
+	input := textinput.New()
+	input.SetValue(fmt.Sprintf("phonebook-%s-%s", strings.ReplaceAll(dev.Address(), ":", ""), time.Now().Format("20060102")))
+	input.Focus()
+
+	var books []pbapBook
+	for _, p := range bluetooth.ExportedPhonebooks() {
+		books = append(books, pbapBook{phonebook: p})
+	}
+	return &pbapPane{
+		keys:    newPbapKeyMap(),
+		address: dev.Address(),
+		name:    dev.Name(),
+		input:   input,
+		format:  bluetooth.VCard30,
+		books:   books,
+	}
+}
+
+// exporting tells whether the export is running.
+func (p *pbapPane) exporting() bool {
+	return p.cancel != nil
+}
+
+// pbapConnectedMsg is sent once the phone allows access to its phonebooks.
+type pbapConnectedMsg struct {
+	pane    *pbapPane
+	session bluetooth.PhonebookAccess
+	err     error
+}
+
+// pbapTransferMsg is sent once the pull of a phonebook starts, or fails to.
+type pbapTransferMsg struct {
+	session  bluetooth.PhonebookAccess
+	transfer *bluetooth.Transfer
+	// selectErr is set when the phone doesn't have the phonebook, which
+	// isn't fatal.
+	selectErr error
+	err       error
+}
+
+func connectPbap(ctx context.Context, obex bluetooth.Obex, pane *pbapPane) tea.Cmd {
+	return func() tea.Msg {
+		session, err := obex.PhonebookAccess(ctx, pane.address)
+		return pbapConnectedMsg{pane: pane, session: session, err: err}
+	}
+}
+
+func pullPhonebook(ctx context.Context, session bluetooth.PhonebookAccess, phonebook bluetooth.Phonebook, file string, format bluetooth.VCardFormat) tea.Cmd {
+	return func() tea.Msg {
+		if err := session.Select(ctx, bluetooth.PhonebookInternal, phonebook); err != nil {
+			if ctx.Err() != nil {
+				return pbapTransferMsg{session: session, err: err}
+			}
+			return pbapTransferMsg{session: session, selectErr: err}
+		}
+		transfer, err := session.PullAll(ctx, file, format)
+		return pbapTransferMsg{session: session, transfer: transfer, err: err}
+	}
+}
+
+// closePbapSession disconnects from the phone once the export is over.
+func closePbapSession(ctx context.Context, session bluetooth.PhonebookAccess) tea.Cmd {
+	return func() tea.Msg {
+		if err := session.Close(ctx); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// handlePbapMsg handles the messages of the pane. The ones of a pane that was
+// already closed are dropped.
+func (m *model) handlePbapMsg(msg tea.Msg) tea.Cmd {
+	p := m.pbap
+
+	switch msg := msg.(type) {
+	case pbapConnectedMsg:
+		if p != msg.pane {
+			if msg.session != nil {
+				return closePbapSession(m.ctx, msg.session)
+			}
+			return nil
+		}
+		if msg.err != nil {
+			return p.finish(m.ctx, msg.err)
+		}
+		p.session = msg.session
+		return p.next(m.ctx)
+	case pbapTransferMsg:
+		if p == nil || p.session != msg.session {
+			return nil
+		}
+		if msg.err != nil {
+			return p.finish(m.ctx, msg.err)
+		}
+		if msg.selectErr != nil {
+			p.books[p.current].err = msg.selectErr
+			p.current++
+			return p.next(m.ctx)
+		}
+		p.transfer = msg.transfer
+		return waitForTransferProgress(msg.transfer)
+	case transferProgressMsg:
+		if p == nil || p.transfer != msg.transfer {
+			return nil
+		}
+		p.books[p.current].progress = msg.progress
+		return waitForTransferProgress(msg.transfer)
+	case transferDoneMsg:
+		if p == nil || p.transfer != msg.transfer {
+			return nil
+		}
+		p.transfer = nil
+		if msg.err != nil {
+			return p.finish(m.ctx, msg.err)
+		}
+		p.books[p.current].done = true
+		p.current++
+		return p.next(m.ctx)
+	}
+	return nil
+}
+
+// next pulls the next phonebook, or finishes once they are all exported.
+func (p *pbapPane) next(ctx context.Context) tea.Cmd {
+	if p.current >= len(p.books) {
+		return p.finish(ctx, nil)
+	}
+	book := p.books[p.current]
+	p.status = "Exporting " + book.phonebook.Name() + "..."
+	return pullPhonebook(p.exportCtx, p.session, book.phonebook, filepath.Join(p.dir, book.phonebook.FileName()), p.format)
+}
+
+// finish reports how the export went, and disconnects from the phone.
+func (p *pbapPane) finish(ctx context.Context, err error) tea.Cmd {
+	p.cancel()
+	p.cancel = nil
+
+	exported := 0
+	for _, b := range p.books {
+		if b.done {
+			exported++
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		p.status = "Canceled."
+	case err != nil:
+		p.status, p.err = "", err
+	case exported == 0:
+		p.status, p.err = "", errors.New("the phone didn't let any phonebook be exported")
+	default:
+		p.status = fmt.Sprintf("Exported %d phonebooks to %s.", exported, p.dir)
+	}
+
+	if p.session == nil {
+		return nil
+	}
+	session := p.session
+	p.session = nil
+	return closePbapSession(ctx, session)
+}
+
+// updatePbapPane handles the keys pressed while the pane is shown.
+func (m *model) updatePbapPane(msg tea.KeyMsg) tea.Cmd {
+	p := m.pbap
+
+	switch {
+	case key.Matches(msg, p.keys.back):
+		// The first esc cancels the export, the second one goes back.
+		if p.exporting() {
+			p.cancel()
+			p.status = "Canceling..."
+			return nil
+		}
+		m.pbap = nil
+		return nil
+	case p.exporting():
+		return nil
+	case key.Matches(msg, p.keys.format):
+		if p.format == bluetooth.VCard30 {
+			p.format = bluetooth.VCard21
+		} else {
+			p.format = bluetooth.VCard30
+		}
+		return nil
+	case key.Matches(msg, p.keys.export):
+		if m.obex == nil {
+			p.err = errors.New("OBEX is not available, is obexd running?")
+			return nil
+		}
+		p.dir = strings.TrimSpace(p.input.Value())
+		if err := os.MkdirAll(p.dir, 0o755); err != nil {
+			p.err = err
+			return nil
+		}
+		for i := range p.books {
+			p.books[i] = pbapBook{phonebook: p.books[i].phonebook}
+		}
+		ctx, cancel := context.WithCancel(m.ctx)
+		p.exportCtx, p.cancel = ctx, cancel
+		p.current = 0
+		p.status, p.err = "Waiting for "+p.name+" to allow access...", nil
+		return connectPbap(ctx, m.obex, p)
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// View renders the directory and format of the export, and the state of every
+// phonebook.
+func (p *pbapPane) View() string {
+	lines := []string{
+		panelLabelStyle.Render("Export to") + p.input.View(),
+		panelLabelStyle.Render("Format") + modalValueStyle.Render(p.format.String()),
+		"",
+	}
+
+	for i, b := range p.books {
+		line := panelLabelStyle.Render(b.phonebook.Name())
+		switch {
+		case b.err != nil:
+			line += modalHintStyle.Render("skipped: " + b.err.Error())
+		case b.done:
+			line += modalValueStyle.Render(b.phonebook.FileName()) + " " + modalHintStyle.Render(formatSize(b.progress.Transferred))
+		case p.exporting() && i == p.current && b.progress.Status != "":
+			// Phones don't tell the size of phonebooks, so there's
+			// only the amount pulled so far.
+			line += modalHintStyle.Render(string(b.progress.Status) + " " + formatSize(b.progress.Transferred))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	if p.err != nil {
+		lines = append(lines, errorStyle.Render(p.err.Error()))
+	} else if p.status != "" {
+		lines = append(lines, p.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// pbapView renders the pane as a full screen.
+func (m model) pbapView() string {
+	helpView := m.help.View(m.pbap.keys)
+	if m.pbap.exporting() {
+		helpView = modalHintStyle.Render("esc cancel export")
+	}
+
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Export phonebooks of "+m.pbap.name),
+		"",
+		m.pbap.View(),
+		"",
+		helpView,
+	))
+}
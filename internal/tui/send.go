@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sendPane picks a file and pushes it to a device through OBEX Object Push,
+// showing the progress of the transfer.
+type sendPane struct {
+	keys    sendKeyMap
+	address string
+	name    string
+	picker  *filePicker
+	// transfer is nil until the device accepts the push, and cancel is set
+	// from the moment the file is picked until the transfer is over.
+	transfer *bluetooth.Transfer
+	cancel   context.CancelFunc
+	file     string
+	progress bluetooth.TransferProgress
+	status   string
+	err      error
+}
+
+func newSendPane(dev bluetooth.Device) *sendPane {
+	return &sendPane{
+		keys:    newSendKeyMap(),
+		address: dev.Address(),
+		name:    dev.Name(),
+		picker:  newFilePicker(""),
+	}
+}
+
+// sending tells whether a file is on its way.
+func (s *sendPane) sending() bool {
+	return s.cancel != nil
+}
+
+// sendStartedMsg is sent once the device accepted the push, or refused it.
+type sendStartedMsg struct {
+	pane     *sendPane
+	transfer *bluetooth.Transfer
+	err      error
+}
+
+// transferProgressMsg carries the progress of a transfer.
+type transferProgressMsg struct {
+	transfer *bluetooth.Transfer
+	progress bluetooth.TransferProgress
+}
+
+// transferDoneMsg is sent once a transfer is over.
+type transferDoneMsg struct {
+	transfer *bluetooth.Transfer
+	err      error
+}
+
+func sendFile(ctx context.Context, obex bluetooth.Obex, pane *sendPane, file string) tea.Cmd {
+	return func() tea.Msg {
+		transfer, err := obex.SendFile(ctx, pane.address, file)
+		return sendStartedMsg{pane: pane, transfer: transfer, err: err}
+	}
+}
+
+// waitForTransferProgress waits for the next update of a transfer. It must be
+// issued again after each transferProgressMsg, until transferDoneMsg.
+func waitForTransferProgress(transfer *bluetooth.Transfer) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-transfer.Progress()
+		if !ok {
+			return transferDoneMsg{transfer: transfer, err: transfer.Err()}
+		}
+		return transferProgressMsg{transfer: transfer, progress: p}
+	}
+}
+
+// handleSendMsg handles the messages of the pane. The ones of a pane that was
+// already closed are dropped.
+func (m *model) handleSendMsg(msg tea.Msg) tea.Cmd {
+	s := m.send
+
+	switch msg := msg.(type) {
+	case sendStartedMsg:
+		if s != msg.pane {
+			return nil
+		}
+		if msg.err != nil {
+			s.finish(msg.err)
+			return nil
+		}
+		s.transfer = msg.transfer
+		s.status = "Sending " + filepath.Base(s.file) + "..."
+		return waitForTransferProgress(msg.transfer)
+	case transferProgressMsg:
+		if s == nil || s.transfer != msg.transfer {
+			return nil
+		}
+		s.progress = msg.progress
+		return waitForTransferProgress(msg.transfer)
+	case transferDoneMsg:
+		if s == nil || s.transfer != msg.transfer {
+			return nil
+		}
+		s.finish(msg.err)
+	}
+	return nil
+}
+
+// finish reports how the push went, and lets another file be picked.
+func (s *sendPane) finish(err error) {
+	s.cancel()
+	s.cancel, s.transfer = nil, nil
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.status = "Canceled."
+	case err != nil:
+		s.status, s.err = "", err
+	default:
+		s.status = fmt.Sprintf("Sent %s to %s.", filepath.Base(s.file), s.name)
+	}
+}
+
+// updateSendPane handles the keys pressed while the pane is shown.
+func (m *model) updateSendPane(msg tea.KeyMsg) tea.Cmd {
+	s := m.send
+
+	switch {
+	case key.Matches(msg, s.keys.back):
+		// The first esc cancels the transfer, the second one goes back.
+		if s.sending() {
+			s.cancel()
+			s.status = "Canceling..."
+			return nil
+		}
+		m.send = nil
+	case key.Matches(msg, m.keys.quit):
+		return tea.Quit
+	case s.sending():
+		// The picker waits until the transfer is over.
+	case key.Matches(msg, s.keys.up):
+		s.picker.up()
+	case key.Matches(msg, s.keys.down):
+		s.picker.down()
+	case key.Matches(msg, s.keys.parent):
+		s.picker.parent()
+	case key.Matches(msg, s.keys.open):
+		file, ok := s.picker.open()
+		if !ok {
+			return nil
+		}
+		if m.obex == nil {
+			s.err = errors.New("OBEX is not available, is obexd running?")
+			return nil
+		}
+		ctx, cancel := context.WithCancel(m.ctx)
+		s.file, s.cancel = file, cancel
+		s.progress = bluetooth.TransferProgress{}
+		s.status, s.err = "Waiting for "+s.name+" to accept "+filepath.Base(file)+"...", nil
+		return sendFile(ctx, m.obex, s, file)
+	}
+	return nil
+}
+
+// View renders the file picker, and the progress of the transfer.
+func (s *sendPane) View(rows int) string {
+	lines := []string{s.picker.View(rows, !s.sending()), ""}
+
+	// The last progress stays until another file is picked.
+	if s.progress.Status != "" {
+		lines = append(lines, transferView(s.progress))
+	}
+	if s.err != nil {
+		lines = append(lines, errorStyle.Render(s.err.Error()))
+	} else if s.status != "" {
+		lines = append(lines, s.status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// transferView renders the progress of a transfer as a bar.
+func transferView(p bluetooth.TransferProgress) string {
+	line := progressBar(40, p.Fraction()) + " " + modalHintStyle.Render(string(p.Status))
+	if p.Size > 0 {
+		line += " " + modalHintStyle.Render(formatSize(p.Transferred)+" / "+formatSize(p.Size))
+	}
+	return line
+}
+
+// sendView renders the pane as a full screen.
+func (m model) sendView() string {
+	helpView := m.help.View(m.send.keys)
+	if m.send.sending() {
+		helpView = modalHintStyle.Render("esc cancel transfer")
+	}
+
+	// The title, the progress and the help take about a dozen lines.
+	rows := max(m.height-12, 5)
+	return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Send file to "+m.send.name),
+		"",
+		m.send.View(rows),
+		"",
+		helpView,
+	))
+}
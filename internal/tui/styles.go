@@ -10,9 +10,34 @@ const (
 	separatorDot    = lipgloss.Color("#FF66A6")
 	helpKey         = lipgloss.Color("#999999")
 	helpDesc        = lipgloss.Color("#808080")
+	errorText       = lipgloss.Color("#FF5F87")
+	modalBorder     = lipgloss.Color("#25A065")
+	modalValue      = lipgloss.Color("#FFFDF5")
+	batteryHigh     = lipgloss.Color("#25A065")
+	batteryMid      = lipgloss.Color("#F2C94C")
+	batteryLow      = lipgloss.Color("#FF5F87")
 )
 
-var appStyle = lipgloss.NewStyle().Padding(1, 2)
+var (
+	appStyle   = lipgloss.NewStyle().Padding(1, 2)
+	errorStyle = lipgloss.NewStyle().Foreground(errorText)
+	titleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFDF5")).
+			Background(lipgloss.Color("#25A065")).
+			Padding(0, 1)
+	// panelLabelStyle is used for the labels of the property tables, like the
+	// one of the adapter panel.
+	panelLabelStyle = lipgloss.NewStyle().Width(24).Foreground(helpKey)
+
+	// Styles used for the modals (e.g. pairing requests).
+	modalStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(modalBorder).
+			Padding(1, 3)
+	modalTitleStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	modalValueStyle = lipgloss.NewStyle().Bold(true).Foreground(modalValue)
+	modalHintStyle  = lipgloss.NewStyle().Foreground(helpDesc)
+)
 
 func styledHelp(help help.Model) help.Model {
 	// The ellipsis is the "..." shown when text is truncated.
@@ -1,11 +1,21 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apaydev/bluetui/internal/bluetooth"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// discoveryTimeout is how long a discovery started from the list lasts, unless
+// the user stops it first.
+const discoveryTimeout = 30 * time.Second
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
@@ -16,7 +26,104 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// If we set a width on the help menu it can gracefully truncate
 		// its view as needed.
 		m.help.Width = msg.Width
+		m.width, m.height = msg.Width, msg.Height
+	case watchStartedMsg:
+		cmds = append(cmds, waitForDeviceEvent(msg.events))
+	case deviceEventMsg:
+		cmds = append(cmds, m.applyDeviceEvent(msg.event), waitForDeviceEvent(msg.events))
+		if m.detail != nil && m.detail.address == msg.event.Device.Address() && transportsChanged(msg.event) {
+			cmds = append(cmds, loadTransports(m.ctx, m.adapter, m.detail.address))
+		}
+	case transportsMsg:
+		m.handleTransports(msg)
+	case errMsg:
+		cmds = append(cmds, m.list.NewStatusMessage(errorStyle.Render(msg.err.Error())))
+	case agentRequestMsg:
+		cmds = append(cmds, m.handleAgentRequest(agentRequest(msg)), waitForAgentRequest(m.agent))
+	case discoveryStartedMsg:
+		cmds = append(cmds, waitForDiscoveredDevice(msg.session))
+	case discoveredDeviceMsg:
+		// The watch also reports these devices, but the list shouldn't
+		// depend on it while we are discovering.
+		ev := bluetooth.DeviceEvent{Type: bluetooth.DeviceUpdated, Device: msg.device}
+		cmds = append(cmds, m.applyDeviceEvent(ev), waitForDiscoveredDevice(msg.session))
+	case discoverDoneMsg:
+		if m.stopDiscovery != nil {
+			m.stopDiscovery()
+			m.stopDiscovery = nil
+		}
+		m.list.StopSpinner()
+		if msg.err != nil {
+			cmds = append(cmds, m.list.NewStatusMessage(errorStyle.Render(msg.err.Error())))
+		} else {
+			cmds = append(cmds, m.list.NewStatusMessage(fmt.Sprintf("Discovery finished, %d devices found.", msg.found)))
+		}
+	case adapterInfoMsg:
+		cmds = append(cmds, m.handleAdapterInfo(msg))
+	case operationDoneMsg:
+		cmds = append(cmds, m.handleOperationDone(msg))
+	case operationEventMsg:
+		cmds = append(cmds, m.handleOperationEvent(bluetooth.OperationEvent(msg)), waitForOperationEvent(m.observer))
+	case playerWatchStartedMsg, playerStateMsg, playerTickMsg, playerErrMsg:
+		cmds = append(cmds, m.handlePlayerMsg(msg))
+	case gattServicesMsg, gattValueMsg:
+		m.handleGattMsg(msg)
+	case gattNotifyStartedMsg, gattNotificationMsg:
+		cmds = append(cmds, m.handleNotifyMsg(msg))
+	case gattServeMsg, gattServerRequestMsg, gattServerValueMsg:
+		cmds = append(cmds, m.handleGattServerMsg(msg))
+	case advertisingInfoMsg, advertiseMsg, advertisementReleasedMsg, advertisementStoppedMsg, advertisingTickMsg:
+		cmds = append(cmds, m.handleAdvertisingMsg(msg))
+	case sendStartedMsg:
+		cmds = append(cmds, m.handleSendMsg(msg))
+	case ftpConnectedMsg, ftpListMsg, ftpTransferMsg:
+		cmds = append(cmds, m.handleFtpMsg(msg))
+	case pbapConnectedMsg, pbapTransferMsg:
+		cmds = append(cmds, m.handlePbapMsg(msg))
+	case transferProgressMsg, transferDoneMsg:
+		// Only the view that started the transfer takes them.
+		cmds = append(cmds, m.handleSendMsg(msg), m.handleFtpMsg(msg), m.handlePbapMsg(msg))
 	case tea.KeyMsg:
+		// Modals take every key while they are shown.
+		if m.prompt != nil {
+			return m, m.updateAgentPrompt(msg)
+		}
+		if m.confirm != nil {
+			return m, m.updateConfirmPrompt(msg)
+		}
+		// The adapter panel replaces the list, so the list doesn't get any
+		// key either.
+		if m.panel != nil {
+			return m, m.updateAdapterPanel(msg)
+		}
+		if m.filterMenu != nil {
+			return m, m.updateDiscoveryFilterMenu(msg)
+		}
+		if m.detail != nil {
+			return m, m.updateDeviceDetail(msg)
+		}
+		if m.media != nil {
+			return m, m.updateNowPlaying(msg)
+		}
+		if m.gatt != nil {
+			return m, m.updateGattBrowser(msg)
+		}
+		if m.gattServer != nil {
+			return m, m.updateGattServerPane(msg)
+		}
+		if m.advertising != nil {
+			return m, m.updateAdvertisingPane(msg)
+		}
+		if m.send != nil {
+			return m, m.updateSendPane(msg)
+		}
+		if m.ftp != nil {
+			return m, m.updateFtpBrowser(msg)
+		}
+		if m.pbap != nil {
+			return m, m.updatePbapPane(msg)
+		}
+
 		// // Don't match any of the keys below if we're actively filtering.
 		if m.list.FilterState() == list.Filtering {
 			break
@@ -29,6 +136,98 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.filter) && m.list.ShowHelp():
 			m.list.Help.ShowAll = false // change default back to short help to keep in sync
 			m.list.SetShowHelp(false)
+		case key.Matches(msg, m.keys.pair):
+			if dev, ok := m.selectedDevice(); ok {
+				return m, m.runOperation(dev, bluetooth.OpPair, m.adapter.Pair)
+			}
+		case key.Matches(msg, m.keys.connect):
+			if dev, ok := m.selectedDevice(); ok {
+				return m, m.runOperation(dev, bluetooth.OpConnect, m.adapter.Connect)
+			}
+		case key.Matches(msg, m.keys.disconnect):
+			if dev, ok := m.selectedDevice(); ok {
+				return m, m.runOperation(dev, bluetooth.OpDisconnect, m.adapter.Disconnect)
+			}
+		case key.Matches(msg, m.keys.cancel):
+			// Esc aborts whatever runs on the selected device. Otherwise, it
+			// goes to the list, which uses it to clear the filter.
+			if dev, ok := m.selectedDevice(); ok && m.cancelOperation(dev) {
+				return m, m.list.NewStatusMessage("Canceling...")
+			}
+		case key.Matches(msg, m.keys.discover):
+			// Scanning again while discovering stops it. The devices found
+			// are reported once the session ends.
+			if m.stopDiscovery != nil {
+				m.stopDiscovery()
+				return m, nil
+			}
+			ctx, cancel := context.WithTimeout(m.ctx, discoveryTimeout)
+			m.stopDiscovery = cancel
+			return m, tea.Batch(
+				m.list.StartSpinner(),
+				m.list.NewStatusMessage("Discovering devices..."),
+				startDiscovery(ctx, m.adapter, m.discoveryFilter),
+			)
+		case key.Matches(msg, m.keys.discoveryFilter):
+			m.filterMenu = newDiscoveryFilterMenu(m.discoveryFilter)
+			return m, nil
+		case key.Matches(msg, m.keys.details):
+			if dev, ok := m.selectedDevice(); ok {
+				m.detail = newDeviceDetail(dev)
+				return m, loadTransports(m.ctx, m.adapter, dev.Address())
+			}
+		case key.Matches(msg, m.keys.media):
+			if dev, ok := m.selectedDevice(); ok {
+				return m, m.openNowPlaying(dev)
+			}
+		case key.Matches(msg, m.keys.gatt):
+			if dev, ok := m.selectedDevice(); ok {
+				m.gatt = newGattBrowser(dev)
+				return m, loadGattServices(m.ctx, m.adapter, dev.Address())
+			}
+		case key.Matches(msg, m.keys.gattServer):
+			m.gattServer = newGattServerPane()
+			return m, textinput.Blink
+		case key.Matches(msg, m.keys.advertising):
+			m.advertising = newAdvertisingPane()
+			return m, tea.Batch(loadAdvertisingInfo(m.ctx, m.adapter), m.tickAdvertising())
+		case key.Matches(msg, m.keys.send):
+			if dev, ok := m.selectedDevice(); ok {
+				m.send = newSendPane(dev)
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.files):
+			if dev, ok := m.selectedDevice(); ok {
+				if m.obex == nil {
+					return m, m.list.NewStatusMessage(errorStyle.Render("OBEX is not available, is obexd running?"))
+				}
+				m.ftp = newFtpBrowser(dev)
+				return m, connectFtp(m.ctx, m.obex, m.ftp)
+			}
+		case key.Matches(msg, m.keys.phonebook):
+			if dev, ok := m.selectedDevice(); ok {
+				// PBAP goes over the link of the phone, which must be
+				// connected already.
+				if !dev.Connected() {
+					return m, m.list.NewStatusMessage(errorStyle.Render("Connect to " + dev.Name() + " first."))
+				}
+				m.pbap = newPbapPane(dev)
+				return m, textinput.Blink
+			}
+		case key.Matches(msg, m.keys.adapter):
+			m.panel = newAdapterPanel()
+			return m, loadAdapterInfo(m.ctx, m.adapter)
+		case key.Matches(msg, m.keys.remove):
+			if dev, ok := m.selectedDevice(); ok {
+				m.confirm = &confirmPrompt{
+					title:   "Remove device",
+					message: "Forget " + dev.Name() + " (" + dev.Address() + ")?\nIt will have to be paired again.",
+					onConfirm: func(m *model) tea.Cmd {
+						return m.runOperation(dev, bluetooth.OpRemove, m.adapter.Remove)
+					},
+				}
+				return m, nil
+			}
 		}
 	}
 
@@ -6,6 +6,44 @@ import (
 
 // NOTE: Need to check if I can simplify this.
 func (m model) View() string {
+	if m.prompt != nil {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.prompt.View())
+	}
+	if m.confirm != nil {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.confirm.View())
+	}
+
+	if m.panel != nil {
+		return m.adapterView()
+	}
+	if m.filterMenu != nil {
+		return m.discoveryFilterView()
+	}
+	if m.detail != nil {
+		return m.detailView()
+	}
+	if m.media != nil {
+		return m.nowPlayingView()
+	}
+	if m.gatt != nil {
+		return m.gattView()
+	}
+	if m.gattServer != nil {
+		return m.gattServerView()
+	}
+	if m.advertising != nil {
+		return m.advertisingView()
+	}
+	if m.send != nil {
+		return m.sendView()
+	}
+	if m.ftp != nil {
+		return m.ftpView()
+	}
+	if m.pbap != nil {
+		return m.pbapView()
+	}
+
 	listView := m.list.View()
 	helpView := lipgloss.NewStyle().PaddingLeft(2).Render(m.help.View(m.keys))
 